@@ -0,0 +1,312 @@
+package bandit
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+
+	"github.com/anthonykrivonos/go-rl/mdp"
+	"github.com/anthonykrivonos/go-rl/tabular"
+	"github.com/anthonykrivonos/go-rl/utils"
+)
+
+// An agent that learns which arm of a bandit to pull.
+type Agent interface {
+	// Choose returns the arm to pull next.
+	Choose() mdp.Action
+	// Update learns from the reward of pulling an arm.
+	Update(arm mdp.Action, reward float32)
+}
+
+// estimates holds the sample-average reward of each arm.
+type estimates struct {
+	arms   []mdp.Action
+	counts []int
+	means  []float32
+}
+
+func newEstimates(arms []mdp.Action) estimates {
+	return estimates{arms, make([]int, len(arms)), make([]float32, len(arms))}
+}
+
+// update adds a reward to the arm's sample average and returns the arm's position, or -1 if it isn't one of the arms.
+func (e *estimates) update(arm mdp.Action, reward float32) int {
+	i := armPosition(e.arms, arm)
+	if i >= 0 {
+		e.counts[i]++
+		e.means[i] += (reward - e.means[i]) / float32(e.counts[i])
+	}
+	return i
+}
+
+// validateArms returns an error if there are no arms or no random source.
+func validateArms(arms []mdp.Action, rng *rand.Rand) error {
+	if len(arms) == 0 {
+		return errors.New("at least one arm must be provided")
+	} else if rng == nil {
+		return errors.New("random source must be provided")
+	}
+	return nil
+}
+
+type valueAgent struct {
+	estimates
+	explorer tabular.Explorer
+	rng      *rand.Rand
+}
+
+// NewEpsilonGreedy creates an agent that pulls a uniformly random arm with probability `epsilon` and otherwise pulls
+// the arm with the highest sample-average reward. `rng` is the source of randomness for exploration.
+func NewEpsilonGreedy(arms []mdp.Action, epsilon float32, rng *rand.Rand) (Agent, error) {
+	explorer, err := tabular.NewEpsilonGreedy(epsilon)
+	if err != nil {
+		return nil, err
+	}
+	return NewValueAgent(arms, explorer, rng)
+}
+
+// NewValueAgent creates an agent that chooses arms with a tabular exploration policy over the sample-average reward of
+// each arm. `rng` is the source of randomness for exploration.
+func NewValueAgent(arms []mdp.Action, explorer tabular.Explorer, rng *rand.Rand) (Agent, error) {
+	if err := validateArms(arms, rng); err != nil {
+		return nil, err
+	} else if explorer == nil {
+		return nil, errors.New("explorer must be provided")
+	}
+	a := &valueAgent{}
+	a.estimates = newEstimates(arms)
+	a.explorer = explorer
+	a.rng = rng
+	return a, nil
+}
+
+func (a *valueAgent) Choose() mdp.Action {
+	return a.explorer.Choose(a.arms, a.means, a.rng)
+}
+
+func (a *valueAgent) Update(arm mdp.Action, reward float32) {
+	a.update(arm, reward)
+}
+
+type ucb1 struct {
+	estimates
+	exploration float64
+	steps       int
+}
+
+// NewUCB1 creates an agent that pulls every arm once and then pulls the arm with the highest upper confidence bound
+// mean + c √(ln t / n), where t is the number of pulls so far and n is the number of pulls of the arm. UCB1 uses
+// c = √2.
+func NewUCB1(arms []mdp.Action, c float32) (Agent, error) {
+	if len(arms) == 0 {
+		return nil, errors.New("at least one arm must be provided")
+	} else if c < 0 {
+		return nil, errors.New("exploration coefficient must be non-negative")
+	}
+	a := &ucb1{}
+	a.estimates = newEstimates(arms)
+	a.exploration = float64(c)
+	return a, nil
+}
+
+func (a *ucb1) Choose() mdp.Action {
+	best := -1
+	bestBound := math.Inf(-1)
+	for i, n := range a.counts {
+		if n == 0 {
+			return a.arms[i]
+		}
+		bound := float64(a.means[i]) + a.exploration*math.Sqrt(math.Log(float64(a.steps))/float64(n))
+		if bound > bestBound {
+			best = i
+			bestBound = bound
+		}
+	}
+	return a.arms[best]
+}
+
+func (a *ucb1) Update(arm mdp.Action, reward float32) {
+	if a.update(arm, reward) >= 0 {
+		a.steps++
+	}
+}
+
+type betaThompson struct {
+	arms        []mdp.Action
+	alpha, beta []float64
+	rng         *rand.Rand
+}
+
+// NewBetaThompson creates a Thompson sampling agent for rewards in [0, 1], such as those of Bernoulli bandits. It keeps
+// a Beta(1, 1) prior on each arm's mean, pulls the arm whose sampled mean is highest, and treats each reward r as r
+// successes and 1 - r failures. `rng` is the source of randomness for sampling.
+func NewBetaThompson(arms []mdp.Action, rng *rand.Rand) (Agent, error) {
+	if err := validateArms(arms, rng); err != nil {
+		return nil, err
+	}
+	a := &betaThompson{}
+	a.arms = arms
+	a.alpha = make([]float64, len(arms))
+	a.beta = make([]float64, len(arms))
+	for i := range arms {
+		a.alpha[i], a.beta[i] = 1, 1
+	}
+	a.rng = rng
+	return a, nil
+}
+
+func (a *betaThompson) Choose() mdp.Action {
+	best := 0
+	bestSample := math.Inf(-1)
+	for i := range a.arms {
+		if sample := sampleBeta(a.rng, a.alpha[i], a.beta[i]); sample > bestSample {
+			best = i
+			bestSample = sample
+		}
+	}
+	return a.arms[best]
+}
+
+func (a *betaThompson) Update(arm mdp.Action, reward float32) {
+	if i := armPosition(a.arms, arm); i >= 0 {
+		r := math.Max(0, math.Min(1, float64(reward)))
+		a.alpha[i] += r
+		a.beta[i] += 1 - r
+	}
+}
+
+type gaussianThompson struct {
+	arms      []mdp.Action
+	precision []float64
+	weighted  []float64
+	noise     float64
+	rng       *rand.Rand
+}
+
+// NewGaussianThompson creates a Thompson sampling agent for rewards with Gaussian noise of standard deviation `noise`.
+// It keeps a N(0, 1) prior on each arm's mean, updates it exactly after each reward, and pulls the arm whose sampled
+// mean is highest. `rng` is the source of randomness for sampling.
+func NewGaussianThompson(arms []mdp.Action, noise float32, rng *rand.Rand) (Agent, error) {
+	if err := validateArms(arms, rng); err != nil {
+		return nil, err
+	} else if noise <= 0 {
+		return nil, errors.New("noise must be positive")
+	}
+	a := &gaussianThompson{}
+	a.arms = arms
+	a.precision = make([]float64, len(arms))
+	a.weighted = make([]float64, len(arms))
+	for i := range arms {
+		a.precision[i] = 1
+	}
+	a.noise = float64(noise)
+	a.rng = rng
+	return a, nil
+}
+
+func (a *gaussianThompson) Choose() mdp.Action {
+	best := 0
+	bestSample := math.Inf(-1)
+	for i := range a.arms {
+		mean := a.weighted[i] / a.precision[i]
+		sample := mean + a.rng.NormFloat64()/math.Sqrt(a.precision[i])
+		if sample > bestSample {
+			best = i
+			bestSample = sample
+		}
+	}
+	return a.arms[best]
+}
+
+func (a *gaussianThompson) Update(arm mdp.Action, reward float32) {
+	if i := armPosition(a.arms, arm); i >= 0 {
+		variance := a.noise * a.noise
+		a.precision[i] += 1 / variance
+		a.weighted[i] += float64(reward) / variance
+	}
+}
+
+type gradient struct {
+	arms        []mdp.Action
+	preferences []float64
+	stepSize    float64
+	useBaseline bool
+	baseline    float64
+	steps       int
+	rng         *rand.Rand
+}
+
+// NewGradient creates a gradient bandit agent, which pulls arms with probabilities given by a softmax over learned
+// preferences and moves the preferences by stochastic gradient ascent on the expected reward. If `baseline` is true,
+// rewards are compared with their running average. `rng` is the source of randomness for choosing arms.
+func NewGradient(arms []mdp.Action, stepSize float32, baseline bool, rng *rand.Rand) (Agent, error) {
+	if err := validateArms(arms, rng); err != nil {
+		return nil, err
+	} else if stepSize <= 0 {
+		return nil, errors.New("step size must be positive")
+	}
+	a := &gradient{}
+	a.arms = arms
+	a.preferences = make([]float64, len(arms))
+	a.stepSize = float64(stepSize)
+	a.useBaseline = baseline
+	a.rng = rng
+	return a, nil
+}
+
+// probabilities returns the softmax of the preferences.
+func (a *gradient) probabilities() []float64 {
+	return utils.Softmax(a.preferences, 1)
+}
+
+func (a *gradient) Choose() mdp.Action {
+	return a.arms[utils.SampleCategorical(a.probabilities(), a.rng)]
+}
+
+func (a *gradient) Update(arm mdp.Action, reward float32) {
+	chosen := armPosition(a.arms, arm)
+	if chosen < 0 {
+		return
+	}
+	a.steps++
+	if a.useBaseline {
+		a.baseline += (float64(reward) - a.baseline) / float64(a.steps)
+	}
+	advantage := float64(reward) - a.baseline
+	for i, p := range a.probabilities() {
+		if i == chosen {
+			a.preferences[i] += a.stepSize * advantage * (1 - p)
+		} else {
+			a.preferences[i] -= a.stepSize * advantage * p
+		}
+	}
+}
+
+// sampleBeta draws a sample from a Beta(alpha, beta) distribution.
+func sampleBeta(rng *rand.Rand, alpha, beta float64) float64 {
+	x := sampleGamma(rng, alpha)
+	y := sampleGamma(rng, beta)
+	return x / (x + y)
+}
+
+// sampleGamma draws a sample from a Gamma(shape, 1) distribution with the method of Marsaglia and Tsang.
+func sampleGamma(rng *rand.Rand, shape float64) float64 {
+	if shape < 1 {
+		// Boost the shape above 1 and scale the sample back down
+		return sampleGamma(rng, shape+1) * math.Pow(rng.Float64(), 1/shape)
+	}
+	d := shape - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := rng.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := rng.Float64()
+		if math.Log(u) < 0.5*x*x+d-d*v+d*math.Log(v) {
+			return d * v
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package bandit
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/anthonykrivonos/go-rl/mdp"
+)
+
+// A multi-armed bandit, whose arms are actions that each pay a random reward.
+type Bandit interface {
+	// Arms returns the bandit's arms.
+	Arms() []mdp.Action
+	// Pull pulls an arm and returns its reward.
+	Pull(arm mdp.Action) (float32, error)
+	// Mean returns the expected reward of an arm.
+	Mean(arm mdp.Action) float32
+}
+
+// newArms creates `k` arms named by their position.
+func newArms(k int) []mdp.Action {
+	names := make([]string, k)
+	for i := range names {
+		names[i] = fmt.Sprint(i)
+	}
+	return mdp.NewActions(names)
+}
+
+// armPosition returns the position of `arm` in `arms`, or -1 if it isn't one of them.
+func armPosition(arms []mdp.Action, arm mdp.Action) int {
+	for i, a := range arms {
+		if a.Equals(arm) {
+			return i
+		}
+	}
+	return -1
+}
+
+type bernoulliBandit struct {
+	arms          []mdp.Action
+	probabilities []float32
+	rng           *rand.Rand
+}
+
+// NewBernoulliBandit creates a bandit whose ith arm pays 1 with probability `probabilities[i]` and 0 otherwise. Arms are
+// named by their position. `rng` is the source of randomness for rewards.
+func NewBernoulliBandit(probabilities []float32, rng *rand.Rand) (Bandit, error) {
+	if len(probabilities) == 0 {
+		return nil, errors.New("at least one arm must be provided")
+	} else if rng == nil {
+		return nil, errors.New("random source must be provided")
+	}
+	for _, p := range probabilities {
+		if p < 0 || p > 1.0 {
+			return nil, errors.New("probabilities must be in [0, 1.0]")
+		}
+	}
+	b := &bernoulliBandit{}
+	b.arms = newArms(len(probabilities))
+	b.probabilities = probabilities
+	b.rng = rng
+	return b, nil
+}
+
+func (b *bernoulliBandit) Arms() []mdp.Action {
+	return b.arms
+}
+
+func (b *bernoulliBandit) Pull(arm mdp.Action) (float32, error) {
+	i := armPosition(b.arms, arm)
+	if i < 0 {
+		return 0, errors.New("arm " + arm.String() + " doesn't exist")
+	}
+	if b.rng.Float32() < b.probabilities[i] {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (b *bernoulliBandit) Mean(arm mdp.Action) float32 {
+	if i := armPosition(b.arms, arm); i >= 0 {
+		return b.probabilities[i]
+	}
+	return 0
+}
+
+type gaussianBandit struct {
+	arms    []mdp.Action
+	means   []float32
+	stddevs []float32
+	rng     *rand.Rand
+}
+
+// NewGaussianBandit creates a bandit whose ith arm pays a reward drawn from a normal distribution with mean `means[i]`
+// and standard deviation `stddevs[i]`. Arms are named by their position. `rng` is the source of randomness for rewards.
+func NewGaussianBandit(means, stddevs []float32, rng *rand.Rand) (Bandit, error) {
+	if len(means) == 0 {
+		return nil, errors.New("at least one arm must be provided")
+	} else if len(means) != len(stddevs) {
+		return nil, errors.New("every arm must have a mean and a standard deviation")
+	} else if rng == nil {
+		return nil, errors.New("random source must be provided")
+	}
+	for _, stddev := range stddevs {
+		if stddev < 0 {
+			return nil, errors.New("standard deviations must be non-negative")
+		}
+	}
+	b := &gaussianBandit{}
+	b.arms = newArms(len(means))
+	b.means = means
+	b.stddevs = stddevs
+	b.rng = rng
+	return b, nil
+}
+
+func (b *gaussianBandit) Arms() []mdp.Action {
+	return b.arms
+}
+
+func (b *gaussianBandit) Pull(arm mdp.Action) (float32, error) {
+	i := armPosition(b.arms, arm)
+	if i < 0 {
+		return 0, errors.New("arm " + arm.String() + " doesn't exist")
+	}
+	return b.means[i] + b.stddevs[i]*float32(b.rng.NormFloat64()), nil
+}
+
+func (b *gaussianBandit) Mean(arm mdp.Action) float32 {
+	if i := armPosition(b.arms, arm); i >= 0 {
+		return b.means[i]
+	}
+	return 0
+}
+
+// Result records a run of an agent on a bandit.
+type Result struct {
+	// Rewards holds the reward of each pull.
+	Rewards []float32
+	// Regret holds the cumulative regret after each pull: the total shortfall of the expected reward of the arms
+	// pulled so far against always pulling the best arm.
+	Regret []float32
+	// OptimalPulls is the number of times an arm with the highest expected reward was pulled.
+	OptimalPulls int
+}
+
+// Run lets `agent` pull the arms of `b` `steps` times, learning from each reward.
+// Returns the result and a nil error on success or returns nil and a non-nil error on failure.
+func Run(b Bandit, agent Agent, steps int) (*Result, error) {
+	if steps <= 0 {
+		return nil, errors.New("steps must be positive")
+	}
+	best := b.Mean(b.Arms()[0])
+	for _, arm := range b.Arms() {
+		if b.Mean(arm) > best {
+			best = b.Mean(arm)
+		}
+	}
+
+	result := &Result{}
+	result.Rewards = make([]float32, steps)
+	result.Regret = make([]float32, steps)
+	regret := float32(0)
+	for t := 0; t < steps; t++ {
+		arm := agent.Choose()
+		reward, err := b.Pull(arm)
+		if err != nil {
+			return nil, err
+		}
+		agent.Update(arm, reward)
+
+		regret += best - b.Mean(arm)
+		result.Rewards[t] = reward
+		result.Regret[t] = regret
+		if b.Mean(arm) == best {
+			result.OptimalPulls++
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,121 @@
+package bandit
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/anthonykrivonos/go-rl/mdp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBandits(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	b, err := NewBernoulliBandit([]float32{0.2, 0.7}, rng)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0", "1"}, []string{b.Arms()[0].Name(), b.Arms()[1].Name()})
+	assert.Equal(t, float32(0.7), b.Mean(mdp.NewAction("1")))
+	total := float32(0)
+	for i := 0; i < 10000; i++ {
+		reward, err := b.Pull(mdp.NewAction("1"))
+		assert.NoError(t, err)
+		total += reward
+	}
+	assert.InDelta(t, 0.7, total/10000, 0.02)
+	_, err = b.Pull(mdp.NewAction("2"))
+	assert.Error(t, err)
+
+	g, err := NewGaussianBandit([]float32{1, -1}, []float32{2, 0}, rng)
+	assert.NoError(t, err)
+	reward, err := g.Pull(mdp.NewAction("1"))
+	assert.NoError(t, err)
+	assert.Equal(t, float32(-1), reward)
+
+	_, err = NewBernoulliBandit([]float32{1.5}, rng)
+	assert.Error(t, err)
+	_, err = NewBernoulliBandit(nil, rng)
+	assert.Error(t, err)
+	_, err = NewGaussianBandit([]float32{1}, []float32{1, 2}, rng)
+	assert.Error(t, err)
+}
+
+func TestAgentsFindTheBestArm(t *testing.T) {
+	steps := 3000
+	probabilities := []float32{0.1, 0.3, 0.5, 0.8}
+	arms := newArms(len(probabilities))
+
+	agents := map[string]func(rng *rand.Rand) (Agent, error){
+		"epsilon-greedy": func(rng *rand.Rand) (Agent, error) {
+			return NewEpsilonGreedy(arms, 0.05, rng)
+		},
+		"UCB1": func(_ *rand.Rand) (Agent, error) {
+			return NewUCB1(arms, 1.414)
+		},
+		"Beta Thompson": func(rng *rand.Rand) (Agent, error) {
+			return NewBetaThompson(arms, rng)
+		},
+		"Gaussian Thompson": func(rng *rand.Rand) (Agent, error) {
+			return NewGaussianThompson(arms, 0.5, rng)
+		},
+		"gradient": func(rng *rand.Rand) (Agent, error) {
+			return NewGradient(arms, 0.1, true, rng)
+		},
+	}
+
+	// A uniformly random agent would have a regret of about 0.375 per step
+	for name, newAgent := range agents {
+		b, err := NewBernoulliBandit(probabilities, rand.New(rand.NewSource(2)))
+		assert.NoError(t, err)
+		agent, err := newAgent(rand.New(rand.NewSource(3)))
+		assert.NoError(t, err)
+		result, err := Run(b, agent, steps)
+		assert.NoError(t, err)
+
+		assert.Len(t, result.Rewards, steps)
+		assert.True(t, result.Regret[steps-1] < 0.05*float32(steps), "%s regret %f", name, result.Regret[steps-1])
+		assert.True(t, result.OptimalPulls > steps*3/4, "%s optimal pulls %d", name, result.OptimalPulls)
+		for i := 1; i < steps; i++ {
+			assert.True(t, result.Regret[i] >= result.Regret[i-1])
+		}
+	}
+}
+
+func TestGaussianThompsonOnGaussianBandit(t *testing.T) {
+	b, err := NewGaussianBandit([]float32{0, 0.5, 1}, []float32{1, 1, 1}, rand.New(rand.NewSource(4)))
+	assert.NoError(t, err)
+	agent, err := NewGaussianThompson(b.Arms(), 1, rand.New(rand.NewSource(5)))
+	assert.NoError(t, err)
+	result, err := Run(b, agent, 2000)
+	assert.NoError(t, err)
+	assert.True(t, result.OptimalPulls > 1700, "optimal pulls %d", result.OptimalPulls)
+}
+
+func TestGradientBaselineHelps(t *testing.T) {
+	// With rewards far from zero, the baseline is what lets the gradient agent tell arms apart
+	means := []float32{4, 4.5, 5}
+	pulls := func(baseline bool) int {
+		total := 0
+		for run := 0; run < 20; run++ {
+			b, err := NewGaussianBandit(means, []float32{1, 1, 1}, rand.New(rand.NewSource(int64(run))))
+			assert.NoError(t, err)
+			agent, err := NewGradient(b.Arms(), 0.1, baseline, rand.New(rand.NewSource(int64(run))))
+			assert.NoError(t, err)
+			result, err := Run(b, agent, 500)
+			assert.NoError(t, err)
+			total += result.OptimalPulls
+		}
+		return total
+	}
+	assert.True(t, pulls(true) > pulls(false))
+}
+
+func TestSampleBeta(t *testing.T) {
+	rng := rand.New(rand.NewSource(6))
+	for _, params := range [][2]float64{{2, 5}, {0.5, 0.5}, {10, 1}} {
+		total := 0.0
+		for i := 0; i < 20000; i++ {
+			total += sampleBeta(rng, params[0], params[1])
+		}
+		assert.InDelta(t, params[0]/(params[0]+params[1]), total/20000, 0.01)
+	}
+}
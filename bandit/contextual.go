@@ -0,0 +1,333 @@
+package bandit
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/anthonykrivonos/go-rl/mdp"
+)
+
+// Context holds the feature vector of each arm for one round, in the order of the arms.
+type Context [][]float64
+
+// A contextual bandit, which shows a feature vector for each arm before every pull.
+type ContextualBandit interface {
+	// Arms returns the bandit's arms.
+	Arms() []mdp.Action
+	// Context returns the features of each arm for the current round.
+	Context() Context
+	// Pull pulls an arm, returns its reward, and moves on to the next round.
+	Pull(arm mdp.Action) (float32, error)
+	// Mean returns the expected reward of an arm in the current round.
+	Mean(arm mdp.Action) float32
+}
+
+// An agent that learns which arm of a contextual bandit to pull from each round's features.
+type ContextualAgent interface {
+	// Choose returns the arm to pull given the round's features.
+	Choose(context Context) mdp.Action
+	// Update learns from the reward of pulling an arm in a round with the given features.
+	Update(context Context, arm mdp.Action, reward float32)
+}
+
+type linearBandit struct {
+	arms    []mdp.Action
+	theta   []float64
+	noise   float64
+	rng     *rand.Rand
+	context Context
+}
+
+// NewLinearBandit creates a contextual bandit with `k` arms. Every round, each arm's features are drawn from a standard
+// normal distribution, and the arm pays the dot product of its features with `theta` plus Gaussian noise with standard
+// deviation `noise`. Arms are named by their position. `rng` is the source of randomness for features and rewards.
+func NewLinearBandit(k int, theta []float64, noise float32, rng *rand.Rand) (ContextualBandit, error) {
+	if k <= 0 {
+		return nil, errors.New("at least one arm must be provided")
+	} else if len(theta) == 0 {
+		return nil, errors.New("theta must be provided")
+	} else if noise < 0 {
+		return nil, errors.New("noise must be non-negative")
+	} else if rng == nil {
+		return nil, errors.New("random source must be provided")
+	}
+	b := &linearBandit{}
+	b.arms = newArms(k)
+	b.theta = theta
+	b.noise = float64(noise)
+	b.rng = rng
+	b.next()
+	return b, nil
+}
+
+// next draws the features of the next round.
+func (b *linearBandit) next() {
+	b.context = make(Context, len(b.arms))
+	for i := range b.context {
+		b.context[i] = make([]float64, len(b.theta))
+		for j := range b.context[i] {
+			b.context[i][j] = b.rng.NormFloat64()
+		}
+	}
+}
+
+func (b *linearBandit) Arms() []mdp.Action {
+	return b.arms
+}
+
+func (b *linearBandit) Context() Context {
+	return b.context
+}
+
+func (b *linearBandit) Pull(arm mdp.Action) (float32, error) {
+	i := armPosition(b.arms, arm)
+	if i < 0 {
+		return 0, errors.New("arm " + arm.String() + " doesn't exist")
+	}
+	reward := dot(b.theta, b.context[i]) + b.noise*b.rng.NormFloat64()
+	b.next()
+	return float32(reward), nil
+}
+
+func (b *linearBandit) Mean(arm mdp.Action) float32 {
+	if i := armPosition(b.arms, arm); i >= 0 {
+		return float32(dot(b.theta, b.context[i]))
+	}
+	return 0
+}
+
+// RunContextual lets `agent` pull the arms of `b` `steps` times, learning from each reward. Regret is measured against
+// the best arm of each round.
+// Returns the result and a nil error on success or returns nil and a non-nil error on failure.
+func RunContextual(b ContextualBandit, agent ContextualAgent, steps int) (*Result, error) {
+	if steps <= 0 {
+		return nil, errors.New("steps must be positive")
+	}
+	result := &Result{}
+	result.Rewards = make([]float32, steps)
+	result.Regret = make([]float32, steps)
+	regret := float32(0)
+	for t := 0; t < steps; t++ {
+		best := b.Mean(b.Arms()[0])
+		for _, arm := range b.Arms() {
+			if b.Mean(arm) > best {
+				best = b.Mean(arm)
+			}
+		}
+
+		context := b.Context()
+		arm := agent.Choose(context)
+		mean := b.Mean(arm)
+		reward, err := b.Pull(arm)
+		if err != nil {
+			return nil, err
+		}
+		agent.Update(context, arm, reward)
+
+		regret += best - mean
+		result.Rewards[t] = reward
+		result.Regret[t] = regret
+		if mean == best {
+			result.OptimalPulls++
+		}
+	}
+	return result, nil
+}
+
+// ridge is a ridge regression of reward on features for one arm, kept as the inverse of A = I + Σ xxᵀ and b = Σ rx.
+type ridge struct {
+	inverse [][]float64
+	b       []float64
+}
+
+func newRidge(dimension int) *ridge {
+	return &ridge{identity(dimension), make([]float64, dimension)}
+}
+
+// theta returns the regression weights A⁻¹b.
+func (r *ridge) theta() []float64 {
+	return multiply(r.inverse, r.b)
+}
+
+// update adds an observation of `reward` for features `x`.
+func (r *ridge) update(x []float64, reward float32) {
+	shermanMorrison(r.inverse, x)
+	for i := range r.b {
+		r.b[i] += float64(reward) * x[i]
+	}
+}
+
+// linearModels holds a ridge regression per arm.
+type linearModels struct {
+	arms      []mdp.Action
+	dimension int
+	models    []*ridge
+}
+
+func newLinearModels(arms []mdp.Action, dimension int) (linearModels, error) {
+	if len(arms) == 0 {
+		return linearModels{}, errors.New("at least one arm must be provided")
+	} else if dimension <= 0 {
+		return linearModels{}, errors.New("dimension must be positive")
+	}
+	models := make([]*ridge, len(arms))
+	for i := range models {
+		models[i] = newRidge(dimension)
+	}
+	return linearModels{arms, dimension, models}, nil
+}
+
+// fits reports whether the context holds a feature vector of the models' dimension at position `i`.
+func (l *linearModels) fits(context Context, i int) bool {
+	return i < len(context) && len(context[i]) == l.dimension
+}
+
+// checkContext returns a non-nil error unless the context holds one feature vector of the models' dimension per arm.
+func (l *linearModels) checkContext(context Context) error {
+	if len(context) != len(l.arms) {
+		return errors.New("context must have " + fmt.Sprint(len(l.arms)) + " feature vectors, not " + fmt.Sprint(len(context)))
+	}
+	for i := range context {
+		if !l.fits(context, i) {
+			return errors.New("feature vector " + fmt.Sprint(i) + " must have " + fmt.Sprint(l.dimension) + " features, not " + fmt.Sprint(len(context[i])))
+		}
+	}
+	return nil
+}
+
+// Update ignores rounds where the pulled arm has no feature vector of the models' dimension.
+func (l *linearModels) Update(context Context, arm mdp.Action, reward float32) {
+	if i := armPosition(l.arms, arm); i >= 0 && l.fits(context, i) {
+		l.models[i].update(context[i], reward)
+	}
+}
+
+type linUCB struct {
+	linearModels
+	alpha float64
+}
+
+// NewLinUCB creates a LinUCB agent with a separate linear model of each arm's reward over `dimension` features. It pulls
+// the arm with the highest upper confidence bound θᵀx + α √(xᵀA⁻¹x).
+func NewLinUCB(arms []mdp.Action, dimension int, alpha float32) (ContextualAgent, error) {
+	models, err := newLinearModels(arms, dimension)
+	if err != nil {
+		return nil, err
+	} else if alpha < 0 {
+		return nil, errors.New("alpha must be non-negative")
+	}
+	a := &linUCB{}
+	a.linearModels = models
+	a.alpha = float64(alpha)
+	return a, nil
+}
+
+// Choose skips arms without a feature vector of the agent's dimension, and pulls the first arm if none has one.
+func (a *linUCB) Choose(context Context) mdp.Action {
+	best := 0
+	bestBound := math.Inf(-1)
+	for i, model := range a.models {
+		if !a.fits(context, i) {
+			continue
+		}
+		x := context[i]
+		bound := dot(model.theta(), x) + a.alpha*math.Sqrt(math.Max(0, dot(x, multiply(model.inverse, x))))
+		if bound > bestBound {
+			best = i
+			bestBound = bound
+		}
+	}
+	return a.arms[best]
+}
+
+type linearThompson struct {
+	linearModels
+	scale float64
+	rng   *rand.Rand
+}
+
+// NewLinearThompson creates a linear Thompson sampling agent with a separate linear model of each arm's reward over
+// `dimension` features. It samples each arm's weights from N(θ, v²A⁻¹) and pulls the arm whose sampled weights predict
+// the highest reward. `rng` is the source of randomness for sampling.
+func NewLinearThompson(arms []mdp.Action, dimension int, v float32, rng *rand.Rand) (ContextualAgent, error) {
+	models, err := newLinearModels(arms, dimension)
+	if err != nil {
+		return nil, err
+	} else if v <= 0 {
+		return nil, errors.New("v must be positive")
+	} else if rng == nil {
+		return nil, errors.New("random source must be provided")
+	}
+	a := &linearThompson{}
+	a.linearModels = models
+	a.scale = float64(v)
+	a.rng = rng
+	return a, nil
+}
+
+// Choose skips arms without a feature vector of the agent's dimension, and pulls the first arm if none has one.
+func (a *linearThompson) Choose(context Context) mdp.Action {
+	best := 0
+	bestSample := math.Inf(-1)
+	for i, model := range a.models {
+		if !a.fits(context, i) {
+			continue
+		}
+		z := make([]float64, a.dimension)
+		for j := range z {
+			z[j] = a.rng.NormFloat64()
+		}
+		theta := model.theta()
+		noise := multiply(cholesky(model.inverse), z)
+		for j := range theta {
+			theta[j] += a.scale * noise[j]
+		}
+		if sample := dot(theta, context[i]); sample > bestSample {
+			best = i
+			bestSample = sample
+		}
+	}
+	return a.arms[best]
+}
+
+// contextChecker is implemented by agents that know the shape of the contexts they accept.
+type contextChecker interface {
+	checkContext(context Context) error
+}
+
+// LoggedEvent is one round of logged bandit data: the features shown, the arm the logging policy pulled, and its reward.
+type LoggedEvent struct {
+	Context Context
+	Arm     mdp.Action
+	Reward  float32
+}
+
+// ReplayEvaluate estimates the average reward per round of `agent` from logged data with the replay method of Li et al.
+// Events are replayed in order, and those where the agent chooses the logged arm count towards the estimate and are
+// learned from; the rest are skipped. The estimate is unbiased if the logging policy chose arms uniformly at random.
+// Returns the estimate, the number of events that counted and a nil error on success, or returns 0, 0 and a non-nil
+// error if no events counted or, for the linear agents, if an event's context doesn't have one feature vector of the
+// agent's dimension per arm.
+func ReplayEvaluate(agent ContextualAgent, log []LoggedEvent) (float32, int, error) {
+	checker, checked := agent.(contextChecker)
+	total := float32(0)
+	matches := 0
+	for n, event := range log {
+		if checked {
+			if err := checker.checkContext(event.Context); err != nil {
+				return 0, 0, errors.New("event " + fmt.Sprint(n) + ": " + err.Error())
+			}
+		}
+		if agent.Choose(event.Context).Equals(event.Arm) {
+			agent.Update(event.Context, event.Arm, event.Reward)
+			total += event.Reward
+			matches++
+		}
+	}
+	if matches == 0 {
+		return 0, 0, errors.New("no logged events match the agent's choices")
+	}
+	return total / float32(matches), matches, nil
+}
@@ -0,0 +1,134 @@
+package bandit
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/anthonykrivonos/go-rl/mdp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinearAlgebra(t *testing.T) {
+	// Inverse of I + xxᵀ for x = (1, 1) is I - xxᵀ/3
+	inverse := identity(2)
+	shermanMorrison(inverse, []float64{1, 1})
+	assert.InDelta(t, 2.0/3, inverse[0][0], 1e-9)
+	assert.InDelta(t, -1.0/3, inverse[0][1], 1e-9)
+
+	l := cholesky([][]float64{{4, 2}, {2, 5}})
+	assert.InDelta(t, 2, l[0][0], 1e-9)
+	assert.InDelta(t, 1, l[1][0], 1e-9)
+	assert.InDelta(t, 2, l[1][1], 1e-9)
+	assert.Equal(t, 0.0, l[0][1])
+}
+
+func TestContextualAgentsLearn(t *testing.T) {
+	theta := []float64{1, -0.5, 0.25}
+	steps := 2000
+
+	agents := map[string]func(arms []mdp.Action, rng *rand.Rand) (ContextualAgent, error){
+		"LinUCB": func(arms []mdp.Action, _ *rand.Rand) (ContextualAgent, error) {
+			return NewLinUCB(arms, len(theta), 1)
+		},
+		"linear Thompson": func(arms []mdp.Action, rng *rand.Rand) (ContextualAgent, error) {
+			return NewLinearThompson(arms, len(theta), 0.5, rng)
+		},
+	}
+	for name, newAgent := range agents {
+		rng := rand.New(rand.NewSource(2))
+		b, err := NewLinearBandit(4, theta, 0.1, rng)
+		assert.NoError(t, err)
+		agent, err := newAgent(b.Arms(), rng)
+		assert.NoError(t, err)
+		result, err := RunContextual(b, agent, steps)
+		assert.NoError(t, err)
+
+		// Regret grows sublinearly, so the second half adds far less than the first
+		half := result.Regret[steps/2-1]
+		assert.Less(t, result.Regret[steps-1]-half, half/2, name)
+		assert.Greater(t, result.OptimalPulls, steps*3/4, name)
+	}
+
+	_, err := NewLinUCB(nil, 2, 1)
+	assert.Error(t, err)
+	_, err = NewLinUCB(newArms(2), 0, 1)
+	assert.Error(t, err)
+	_, err = NewLinearThompson(newArms(2), 2, 0, rand.New(rand.NewSource(1)))
+	assert.Error(t, err)
+}
+
+// oracle pulls the arm with the highest mean under known weights.
+type oracle struct {
+	arms  []mdp.Action
+	theta []float64
+}
+
+func (o *oracle) Choose(context Context) mdp.Action {
+	best := 0
+	for i := range context {
+		if dot(o.theta, context[i]) > dot(o.theta, context[best]) {
+			best = i
+		}
+	}
+	return o.arms[best]
+}
+
+func (o *oracle) Update(Context, mdp.Action, float32) {}
+
+func TestReplayEvaluate(t *testing.T) {
+	theta := []float64{1, 0.5}
+	rng := rand.New(rand.NewSource(3))
+	b, err := NewLinearBandit(3, theta, 0.1, rng)
+	assert.NoError(t, err)
+
+	// Log rounds under a uniformly random policy, and track what the oracle would truly have earned
+	log := make([]LoggedEvent, 30000)
+	oracleTotal := float32(0)
+	o := &oracle{b.Arms(), theta}
+	for i := range log {
+		context := b.Context()
+		oracleTotal += b.Mean(o.Choose(context))
+		arm := b.Arms()[rng.Intn(len(b.Arms()))]
+		reward, err := b.Pull(arm)
+		assert.NoError(t, err)
+		log[i] = LoggedEvent{context, arm, reward}
+	}
+
+	value, matches, err := ReplayEvaluate(o, log)
+	assert.NoError(t, err)
+	assert.InDelta(t, len(log)/3, matches, 500)
+	assert.InDelta(t, oracleTotal/float32(len(log)), value, 0.05)
+
+	agent, err := NewLinUCB(b.Arms(), len(theta), 1)
+	assert.NoError(t, err)
+	learned, _, err := ReplayEvaluate(agent, log)
+	assert.NoError(t, err)
+	assert.Greater(t, learned, value-0.1)
+
+	_, _, err = ReplayEvaluate(o, nil)
+	assert.Error(t, err)
+}
+
+func TestReplayEvaluateMalformedLog(t *testing.T) {
+	arms := newArms(2)
+	agent, err := NewLinUCB(arms, 2, 1)
+	assert.NoError(t, err)
+	good := Context{{1, 0}, {0, 1}}
+
+	// Too few feature vectors, or a vector of the wrong length, is an error rather than a panic
+	_, _, err = ReplayEvaluate(agent, []LoggedEvent{{good, arms[0], 1}, {Context{{1, 0}}, arms[1], 1}})
+	assert.Error(t, err)
+	_, _, err = ReplayEvaluate(agent, []LoggedEvent{{Context{{1, 0}, {0, 1, 2}}, arms[0], 1}})
+	assert.Error(t, err)
+
+	// Outside replay, malformed arms are skipped and malformed updates ignored
+	thompson, err := NewLinearThompson(arms, 2, 0.5, rand.New(rand.NewSource(1)))
+	assert.NoError(t, err)
+	for _, a := range []ContextualAgent{agent, thompson} {
+		assert.Equal(t, arms[1], a.Choose(Context{{1}, {0, 1}}))
+		assert.Equal(t, arms[0], a.Choose(nil))
+		a.Update(Context{{1, 0}}, arms[1], 1)
+		a.Update(Context{{1, 0}, {1}}, arms[1], 1)
+	}
+	assert.Equal(t, []float64{0, 0}, agent.(*linUCB).models[1].b)
+}
@@ -0,0 +1,66 @@
+package bandit
+
+import "math"
+
+// identity returns the n×n identity matrix.
+func identity(n int) [][]float64 {
+	m := make([][]float64, n)
+	for i := range m {
+		m[i] = make([]float64, n)
+		m[i][i] = 1
+	}
+	return m
+}
+
+// dot returns the dot product of two vectors.
+func dot(a, b []float64) float64 {
+	total := 0.0
+	for i := range a {
+		total += a[i] * b[i]
+	}
+	return total
+}
+
+// multiply returns the product of a matrix and a vector.
+func multiply(m [][]float64, v []float64) []float64 {
+	res := make([]float64, len(m))
+	for i, row := range m {
+		res[i] = dot(row, v)
+	}
+	return res
+}
+
+// shermanMorrison updates the inverse `inverse` of a symmetric matrix A in place to the inverse of A + xxᵀ.
+func shermanMorrison(inverse [][]float64, x []float64) {
+	u := multiply(inverse, x)
+	denominator := 1 + dot(x, u)
+	for i := range inverse {
+		for j := range inverse[i] {
+			inverse[i][j] -= u[i] * u[j] / denominator
+		}
+	}
+}
+
+// cholesky returns the lower-triangular L with LLᵀ = m for a symmetric positive-definite matrix m. Tiny negative pivots
+// from rounding error are clamped to zero.
+func cholesky(m [][]float64) [][]float64 {
+	n := len(m)
+	l := make([][]float64, n)
+	for i := range l {
+		l[i] = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			sum := m[i][j]
+			for k := 0; k < j; k++ {
+				sum -= l[i][k] * l[j][k]
+			}
+			if i == j {
+				l[i][i] = math.Sqrt(math.Max(sum, 0))
+			} else if l[j][j] > 0 {
+				l[i][j] = sum / l[j][j]
+			}
+		}
+	}
+	return l
+}
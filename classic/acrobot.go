@@ -0,0 +1,132 @@
+package classic
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/anthonykrivonos/go-rl/env"
+	"github.com/anthonykrivonos/go-rl/mdp"
+)
+
+const (
+	acrobotLength1    = 1.0
+	acrobotMass1      = 1.0
+	acrobotMass2      = 1.0
+	acrobotCenter1    = 0.5
+	acrobotCenter2    = 0.5
+	acrobotInertia    = 1.0
+	acrobotGravity    = 9.8
+	acrobotTimeStep   = 0.2
+	acrobotMaxSpeed1  = 4 * math.Pi
+	acrobotMaxSpeed2  = 9 * math.Pi
+	acrobotMaxTorque  = 1.0
+	acrobotStateCount = 4
+)
+
+type acrobot struct {
+	rng     *rand.Rand
+	actions []mdp.Action
+	// Joint angles and angular velocities
+	state [acrobotStateCount]float64
+	done  bool
+}
+
+// NewAcrobot creates the acrobot swing-up task: a two-link pendulum, actuated only at the joint between the links, must
+// swing the tip of its lower link one link length above its pivot. Actions "left", "idle" and "right" apply a torque of
+// -1, 0 or 1 to the joint, and observations are the cosine and sine of both joint angles followed by their angular
+// velocities. Every step pays -1 until the goal is reached, which pays 0 and ends the episode.
+// `rng` is the source of randomness for the initial state.
+func NewAcrobot(rng *rand.Rand) (env.Environment, error) {
+	if rng == nil {
+		return nil, errRandom
+	}
+	e := &acrobot{}
+	e.rng = rng
+	e.actions = mdp.NewActions([]string{"left", "idle", "right"})
+	e.done = true
+	return e, nil
+}
+
+func (e *acrobot) observe() mdp.State {
+	s := e.state
+	return env.NewObservation([]float64{
+		math.Cos(s[0]), math.Sin(s[0]), math.Cos(s[1]), math.Sin(s[1]), s[2], s[3],
+	}, e.done)
+}
+
+func (e *acrobot) Actions() []mdp.Action {
+	if e.done {
+		return nil
+	}
+	return e.actions
+}
+
+func (e *acrobot) Reset() (mdp.State, error) {
+	for i := range e.state {
+		e.state[i] = uniform(e.rng, -0.1, 0.1)
+	}
+	e.done = false
+	return e.observe(), nil
+}
+
+func (e *acrobot) Step(action mdp.Action) (mdp.State, float32, bool, error) {
+	if e.done {
+		return nil, 0, true, errDone
+	}
+	a, err := actionPosition(e.actions, action)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	torque := float64(a-1) * acrobotMaxTorque
+
+	// One fourth-order Runge-Kutta step over the time step
+	s := e.state
+	h := acrobotTimeStep
+	k1 := acrobotDerivatives(s, torque)
+	k2 := acrobotDerivatives(acrobotOffset(s, k1, h/2), torque)
+	k3 := acrobotDerivatives(acrobotOffset(s, k2, h/2), torque)
+	k4 := acrobotDerivatives(acrobotOffset(s, k3, h), torque)
+	for i := range s {
+		s[i] += h / 6 * (k1[i] + 2*k2[i] + 2*k3[i] + k4[i])
+	}
+	s[0] = wrap(s[0])
+	s[1] = wrap(s[1])
+	s[2] = clip(s[2], -acrobotMaxSpeed1, acrobotMaxSpeed1)
+	s[3] = clip(s[3], -acrobotMaxSpeed2, acrobotMaxSpeed2)
+	e.state = s
+
+	e.done = -math.Cos(s[0])-math.Cos(s[0]+s[1]) > 1
+	if e.done {
+		return e.observe(), 0, true, nil
+	}
+	return e.observe(), -1, false, nil
+}
+
+// acrobotOffset returns s + h·d.
+func acrobotOffset(s, d [acrobotStateCount]float64, h float64) [acrobotStateCount]float64 {
+	for i := range s {
+		s[i] += h * d[i]
+	}
+	return s
+}
+
+// acrobotDerivatives returns the time derivative of the acrobot's state under `torque`, using the equations of motion
+// from Sutton and Barto.
+func acrobotDerivatives(s [acrobotStateCount]float64, torque float64) [acrobotStateCount]float64 {
+	theta1, theta2, dTheta1, dTheta2 := s[0], s[1], s[2], s[3]
+	m1, m2 := acrobotMass1, acrobotMass2
+	l1, lc1, lc2 := acrobotLength1, acrobotCenter1, acrobotCenter2
+	i1, i2 := acrobotInertia, acrobotInertia
+	g := acrobotGravity
+
+	d1 := m1*lc1*lc1 + m2*(l1*l1+lc2*lc2+2*l1*lc2*math.Cos(theta2)) + i1 + i2
+	d2 := m2*(lc2*lc2+l1*lc2*math.Cos(theta2)) + i2
+	phi2 := m2 * lc2 * g * math.Cos(theta1+theta2-math.Pi/2)
+	phi1 := -m2*l1*lc2*dTheta2*dTheta2*math.Sin(theta2) -
+		2*m2*l1*lc2*dTheta2*dTheta1*math.Sin(theta2) +
+		(m1*lc1+m2*l1)*g*math.Cos(theta1-math.Pi/2) + phi2
+	ddTheta2 := (torque + d2/d1*phi1 - m2*l1*lc2*dTheta1*dTheta1*math.Sin(theta2) - phi2) /
+		(m2*lc2*lc2 + i2 - d2*d2/d1)
+	ddTheta1 := -(d2*ddTheta2 + phi1) / d1
+	return [acrobotStateCount]float64{dTheta1, dTheta2, ddTheta1, ddTheta2}
+}
@@ -0,0 +1,95 @@
+package classic
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/anthonykrivonos/go-rl/env"
+	"github.com/anthonykrivonos/go-rl/mdp"
+)
+
+const (
+	cartPoleGravity    = 9.8
+	cartPoleCartMass   = 1.0
+	cartPolePoleMass   = 0.1
+	cartPoleHalfLength = 0.5
+	cartPoleForce      = 10.0
+	cartPoleTau        = 0.02
+	// The episode fails once the cart leaves [-2.4, 2.4] or the pole tilts more than 12 degrees
+	cartPoleMaxPosition = 2.4
+	cartPoleMaxAngle    = 12 * math.Pi / 180
+)
+
+type cartPole struct {
+	rng     *rand.Rand
+	actions []mdp.Action
+	// Cart position and velocity, and pole angle and angular velocity
+	x, xDot, theta, thetaDot float64
+	done                     bool
+}
+
+// NewCartPole creates the cart-pole balancing task. Actions "left" and "right" push the cart with a fixed force, and
+// observations are the cart's position and velocity and the pole's angle and angular velocity. Every step pays 1, and
+// the episode ends when the pole falls past 12 degrees or the cart leaves the track.
+// `rng` is the source of randomness for the initial state.
+func NewCartPole(rng *rand.Rand) (env.Environment, error) {
+	if rng == nil {
+		return nil, errRandom
+	}
+	e := &cartPole{}
+	e.rng = rng
+	e.actions = mdp.NewActions([]string{"left", "right"})
+	e.done = true
+	return e, nil
+}
+
+func (e *cartPole) observe() mdp.State {
+	return env.NewObservation([]float64{e.x, e.xDot, e.theta, e.thetaDot}, e.done)
+}
+
+func (e *cartPole) Actions() []mdp.Action {
+	if e.done {
+		return nil
+	}
+	return e.actions
+}
+
+func (e *cartPole) Reset() (mdp.State, error) {
+	e.x = uniform(e.rng, -0.05, 0.05)
+	e.xDot = uniform(e.rng, -0.05, 0.05)
+	e.theta = uniform(e.rng, -0.05, 0.05)
+	e.thetaDot = uniform(e.rng, -0.05, 0.05)
+	e.done = false
+	return e.observe(), nil
+}
+
+func (e *cartPole) Step(action mdp.Action) (mdp.State, float32, bool, error) {
+	if e.done {
+		return nil, 0, true, errDone
+	}
+	a, err := actionPosition(e.actions, action)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	force := cartPoleForce
+	if a == 0 {
+		force = -force
+	}
+
+	totalMass := cartPoleCartMass + cartPolePoleMass
+	poleMassLength := cartPolePoleMass * cartPoleHalfLength
+	cos, sin := math.Cos(e.theta), math.Sin(e.theta)
+	temp := (force + poleMassLength*e.thetaDot*e.thetaDot*sin) / totalMass
+	thetaAcc := (cartPoleGravity*sin - cos*temp) /
+		(cartPoleHalfLength * (4.0/3.0 - cartPolePoleMass*cos*cos/totalMass))
+	xAcc := temp - poleMassLength*thetaAcc*cos/totalMass
+
+	// Euler integration
+	e.x += cartPoleTau * e.xDot
+	e.xDot += cartPoleTau * xAcc
+	e.theta += cartPoleTau * e.thetaDot
+	e.thetaDot += cartPoleTau * thetaAcc
+
+	e.done = math.Abs(e.x) > cartPoleMaxPosition || math.Abs(e.theta) > cartPoleMaxAngle
+	return e.observe(), 1, e.done, nil
+}
@@ -0,0 +1,43 @@
+package classic
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+
+	"github.com/anthonykrivonos/go-rl/mdp"
+)
+
+var (
+	errRandom = errors.New("random source must be provided")
+	errDone   = errors.New("episode is over; call Reset to start a new one")
+)
+
+// actionPosition returns the position of `action` in `actions` and a nil error, or returns -1 and a non-nil error if
+// it isn't one of them.
+func actionPosition(actions []mdp.Action, action mdp.Action) (int, error) {
+	if action == nil {
+		return -1, errors.New("action must be provided")
+	}
+	for i, a := range actions {
+		if a.Equals(action) {
+			return i, nil
+		}
+	}
+	return -1, errors.New("action " + action.String() + " isn't available")
+}
+
+// uniform returns a value drawn uniformly from [low, high).
+func uniform(rng *rand.Rand, low float64, high float64) float64 {
+	return low + (high-low)*rng.Float64()
+}
+
+// clip returns `value` limited to [low, high].
+func clip(value float64, low float64, high float64) float64 {
+	return math.Max(low, math.Min(high, value))
+}
+
+// wrap returns `angle` wrapped into [-π, π).
+func wrap(angle float64) float64 {
+	return angle - 2*math.Pi*math.Floor((angle+math.Pi)/(2*math.Pi))
+}
@@ -0,0 +1,196 @@
+package classic
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/anthonykrivonos/go-rl/env"
+	"github.com/anthonykrivonos/go-rl/mdp"
+	"github.com/stretchr/testify/assert"
+)
+
+// fixedPolicy is an agent that acts by a fixed rule on observation vectors and learns nothing.
+type fixedPolicy func(vector []float64, actions []mdp.Action) mdp.Action
+
+func (p fixedPolicy) Act(state mdp.State, actions []mdp.Action) mdp.Action {
+	return p(state.(env.Observation).Vector(), actions)
+}
+
+func (p fixedPolicy) Learn(env.Step, []mdp.Action) {}
+
+func (p fixedPolicy) EndEpisode() {}
+
+// first always takes the first action.
+var first = fixedPolicy(func(_ []float64, actions []mdp.Action) mdp.Action {
+	return actions[0]
+})
+
+func TestEnvironmentsAreDeterministic(t *testing.T) {
+	constructors := map[string]func(rng *rand.Rand) (env.Environment, error){
+		"cart pole":    NewCartPole,
+		"mountain car": NewMountainCar,
+		"acrobot":      NewAcrobot,
+		"pendulum": func(rng *rand.Rand) (env.Environment, error) {
+			return NewPendulum(5, rng)
+		},
+	}
+	for name, newEnvironment := range constructors {
+		run := func(seed int64) env.Trajectory {
+			e, err := newEnvironment(rand.New(rand.NewSource(seed)))
+			assert.NoError(t, err)
+			policy := rand.New(rand.NewSource(seed))
+			random := fixedPolicy(func(_ []float64, actions []mdp.Action) mdp.Action {
+				return actions[policy.Intn(len(actions))]
+			})
+			trajectory, err := env.RunEpisode(e, random, 100)
+			assert.NoError(t, err)
+			return trajectory
+		}
+		a, b, c := run(1), run(1), run(2)
+		assert.Equal(t, len(a), len(b), name)
+		for i := range a {
+			assert.True(t, a[i].NextState.Equals(b[i].NextState), name)
+			assert.Equal(t, a[i].Reward, b[i].Reward, name)
+		}
+		assert.False(t, a[0].State.Equals(c[0].State), name)
+
+		_, err := newEnvironment(nil)
+		assert.Error(t, err, name)
+		e, err := newEnvironment(rand.New(rand.NewSource(1)))
+		assert.NoError(t, err)
+		assert.Empty(t, e.Actions(), name)
+		_, _, _, err = e.Step(mdp.NewAction("left"))
+		assert.Error(t, err, name)
+		_, err = e.Reset()
+		assert.NoError(t, err)
+		_, _, _, err = e.Step(mdp.NewAction("jump"))
+		assert.Error(t, err, name)
+		_, _, _, err = e.Step(nil)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestCartPole(t *testing.T) {
+	e, err := NewCartPole(rand.New(rand.NewSource(1)))
+	assert.NoError(t, err)
+	assert.Len(t, e.Actions(), 0)
+
+	// Always pushing one way topples the pole within a few dozen steps
+	trajectory, err := env.RunEpisode(e, first, 500)
+	assert.NoError(t, err)
+	assert.True(t, trajectory[len(trajectory)-1].Done)
+	assert.Less(t, len(trajectory), 50)
+	assert.Equal(t, float32(len(trajectory)), trajectory.Return(1))
+	last := trajectory[len(trajectory)-1].NextState.(env.Observation).Vector()
+	assert.Greater(t, last[2], cartPoleMaxAngle)
+
+	// Pushing towards the side the pole is falling to balances it far longer
+	balance := fixedPolicy(func(v []float64, actions []mdp.Action) mdp.Action {
+		if v[2]+0.5*v[3] < 0 {
+			return actions[0]
+		}
+		return actions[1]
+	})
+	trajectory, err = env.RunEpisode(e, balance, 500)
+	assert.NoError(t, err)
+	assert.Greater(t, len(trajectory), 200)
+}
+
+func TestMountainCar(t *testing.T) {
+	e, err := NewMountainCar(rand.New(rand.NewSource(1)))
+	assert.NoError(t, err)
+
+	// Pushing straight to the right isn't enough to climb the hill
+	right := fixedPolicy(func(_ []float64, actions []mdp.Action) mdp.Action {
+		return actions[2]
+	})
+	trajectory, err := env.RunEpisode(e, right, 500)
+	assert.NoError(t, err)
+	assert.Len(t, trajectory, 500)
+	assert.Equal(t, float32(-500), trajectory.Return(1))
+
+	// Pushing along the velocity builds up enough momentum
+	pump := fixedPolicy(func(v []float64, actions []mdp.Action) mdp.Action {
+		if v[1] < 0 {
+			return actions[0]
+		}
+		return actions[2]
+	})
+	trajectory, err = env.RunEpisode(e, pump, 500)
+	assert.NoError(t, err)
+	assert.True(t, trajectory[len(trajectory)-1].Done)
+	assert.Less(t, len(trajectory), 200)
+	for _, step := range trajectory {
+		v := step.NextState.(env.Observation).Vector()
+		assert.True(t, v[0] >= MountainCarLow[0] && v[0] <= MountainCarHigh[0])
+		assert.True(t, v[1] >= MountainCarLow[1] && v[1] <= MountainCarHigh[1])
+	}
+}
+
+func TestAcrobot(t *testing.T) {
+	e, err := NewAcrobot(rand.New(rand.NewSource(1)))
+	assert.NoError(t, err)
+
+	// Idling leaves the links hanging near the bottom
+	idle := fixedPolicy(func(_ []float64, actions []mdp.Action) mdp.Action {
+		return actions[1]
+	})
+	trajectory, err := env.RunEpisode(e, idle, 200)
+	assert.NoError(t, err)
+	assert.Len(t, trajectory, 200)
+	v := trajectory[len(trajectory)-1].NextState.(env.Observation).Vector()
+	assert.Greater(t, v[0], 0.9)
+	assert.InDelta(t, 1, v[0]*v[0]+v[1]*v[1], 1e-9)
+
+	// Torquing along the joint's swing pumps in energy until the tip swings up
+	pump := fixedPolicy(func(v []float64, actions []mdp.Action) mdp.Action {
+		if v[5] < 0 {
+			return actions[0]
+		}
+		return actions[2]
+	})
+	trajectory, err = env.RunEpisode(e, pump, 1000)
+	assert.NoError(t, err)
+	assert.True(t, trajectory[len(trajectory)-1].Done)
+	assert.Equal(t, float32(0), trajectory[len(trajectory)-1].Reward)
+	assert.Equal(t, float32(1-len(trajectory)), trajectory.Return(1))
+}
+
+func TestPendulum(t *testing.T) {
+	e, err := NewPendulum(3, rand.New(rand.NewSource(1)))
+	assert.NoError(t, err)
+	_, err = e.Reset()
+	assert.NoError(t, err)
+	names := []string{}
+	for _, action := range e.Actions() {
+		names = append(names, action.Name())
+	}
+	assert.Equal(t, []string{"-2", "0", "2"}, names)
+
+	trajectory, err := env.RunEpisode(e, first, 200)
+	assert.NoError(t, err)
+	assert.Len(t, trajectory, 200)
+	for _, step := range trajectory {
+		assert.LessOrEqual(t, step.Reward, float32(0))
+		assert.False(t, step.Done)
+		v := step.NextState.(env.Observation).Vector()
+		assert.LessOrEqual(t, math.Abs(v[2]), pendulumMaxSpeed)
+	}
+
+	// Upright and still costs only the torque
+	p := e.(*pendulum)
+	p.theta, p.thetaDot = 0, 0
+	_, reward, _, err := e.Step(e.Actions()[1])
+	assert.NoError(t, err)
+	assert.Equal(t, float32(0), reward)
+
+	_, err = NewPendulum(1, rand.New(rand.NewSource(1)))
+	assert.Error(t, err)
+}
+
+func TestWrap(t *testing.T) {
+	assert.InDelta(t, 0, wrap(2*math.Pi), 1e-12)
+	assert.InDelta(t, -math.Pi/2, wrap(3*math.Pi/2), 1e-12)
+	assert.InDelta(t, math.Pi/2, wrap(-3*math.Pi/2), 1e-12)
+}
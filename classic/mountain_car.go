@@ -0,0 +1,84 @@
+package classic
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/anthonykrivonos/go-rl/env"
+	"github.com/anthonykrivonos/go-rl/mdp"
+)
+
+const (
+	mountainCarMinPosition = -1.2
+	mountainCarMaxPosition = 0.6
+	mountainCarGoal        = 0.5
+	mountainCarMaxSpeed    = 0.07
+	mountainCarForce       = 0.001
+	mountainCarGravity     = 0.0025
+)
+
+var (
+	// MountainCarLow is the lowest position and velocity of the mountain car.
+	MountainCarLow = []float64{mountainCarMinPosition, -mountainCarMaxSpeed}
+	// MountainCarHigh is the highest position and velocity of the mountain car.
+	MountainCarHigh = []float64{mountainCarMaxPosition, mountainCarMaxSpeed}
+)
+
+type mountainCar struct {
+	rng                *rand.Rand
+	actions            []mdp.Action
+	position, velocity float64
+	done               bool
+}
+
+// NewMountainCar creates the mountain car task, where an underpowered car in a valley must rock back and forth to
+// reach the top of the hill on its right. Actions "left", "idle" and "right" push the car, and observations are its
+// position and velocity. Every step pays -1, and the episode ends when the car reaches the goal.
+// `rng` is the source of randomness for the initial state.
+func NewMountainCar(rng *rand.Rand) (env.Environment, error) {
+	if rng == nil {
+		return nil, errRandom
+	}
+	e := &mountainCar{}
+	e.rng = rng
+	e.actions = mdp.NewActions([]string{"left", "idle", "right"})
+	e.done = true
+	return e, nil
+}
+
+func (e *mountainCar) observe() mdp.State {
+	return env.NewObservation([]float64{e.position, e.velocity}, e.done)
+}
+
+func (e *mountainCar) Actions() []mdp.Action {
+	if e.done {
+		return nil
+	}
+	return e.actions
+}
+
+func (e *mountainCar) Reset() (mdp.State, error) {
+	e.position = uniform(e.rng, -0.6, -0.4)
+	e.velocity = 0
+	e.done = false
+	return e.observe(), nil
+}
+
+func (e *mountainCar) Step(action mdp.Action) (mdp.State, float32, bool, error) {
+	if e.done {
+		return nil, 0, true, errDone
+	}
+	a, err := actionPosition(e.actions, action)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	e.velocity += float64(a-1)*mountainCarForce - mountainCarGravity*math.Cos(3*e.position)
+	e.velocity = clip(e.velocity, -mountainCarMaxSpeed, mountainCarMaxSpeed)
+	e.position = clip(e.position+e.velocity, mountainCarMinPosition, mountainCarMaxPosition)
+	// The car stops dead against the left wall
+	if e.position == mountainCarMinPosition && e.velocity < 0 {
+		e.velocity = 0
+	}
+	e.done = e.position >= mountainCarGoal
+	return e.observe(), -1, e.done, nil
+}
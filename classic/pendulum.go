@@ -0,0 +1,89 @@
+package classic
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/anthonykrivonos/go-rl/env"
+	"github.com/anthonykrivonos/go-rl/mdp"
+)
+
+const (
+	pendulumMaxSpeed  = 8.0
+	pendulumMaxTorque = 2.0
+	pendulumTimeStep  = 0.05
+	pendulumGravity   = 10.0
+	pendulumMass      = 1.0
+	pendulumLength    = 1.0
+)
+
+type pendulum struct {
+	rng     *rand.Rand
+	actions []mdp.Action
+	torques []float64
+	// Angle from upright and angular velocity
+	theta, thetaDot float64
+	started         bool
+}
+
+// NewPendulum creates the inverted pendulum swing-up task. The continuous torque range [-2, 2] is discretized into
+// `torques` evenly spaced actions, each named by its torque, and observations are the cosine and sine of the angle from
+// upright and the angular velocity. Each step pays -(θ² + 0.1θ̇² + 0.001u²) for angle θ and torque u, so the best
+// return comes from swinging up quickly and balancing. Episodes never end on their own.
+// `rng` is the source of randomness for the initial state.
+func NewPendulum(torques int, rng *rand.Rand) (env.Environment, error) {
+	if torques < 2 {
+		return nil, errors.New("there must be at least two torques")
+	} else if rng == nil {
+		return nil, errRandom
+	}
+	e := &pendulum{}
+	e.rng = rng
+	e.torques = make([]float64, torques)
+	names := make([]string, torques)
+	for i := range e.torques {
+		e.torques[i] = -pendulumMaxTorque + 2*pendulumMaxTorque*float64(i)/float64(torques-1)
+		names[i] = fmt.Sprint(e.torques[i])
+	}
+	e.actions = mdp.NewActions(names)
+	return e, nil
+}
+
+func (e *pendulum) observe() mdp.State {
+	return env.NewObservation([]float64{math.Cos(e.theta), math.Sin(e.theta), e.thetaDot}, false)
+}
+
+func (e *pendulum) Actions() []mdp.Action {
+	if !e.started {
+		return nil
+	}
+	return e.actions
+}
+
+func (e *pendulum) Reset() (mdp.State, error) {
+	e.theta = uniform(e.rng, -math.Pi, math.Pi)
+	e.thetaDot = uniform(e.rng, -1, 1)
+	e.started = true
+	return e.observe(), nil
+}
+
+func (e *pendulum) Step(action mdp.Action) (mdp.State, float32, bool, error) {
+	if !e.started {
+		return nil, 0, false, errors.New("episode hasn't started; call Reset to start one")
+	}
+	a, err := actionPosition(e.actions, action)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	u := e.torques[a]
+	theta := wrap(e.theta)
+	cost := theta*theta + 0.1*e.thetaDot*e.thetaDot + 0.001*u*u
+
+	g, m, l := pendulumGravity, pendulumMass, pendulumLength
+	e.thetaDot += (3*g/(2*l)*math.Sin(e.theta) + 3/(m*l*l)*u) * pendulumTimeStep
+	e.thetaDot = clip(e.thetaDot, -pendulumMaxSpeed, pendulumMaxSpeed)
+	e.theta += e.thetaDot * pendulumTimeStep
+	return e.observe(), float32(-cost), false, nil
+}
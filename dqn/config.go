@@ -0,0 +1,95 @@
+package dqn
+
+import (
+	"errors"
+	"math/rand"
+
+	"github.com/anthonykrivonos/go-rl/nn"
+	"github.com/anthonykrivonos/go-rl/replay"
+)
+
+// A Schedule gives a value, such as an exploration rate, for each step of training.
+type Schedule func(step int) float32
+
+// NewConstantSchedule creates a Schedule that is always `value`.
+func NewConstantSchedule(value float32) Schedule {
+	return func(int) float32 {
+		return value
+	}
+}
+
+// NewLinearSchedule creates a Schedule that moves linearly from `start` to `end` over the first `steps` steps and then
+// stays at `end`.
+func NewLinearSchedule(start float32, end float32, steps int) Schedule {
+	return func(step int) float32 {
+		if step >= steps {
+			return end
+		}
+		return start + (end-start)*float32(step)/float32(steps)
+	}
+}
+
+// Config holds the settings of a DQN agent.
+type Config struct {
+	// Hidden holds the size of each hidden layer of the Q-network.
+	Hidden []int
+	// Activation is the activation of the hidden layers.
+	Activation nn.Activation
+	// Optimizer trains the Q-network.
+	Optimizer nn.Optimizer
+	// DiscountRate is the discount rate for learning, ɣ (gamma).
+	DiscountRate float32
+	// Epsilon gives the probability of a random action at each step.
+	Epsilon Schedule
+	// Replay stores steps for replay. Prioritized buffers get each step's absolute TD error as its priority.
+	Replay replay.Buffer
+	// BatchSize is the number of replayed steps in each update.
+	BatchSize int
+	// NSteps is the number of rewards in each target before bootstrapping, n.
+	NSteps int
+	// WarmUp is the number of steps taken before the first update.
+	WarmUp int
+	// TargetSync is the number of steps between copies of the Q-network to the target network.
+	TargetSync int
+	// HuberDelta is the difference from the target beyond which the loss grows linearly.
+	HuberDelta float64
+	// MaxGradientNorm clips the norm of each update's gradient, or is 0 to leave gradients unclipped.
+	MaxGradientNorm float64
+	// Double makes targets use the Q-network to choose the next action and the target network to value it.
+	Double bool
+	// Random is the source of randomness for network initialization and exploration.
+	Random *rand.Rand
+}
+
+// validate returns an error describing the first invalid setting, or nil if the configuration is valid.
+func (c Config) validate() error {
+	for _, size := range c.Hidden {
+		if size <= 0 {
+			return errors.New("hidden layer sizes must be positive")
+		}
+	}
+	if c.Optimizer == nil {
+		return errors.New("optimizer must be provided")
+	} else if c.DiscountRate <= 0 || c.DiscountRate > 1.0 {
+		return errors.New("discount rate must be in (0, 1.0]")
+	} else if c.Epsilon == nil {
+		return errors.New("epsilon schedule must be provided")
+	} else if c.Replay == nil {
+		return errors.New("replay buffer must be provided")
+	} else if c.BatchSize <= 0 || c.Replay.Capacity() < c.BatchSize {
+		return errors.New("batch size must be positive and at most the replay buffer's capacity")
+	} else if c.NSteps <= 0 {
+		return errors.New("n steps must be positive")
+	} else if c.WarmUp < c.BatchSize {
+		return errors.New("warm up must be at least the batch size")
+	} else if c.TargetSync <= 0 {
+		return errors.New("target sync must be positive")
+	} else if c.HuberDelta <= 0 {
+		return errors.New("huber delta must be positive")
+	} else if c.MaxGradientNorm < 0 {
+		return errors.New("max gradient norm must be non-negative")
+	} else if c.Random == nil {
+		return errors.New("random source must be provided")
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package dqn
+
+import (
+	"errors"
+	"math"
+
+	"github.com/anthonykrivonos/go-rl/env"
+	"github.com/anthonykrivonos/go-rl/mdp"
+	"github.com/anthonykrivonos/go-rl/nn"
+	"github.com/anthonykrivonos/go-rl/replay"
+	"github.com/anthonykrivonos/go-rl/tabular"
+	"github.com/anthonykrivonos/go-rl/utils"
+)
+
+// A Deep Q-Network agent.
+type Learner interface {
+	tabular.QLearner
+	// Network returns the Q-network, with one output per action.
+	Network() nn.Network
+	// Steps returns the number of steps learned from.
+	Steps() int
+	// Loss returns the mean loss of the most recent update.
+	Loss() float64
+}
+
+type dqn struct {
+	config  Config
+	actions []mdp.Action
+	encoder env.Encoder
+	online  nn.Network
+	target  nn.Network
+	nStep   *replay.NStep
+	steps   int
+	loss    float64
+}
+
+// NewDQN creates a Deep Q-Network agent for `actions`, whose Q-network maps the `inputs` values `encoder` gives each
+// state to a value per action. It acts epsilon-greedily among the available actions, stores every n-step step for
+// replay, and once warmed up, moves the Q-network towards targets G + ɣⁿ max_a' Q_target(s', a') on a replayed batch
+// each step under the Huber loss, where G is the discounted sum of n rewards. Targets maximize over all of `actions`.
+func NewDQN(actions []mdp.Action, encoder env.Encoder, inputs int, config Config) (Learner, error) {
+	if len(actions) == 0 {
+		return nil, errors.New("at least one action must be provided")
+	} else if encoder == nil {
+		return nil, errors.New("encoder must be provided")
+	} else if inputs <= 0 {
+		return nil, errors.New("inputs must be positive")
+	} else if err := config.validate(); err != nil {
+		return nil, err
+	}
+	sizes := append(append([]int{inputs}, config.Hidden...), len(actions))
+	online, err := nn.NewMLP(sizes, config.Activation, config.Random)
+	if err != nil {
+		return nil, err
+	}
+	l := &dqn{}
+	l.config = config
+	l.actions = actions
+	l.encoder = encoder
+	l.online = online
+	l.target = online.Clone()
+	l.nStep, err = replay.NewNStep(config.NSteps, config.DiscountRate)
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// position returns the output of `action`, or -1 if the agent doesn't know it.
+func (l *dqn) position(action mdp.Action) int {
+	for i, a := range l.actions {
+		if a.Name() == action.Name() {
+			return i
+		}
+	}
+	return -1
+}
+
+func (l *dqn) Act(state mdp.State, actions []mdp.Action) mdp.Action {
+	if l.config.Random.Float32() < l.config.Epsilon(l.steps) {
+		return actions[l.config.Random.Intn(len(actions))]
+	}
+	values := l.online.Forward(l.encoder(state))
+	var known []mdp.Action
+	var knownValues []float64
+	for _, action := range actions {
+		if i := l.position(action); i >= 0 {
+			known = append(known, action)
+			knownValues = append(knownValues, values[i])
+		}
+	}
+	if len(known) == 0 {
+		return actions[l.config.Random.Intn(len(actions))]
+	}
+	return known[utils.Argmax(knownValues, l.config.Random)]
+}
+
+func (l *dqn) Learn(step env.Step, _ []mdp.Action) {
+	if l.position(step.Action) < 0 {
+		return
+	}
+	for _, completed := range l.nStep.Add(step) {
+		l.config.Replay.Add(completed)
+	}
+	l.steps++
+	if l.steps >= l.config.WarmUp && l.config.Replay.Len() > 0 {
+		l.update()
+	}
+	if l.steps%l.config.TargetSync == 0 {
+		// The target is a clone of the online network, so their sizes match and copying can't fail
+		_ = l.target.CopyFrom(l.online)
+	}
+}
+
+func (l *dqn) EndEpisode() {
+	l.nStep.Reset()
+}
+
+func (l *dqn) Q(state mdp.State, action mdp.Action) float32 {
+	if i := l.position(action); i >= 0 {
+		return float32(l.online.Forward(l.encoder(state))[i])
+	}
+	return 0
+}
+
+func (l *dqn) Network() nn.Network {
+	return l.online
+}
+
+func (l *dqn) Steps() int {
+	return l.steps
+}
+
+func (l *dqn) Loss() float64 {
+	return l.loss
+}
+
+// update takes one gradient step on a replayed batch, weighting each step's loss by its importance sampling weight.
+func (l *dqn) update() {
+	batch, err := l.config.Replay.Sample(l.config.BatchSize)
+	if err != nil {
+		return
+	}
+	discount := math.Pow(float64(l.config.DiscountRate), float64(l.config.NSteps))
+	scale := 1 / float64(len(batch.Steps))
+	tdErrors := make([]float64, len(batch.Steps))
+	l.loss = 0
+	for i, step := range batch.Steps {
+		target := float64(step.Reward)
+		if !step.Done {
+			target += discount * l.nextValue(l.encoder(step.NextState))
+		}
+		input := l.encoder(step.State)
+		values := l.online.Forward(input)
+		a := l.position(step.Action)
+		tdErrors[i] = target - values[a]
+		loss, gradient := nn.Huber(values[a:a+1], []float64{target}, l.config.HuberDelta)
+		l.loss += loss * batch.Weights[i] * scale
+		outputGradient := make([]float64, len(values))
+		outputGradient[a] = gradient[0] * batch.Weights[i] * scale
+		l.online.Backward(input, outputGradient)
+	}
+	l.config.Replay.UpdatePriorities(batch.Positions, tdErrors)
+	if l.config.MaxGradientNorm > 0 {
+		l.online.ClipGradient(l.config.MaxGradientNorm)
+	}
+	l.online.Update(l.config.Optimizer)
+}
+
+// nextValue returns the target network's value of the best action in the state encoded as `next`, where the best action
+// is chosen by the target network itself, or by the Q-network for Double DQN.
+func (l *dqn) nextValue(next []float64) float64 {
+	targetValues := l.target.Forward(next)
+	chooser := targetValues
+	if l.config.Double {
+		chooser = l.online.Forward(next)
+	}
+	best := 0
+	for i := range chooser {
+		if chooser[i] > chooser[best] {
+			best = i
+		}
+	}
+	return targetValues[best]
+}
@@ -0,0 +1,162 @@
+package dqn
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/anthonykrivonos/go-rl/classic"
+	"github.com/anthonykrivonos/go-rl/env"
+	"github.com/anthonykrivonos/go-rl/mdp"
+	"github.com/anthonykrivonos/go-rl/nn"
+	"github.com/anthonykrivonos/go-rl/replay"
+	"github.com/anthonykrivonos/go-rl/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// greedy follows the learner's greedy policy without learning.
+type greedy struct {
+	learner Learner
+}
+
+func (g *greedy) Act(state mdp.State, actions []mdp.Action) mdp.Action {
+	best := actions[0]
+	for _, action := range actions {
+		if g.learner.Q(state, action) > g.learner.Q(state, best) {
+			best = action
+		}
+	}
+	return best
+}
+
+func (g *greedy) Learn(env.Step, []mdp.Action) {}
+
+func (g *greedy) EndEpisode() {}
+
+func newConfig(t *testing.T, double bool, seed int64) Config {
+	optimizer, err := nn.NewAdam(5e-4)
+	assert.NoError(t, err)
+	buffer, err := replay.NewUniform(50000, rand.New(rand.NewSource(seed)))
+	assert.NoError(t, err)
+	return Config{
+		Hidden:          []int{32, 32},
+		Activation:      nn.ReLU,
+		Optimizer:       optimizer,
+		DiscountRate:    0.99,
+		Epsilon:         NewLinearSchedule(1, 0.05, 5000),
+		Replay:          buffer,
+		BatchSize:       32,
+		NSteps:          1,
+		WarmUp:          500,
+		TargetSync:      250,
+		HuberDelta:      1,
+		MaxGradientNorm: 10,
+		Double:          double,
+		Random:          rand.New(rand.NewSource(seed)),
+	}
+}
+
+func TestSchedules(t *testing.T) {
+	s := NewLinearSchedule(1, 0.1, 10)
+	assert.Equal(t, float32(1), s(0))
+	assert.InDelta(t, 0.55, s(5), 1e-6)
+	assert.Equal(t, float32(0.1), s(10))
+	assert.Equal(t, float32(0.1), s(100))
+	assert.Equal(t, float32(0.3), NewConstantSchedule(0.3)(7))
+}
+
+func TestConfigValidation(t *testing.T) {
+	actions := []mdp.Action{mdp.NewAction("left"), mdp.NewAction("right")}
+	_, err := NewDQN(actions, env.EncodeVector, 4, newConfig(t, false, 1))
+	assert.NoError(t, err)
+	_, err = NewDQN(nil, env.EncodeVector, 4, newConfig(t, false, 1))
+	assert.Error(t, err)
+	_, err = NewDQN(actions, nil, 4, newConfig(t, false, 1))
+	assert.Error(t, err)
+
+	for _, change := range []func(c *Config){
+		func(c *Config) { c.Optimizer = nil },
+		func(c *Config) { c.DiscountRate = 0 },
+		func(c *Config) { c.Epsilon = nil },
+		func(c *Config) { c.Replay = nil },
+		func(c *Config) { c.BatchSize = 100000 },
+		func(c *Config) { c.NSteps = 0 },
+		func(c *Config) { c.WarmUp = 1 },
+		func(c *Config) { c.TargetSync = 0 },
+		func(c *Config) { c.HuberDelta = 0 },
+		func(c *Config) { c.Hidden = []int{0} },
+		func(c *Config) { c.Random = nil },
+	} {
+		config := newConfig(t, false, 1)
+		change(&config)
+		_, err = NewDQN(actions, env.EncodeVector, 4, config)
+		assert.Error(t, err)
+	}
+}
+
+func TestDQNBalancesCartPole(t *testing.T) {
+	cases := map[string]func(c *Config){
+		"DQN":        func(c *Config) {},
+		"Double DQN": func(c *Config) { c.Double = true },
+		"prioritized 3-step Double DQN": func(c *Config) {
+			buffer, err := replay.NewPrioritized(50000, 0.6, 0.4, rand.New(rand.NewSource(1)))
+			assert.NoError(t, err)
+			c.Replay = buffer
+			c.NSteps = 3
+			c.Double = true
+		},
+	}
+	for name, change := range cases {
+		e, err := classic.NewCartPole(rand.New(rand.NewSource(1)))
+		assert.NoError(t, err)
+		evaluation, err := classic.NewCartPole(rand.New(rand.NewSource(101)))
+		assert.NoError(t, err)
+		actions := []mdp.Action{mdp.NewAction("left"), mdp.NewAction("right")}
+		config := newConfig(t, false, 1)
+		change(&config)
+		learner, err := NewDQN(actions, env.EncodeVector, 4, config)
+		assert.NoError(t, err)
+
+		// Train in rounds of 10 episodes until the greedy policy balances the pole for 195 steps on average
+		solved := false
+		for round := 0; round < 30 && !solved; round++ {
+			_, err = env.Train(e, learner, 10, 500)
+			assert.NoError(t, err)
+			returns, err := env.Train(evaluation, &greedy{learner}, 5, 500)
+			assert.NoError(t, err)
+			total := float32(0)
+			for _, r := range returns {
+				total += r
+			}
+			solved = total/5 >= 195
+		}
+		assert.True(t, solved, name)
+		assert.Greater(t, learner.Loss(), 0.0, name)
+	}
+}
+
+func TestDQNIsReproducible(t *testing.T) {
+	actions := []mdp.Action{mdp.NewAction("left"), mdp.NewAction("right")}
+	// Trains on CartPole with every source of randomness derived from a run-level seed
+	run := func(seed int64) ([]float32, []float64) {
+		seeds := utils.NewSeeds(seed)
+		e, err := classic.NewCartPole(seeds.Random("env"))
+		assert.NoError(t, err)
+		buffer, err := replay.NewPrioritized(1000, 0.6, 0.4, seeds.Random("replay"))
+		assert.NoError(t, err)
+		config := newConfig(t, true, 0)
+		config.Replay = buffer
+		config.WarmUp = 100
+		config.Random = seeds.Random("agent")
+		learner, err := NewDQN(actions, env.EncodeVector, 4, config)
+		assert.NoError(t, err)
+		returns, err := env.Train(e, learner, 20, 500)
+		assert.NoError(t, err)
+		return returns, learner.Network().Parameters()
+	}
+	returns, parameters := run(1)
+	otherReturns, otherParameters := run(1)
+	assert.Equal(t, returns, otherReturns)
+	assert.Equal(t, parameters, otherParameters)
+	differentReturns, _ := run(2)
+	assert.NotEqual(t, returns, differentReturns)
+}
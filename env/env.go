@@ -0,0 +1,123 @@
+package env
+
+import (
+	"errors"
+	"math/rand"
+
+	"github.com/anthonykrivonos/go-rl/mdp"
+	"github.com/anthonykrivonos/go-rl/utils"
+)
+
+// An episodic environment that an agent interacts with one action at a time.
+type Environment interface {
+	// Actions returns the actions available in the current state.
+	Actions() []mdp.Action
+	// Reset starts a new episode and returns its first state.
+	Reset() (mdp.State, error)
+	// Step takes an action in the current state and returns the next state, the reward received on arriving there, and
+	// whether the episode is over.
+	Step(action mdp.Action) (mdp.State, float32, bool, error)
+}
+
+type mdpEnvironment struct {
+	m       mdp.MDP
+	rng     *rand.Rand
+	start   mdp.Transition
+	current mdp.State
+	done    bool
+}
+
+// NewMDPEnvironment creates an Environment that simulates an MDP. Episodes start in the MDP's initial state and end on
+// reaching a terminal state. Taking an action moves to its transition's next state with the transition's probability
+// and otherwise stays put, and the reward for each step is the reward of the state arrived in.
+// `rng` is the source of randomness for sampling transitions.
+func NewMDPEnvironment(m mdp.MDP, rng *rand.Rand) (Environment, error) {
+	if m == nil {
+		return nil, errors.New("mdp must be provided")
+	} else if rng == nil {
+		return nil, errors.New("random source must be provided")
+	}
+	e := &mdpEnvironment{}
+	e.m = m
+	e.rng = rng
+	e.done = true
+	return e, nil
+}
+
+// NewMDPEnvironmentWithStart creates an Environment like NewMDPEnvironment, except that episodes start in a state drawn
+// from the outcomes of `start` out of the MDP's initial state. Use mdp.NewDistribution to start from several states.
+func NewMDPEnvironmentWithStart(m mdp.MDP, start mdp.Transition, rng *rand.Rand) (Environment, error) {
+	if start == nil {
+		return nil, errors.New("start must be provided")
+	} else if err := mdp.ValidateTransition(start); err != nil {
+		return nil, err
+	}
+	e, err := NewMDPEnvironment(m, rng)
+	if err != nil {
+		return nil, err
+	}
+	e.(*mdpEnvironment).start = start
+	return e, nil
+}
+
+// Actions returns the actions available in the current state, or none if the episode is over.
+func (e *mdpEnvironment) Actions() []mdp.Action {
+	if e.done {
+		return nil
+	}
+	return e.m.AvailableActions(e.current)
+}
+
+// Reset moves back to the MDP's initial state, or to a state drawn from the start transition if there is one.
+func (e *mdpEnvironment) Reset() (mdp.State, error) {
+	initial := e.m.InitialState()
+	if initial == nil {
+		return nil, errors.New("mdp has no initial state")
+	}
+	if e.start != nil {
+		initial = e.m.StateByIndex(e.sample(mdp.Outcomes(initial, e.start)).Index())
+		if initial == nil {
+			return nil, errors.New("start leads to a state not in the MDP")
+		}
+	}
+	e.current = initial
+	e.done = initial.Terminal() || len(e.m.AvailableActions(initial)) == 0
+	return e.current, nil
+}
+
+// Step samples the next state of taking `action` in the current state.
+func (e *mdpEnvironment) Step(action mdp.Action) (mdp.State, float32, bool, error) {
+	if e.done {
+		return nil, 0, true, errors.New("episode is over; call Reset to start a new one")
+	}
+	var transition mdp.Transition
+	for _, a := range e.m.AvailableActions(e.current) {
+		if a.Equals(action) {
+			transition = e.m.TByIndex(e.current.Index(), a.Name())
+		}
+	}
+	if transition == nil {
+		return nil, 0, false, errors.New("action " + action.String() + " isn't available in " + e.current.String())
+	}
+
+	state := e.m.StateByIndex(e.sample(mdp.Outcomes(e.current, transition)).Index())
+	if state == nil {
+		return nil, 0, false, errors.New("transition from " + e.current.String() + " via " + action.String() + " leads to a state not in the MDP")
+	}
+
+	e.current = state
+	e.done = state.Terminal() || len(e.m.AvailableActions(state)) == 0
+	return state, e.m.RByIndex(state.Index()), e.done, nil
+}
+
+// sample returns the next state of one of `outcomes`, drawn by their probabilities.
+func (e *mdpEnvironment) sample(outcomes []mdp.Transition) mdp.State {
+	probabilities := make([]float64, len(outcomes))
+	for i, outcome := range outcomes {
+		probabilities[i] = float64(outcome.Probability())
+	}
+	if i := utils.SampleCategorical(probabilities, e.rng); i >= 0 {
+		return outcomes[i].NextState()
+	}
+	return outcomes[len(outcomes)-1].NextState()
+}
@@ -0,0 +1,281 @@
+package env
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/anthonykrivonos/go-rl/mdp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMDPEnvironment(t *testing.T) {
+	m, err := mdp.NewDefaultMDP()
+	assert.NoError(t, err)
+
+	move := mdp.NewAction("move")
+	start := mdp.NewState("start", 0, false)
+	goal := mdp.NewState("goal", 1, true)
+	assert.NoError(t, m.AddStateObject(start, -1, map[mdp.Action]mdp.Transition{move: mdp.NewTransition(0.25, goal)}))
+	assert.NoError(t, m.AddStateObject(goal, 10, nil))
+
+	_, err = NewMDPEnvironment(m, nil)
+	assert.Error(t, err)
+	e, err := NewMDPEnvironment(m, rand.New(rand.NewSource(1)))
+	assert.NoError(t, err)
+
+	_, _, _, err = e.Step(move)
+	assert.Error(t, err)
+
+	// Moving succeeds a quarter of the time and otherwise stays put
+	steps := 0
+	for episode := 0; episode < 1000; episode++ {
+		state, err := e.Reset()
+		assert.NoError(t, err)
+		assert.Equal(t, "start", state.Name())
+		assert.Equal(t, []mdp.Action{move}, e.Actions())
+		for done := false; !done; steps++ {
+			var reward float32
+			state, reward, done, err = e.Step(move)
+			assert.NoError(t, err)
+			if done {
+				assert.Equal(t, "goal", state.Name())
+				assert.Equal(t, float32(10), reward)
+			} else {
+				assert.Equal(t, "start", state.Name())
+				assert.Equal(t, float32(-1), reward)
+			}
+		}
+		assert.Empty(t, e.Actions())
+	}
+	assert.InDelta(t, 4, float64(steps)/1000, 0.3)
+
+	_, err = e.Reset()
+	assert.NoError(t, err)
+	_, _, _, err = e.Step(mdp.NewAction("jump"))
+	assert.Error(t, err)
+}
+
+func TestObservation(t *testing.T) {
+	o := NewObservation([]float64{0.5, -1}, false)
+	assert.Equal(t, -1, o.Index())
+	assert.Equal(t, []float64{0.5, -1}, o.Vector())
+	assert.Equal(t, "(O: [0.5 -1])", o.String())
+	assert.True(t, o.Equals(NewObservation([]float64{0.5, -1}, true)))
+	assert.False(t, o.Equals(NewObservation([]float64{0.5, 1}, false)))
+	assert.False(t, o.Equals(mdp.NewState("S0", -1, false)))
+}
+
+func TestMDPEnvironmentWithStart(t *testing.T) {
+	m, err := mdp.NewDefaultMDP()
+	assert.NoError(t, err)
+	move := mdp.NewAction("move")
+	states := []mdp.State{mdp.NewState("A", 0, false), mdp.NewState("B", 1, false), mdp.NewState("C", 2, true)}
+	assert.NoError(t, m.AddStateObject(states[0], 0, map[mdp.Action]mdp.Transition{move: mdp.NewTransition(1, states[2])}))
+	assert.NoError(t, m.AddStateObject(states[1], 0, map[mdp.Action]mdp.Transition{move: mdp.NewTransition(1, states[2])}))
+	assert.NoError(t, m.AddStateObject(states[2], 1, nil))
+
+	_, err = NewMDPEnvironmentWithStart(m, nil, rand.New(rand.NewSource(1)))
+	assert.Error(t, err)
+	invalid := mdp.NewDistribution(mdp.NewTransition(0.75, states[0]), mdp.NewTransition(0.75, states[1]))
+	_, err = NewMDPEnvironmentWithStart(m, invalid, rand.New(rand.NewSource(1)))
+	assert.Error(t, err)
+	start := mdp.NewDistribution(mdp.NewTransition(0.25, states[0]), mdp.NewTransition(0.75, states[1]))
+	e, err := NewMDPEnvironmentWithStart(m, start, rand.New(rand.NewSource(1)))
+	assert.NoError(t, err)
+	counts := make(map[string]int)
+	for episode := 0; episode < 4000; episode++ {
+		state, err := e.Reset()
+		assert.NoError(t, err)
+		counts[state.Name()]++
+	}
+	assert.InDelta(t, 3000, counts["B"], 100)
+	assert.Equal(t, 4000, counts["A"]+counts["B"])
+}
+
+func TestEncoders(t *testing.T) {
+	assert.Equal(t, []float64{1, 2}, EncodeVector(NewObservation([]float64{1, 2}, false)))
+	assert.Equal(t, []float64{3}, EncodeVector(mdp.NewState("S3", 3, false)))
+	encode := NewOneHotEncoder(3)
+	assert.Equal(t, []float64{0, 0, 1}, encode(mdp.NewState("S2", 2, false)))
+	assert.Equal(t, []float64{0, 0, 0}, encode(mdp.NewState("S7", 7, false)))
+}
+
+// newCorridor creates an MDP whose only action moves right along `length` states, paying 1 per move, until it reaches
+// the terminal last state.
+func newCorridor(t *testing.T, length int) mdp.MDP {
+	m, err := mdp.NewDefaultMDP()
+	assert.NoError(t, err)
+	right := mdp.NewAction("right")
+	states := make([]mdp.State, length)
+	for i := range states {
+		states[i] = mdp.NewState(fmt.Sprint(i), i, i == length-1)
+	}
+	for i, state := range states {
+		var transitions map[mdp.Action]mdp.Transition
+		if i < length-1 {
+			transitions = map[mdp.Action]mdp.Transition{right: mdp.NewTransition(1, states[i+1])}
+		}
+		assert.NoError(t, m.AddStateObject(state, 1, transitions))
+	}
+	return m
+}
+
+// newCorridors creates a simulator of a corridor of each of `lengths`.
+func newCorridors(t *testing.T, lengths ...int) []Environment {
+	var envs []Environment
+	for _, length := range lengths {
+		e, err := NewMDPEnvironment(newCorridor(t, length), rand.New(rand.NewSource(1)))
+		assert.NoError(t, err)
+		envs = append(envs, e)
+	}
+	return envs
+}
+
+func TestVecEnv(t *testing.T) {
+	for name, newVecEnv := range map[string]func(envs []Environment, maxSteps int) (VecEnv, error){
+		"lockstep": NewVecEnv,
+		"async": func(envs []Environment, maxSteps int) (VecEnv, error) {
+			return NewAsyncVecEnv(envs, maxSteps)
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			testVecEnv(t, newVecEnv)
+		})
+	}
+}
+
+func testVecEnv(t *testing.T, newVecEnv func(envs []Environment, maxSteps int) (VecEnv, error)) {
+	newEnvs := func(lengths ...int) []Environment {
+		return newCorridors(t, lengths...)
+	}
+	_, err := newVecEnv(nil, 10)
+	assert.Error(t, err)
+	_, err = newVecEnv(newEnvs(3), 0)
+	assert.Error(t, err)
+	shared := newEnvs(3)[0]
+	_, err = newVecEnv([]Environment{shared, shared}, 10)
+	assert.Error(t, err)
+
+	v, err := newVecEnv(newEnvs(3, 4), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, v.Len())
+	right := mdp.NewAction("right")
+	_, err = v.Step([]mdp.Action{right, right})
+	assert.Error(t, err)
+
+	states, err := v.Reset()
+	assert.NoError(t, err)
+	assert.Equal(t, "0", states[0].Name())
+	assert.Equal(t, [][]mdp.Action{{right}, {right}}, v.Actions())
+	_, err = v.Step([]mdp.Action{right})
+	assert.Error(t, err)
+
+	// The shorter corridor ends after two steps and is reset while the longer one carries on
+	s, err := v.Step([]mdp.Action{right, right})
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{false, false}, s.Dones)
+	s, err = v.Step([]mdp.Action{right, right})
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{true, false}, s.Dones)
+	assert.Equal(t, []bool{false, false}, s.Truncated)
+	assert.Equal(t, "2", s.Finals[0].Name())
+	assert.Equal(t, "0", s.States[0].Name())
+	assert.Equal(t, "2", s.States[1].Name())
+	assert.Equal(t, []float32{2, 0}, s.Returns)
+	assert.Equal(t, []float32{1, 1}, s.Rewards)
+
+	// Episodes are truncated at the step limit
+	v, err = newVecEnv(newEnvs(5), 2)
+	assert.NoError(t, err)
+	_, err = v.Reset()
+	assert.NoError(t, err)
+	_, err = v.Step([]mdp.Action{right})
+	assert.NoError(t, err)
+	s, err = v.Step([]mdp.Action{right})
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{false}, s.Dones)
+	assert.Equal(t, []bool{true}, s.Truncated)
+	assert.Equal(t, "2", s.Finals[0].Name())
+	assert.Equal(t, "0", s.States[0].Name())
+	assert.Equal(t, []float32{2}, s.Returns)
+
+	// Errors from any environment are returned
+	_, err = v.Step([]mdp.Action{mdp.NewAction("left")})
+	assert.Error(t, err)
+
+	// The environments can carry on after an error
+	_, err = v.Step([]mdp.Action{right})
+	assert.NoError(t, err)
+}
+
+func TestAsyncVecEnv(t *testing.T) {
+	v, err := NewAsyncVecEnv(newCorridors(t, 3, 3), 10)
+	assert.NoError(t, err)
+	defer v.Close()
+	right := mdp.NewAction("right")
+	assert.Error(t, v.Send(0, right))
+	_, err = v.Reset()
+	assert.NoError(t, err)
+
+	// Environments step independently, so one can finish an episode while the other waits
+	_, err = v.Receive()
+	assert.Error(t, err)
+	assert.NoError(t, v.Send(0, right))
+	assert.Error(t, v.Send(0, right))
+	assert.Error(t, v.Send(2, right))
+	assert.Equal(t, 1, v.Pending())
+	_, err = v.Step([]mdp.Action{right, right})
+	assert.Error(t, err)
+	s, err := v.Receive()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, s.Env)
+	assert.Equal(t, "1", s.State.Name())
+	assert.NoError(t, v.Send(0, right))
+	s, err = v.Receive()
+	assert.NoError(t, err)
+	assert.True(t, s.Done)
+	assert.Equal(t, "2", s.Final.Name())
+	assert.Equal(t, "0", s.State.Name())
+	assert.Equal(t, []mdp.Action{right}, s.Actions)
+	assert.Equal(t, float32(2), s.Return)
+	assert.Equal(t, 0, v.Pending())
+
+	// Both environments can be stepped together again
+	vs, err := v.Step([]mdp.Action{right, right})
+	assert.NoError(t, err)
+	assert.Equal(t, "1", vs.States[0].Name())
+	assert.Equal(t, "1", vs.States[1].Name())
+
+	// Failed steps are received as errors
+	assert.NoError(t, v.Send(1, mdp.NewAction("left")))
+	_, err = v.Receive()
+	assert.Error(t, err)
+	assert.Equal(t, 0, v.Pending())
+
+	v.Close()
+	v.Close()
+	_, err = v.Reset()
+	assert.Error(t, err)
+	assert.Error(t, v.Send(0, right))
+}
+
+func TestMDPVecEnv(t *testing.T) {
+	m := newCorridor(t, 3)
+	_, err := NewMDPVecEnv(m, 0, 10, rand.New(rand.NewSource(1)))
+	assert.Error(t, err)
+	_, err = NewMDPVecEnv(m, 2, 10, nil)
+	assert.Error(t, err)
+
+	v, err := NewMDPVecEnv(m, 3, 10, rand.New(rand.NewSource(1)))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, v.Len())
+	states, err := v.Reset()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]float64{{0}, {0}, {0}}, EncodeAll(EncodeVector, states))
+
+	a, err := NewAsyncMDPVecEnv(m, 3, 10, rand.New(rand.NewSource(1)))
+	assert.NoError(t, err)
+	defer a.Close()
+	assert.Equal(t, 3, a.Len())
+}
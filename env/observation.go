@@ -0,0 +1,93 @@
+package env
+
+import (
+	"fmt"
+
+	"github.com/anthonykrivonos/go-rl/mdp"
+)
+
+// An Observation is a state given by a vector of real numbers, as seen in environments with continuous states.
+// Observations don't belong to an MDP, so their index is -1 and two observations are equal if their vectors are.
+type Observation interface {
+	mdp.State
+	// Vector returns the observation's values.
+	Vector() []float64
+}
+
+type observation struct {
+	vector   []float64
+	terminal bool
+}
+
+// NewObservation creates an observation of the values in `vector`, which is terminal if `terminal` is true.
+func NewObservation(vector []float64, terminal bool) Observation {
+	o := &observation{}
+	o.vector = vector
+	o.terminal = terminal
+	return o
+}
+
+func (o *observation) Name() string {
+	return fmt.Sprint(o.vector)
+}
+
+func (o *observation) Index() int {
+	return -1
+}
+
+func (o *observation) Terminal() bool {
+	return o.terminal
+}
+
+func (o *observation) Equals(other mdp.State) bool {
+	v, ok := other.(Observation)
+	if !ok || len(v.Vector()) != len(o.vector) {
+		return false
+	}
+	for i, value := range v.Vector() {
+		if value != o.vector[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (o *observation) String() string {
+	return "(O: " + o.Name() + ")"
+}
+
+func (o *observation) Vector() []float64 {
+	return o.vector
+}
+
+// An Encoder turns states into the input vectors of function approximators such as neural networks.
+type Encoder func(state mdp.State) []float64
+
+// EncodeVector encodes observations as their vector, and any other state as a vector holding only its index.
+func EncodeVector(state mdp.State) []float64 {
+	if o, ok := state.(Observation); ok {
+		return o.Vector()
+	}
+	return []float64{float64(state.Index())}
+}
+
+// NewOneHotEncoder creates an Encoder for `size` states that sets only the element at the state's index. States with an
+// index outside [0, size) encode as all zeros.
+func NewOneHotEncoder(size int) Encoder {
+	return func(state mdp.State) []float64 {
+		vector := make([]float64, size)
+		if index := state.Index(); index >= 0 && index < size {
+			vector[index] = 1
+		}
+		return vector
+	}
+}
+
+// EncodeAll encodes each of `states`, such as the states of a VecEnv, into a batch of input vectors.
+func EncodeAll(encoder Encoder, states []mdp.State) [][]float64 {
+	batch := make([][]float64, len(states))
+	for i, state := range states {
+		batch[i] = encoder(state)
+	}
+	return batch
+}
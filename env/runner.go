@@ -0,0 +1,93 @@
+package env
+
+import (
+	"errors"
+
+	"github.com/anthonykrivonos/go-rl/mdp"
+)
+
+// Step is one step of experience: taking an action in a state, receiving a reward, and arriving in the next state.
+type Step struct {
+	State     mdp.State
+	Action    mdp.Action
+	Reward    float32
+	NextState mdp.State
+	Done      bool
+}
+
+// Trajectory is the sequence of steps taken in an episode.
+type Trajectory []Step
+
+// Return returns the discounted sum of the trajectory's rewards.
+func (t Trajectory) Return(discountRate float32) float32 {
+	total := float32(0)
+	discount := float32(1)
+	for _, step := range t {
+		total += discount * step.Reward
+		discount *= discountRate
+	}
+	return total
+}
+
+// Agent is a learner that interacts with an Environment.
+type Agent interface {
+	// Act chooses an action to take in `state` from the available `actions`.
+	Act(state mdp.State, actions []mdp.Action) mdp.Action
+	// Learn updates the agent from a step of experience. `nextActions` are the actions available in the next state,
+	// which are empty if the episode is over.
+	Learn(step Step, nextActions []mdp.Action)
+	// EndEpisode tells the agent that the current episode is over, whether or not it reached a terminal state.
+	EndEpisode()
+}
+
+// RunEpisode runs one episode of `agent` in `e`, letting the agent learn from every step. The episode ends when the
+// environment is done, no actions are available, or after `maxSteps` steps.
+// Returns the episode's trajectory and a nil error on success, or returns nil and a non-nil error on failure.
+func RunEpisode(e Environment, agent Agent, maxSteps int) (Trajectory, error) {
+	if maxSteps <= 0 {
+		return nil, errors.New("max steps must be positive")
+	}
+	state, err := e.Reset()
+	if err != nil {
+		return nil, err
+	}
+
+	var trajectory Trajectory
+	actions := e.Actions()
+	for t := 0; t < maxSteps && len(actions) > 0; t++ {
+		action := agent.Act(state, actions)
+		next, reward, done, err := e.Step(action)
+		if err != nil {
+			return nil, err
+		}
+		actions = e.Actions()
+		if done {
+			actions = nil
+		}
+		step := Step{state, action, reward, next, done}
+		agent.Learn(step, actions)
+		trajectory = append(trajectory, step)
+		if done {
+			break
+		}
+		state = next
+	}
+	agent.EndEpisode()
+
+	return trajectory, nil
+}
+
+// Train runs `episodes` episodes of `agent` in `e`, each at most `maxSteps` steps long.
+// Returns the undiscounted return of each episode and a nil error on success, or returns nil and a non-nil error on
+// failure.
+func Train(e Environment, agent Agent, episodes, maxSteps int) ([]float32, error) {
+	returns := make([]float32, episodes)
+	for episode := range returns {
+		trajectory, err := RunEpisode(e, agent, maxSteps)
+		if err != nil {
+			return nil, err
+		}
+		returns[episode] = trajectory.Return(1)
+	}
+	return returns, nil
+}
@@ -0,0 +1,477 @@
+package env
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+
+	"github.com/anthonykrivonos/go-rl/mdp"
+)
+
+// VecStep is the result of stepping every environment of a VecEnv once. Its slices hold one entry per environment.
+type VecStep struct {
+	// States holds each environment's current state: the state arrived in, or the first state of a new episode if the
+	// previous one just ended.
+	States []mdp.State
+	// Rewards holds the reward each environment paid for the step.
+	Rewards []float32
+	// Dones reports which environments' episodes ended in a terminal state.
+	Dones []bool
+	// Truncated reports which environments' episodes were cut off at the step limit without ending.
+	Truncated []bool
+	// Finals holds the state each environment arrived in, before any reset.
+	Finals []mdp.State
+	// Returns holds the undiscounted return of each episode that just ended or was truncated, and 0 for the others.
+	Returns []float32
+}
+
+// EnvStep is the result of stepping one environment of an AsyncVecEnv.
+type EnvStep struct {
+	// Env is the position of the environment.
+	Env int
+	// State is the environment's current state: the state arrived in, or the first state of a new episode if the
+	// previous one just ended.
+	State mdp.State
+	// Actions holds the actions available in State.
+	Actions []mdp.Action
+	// Reward is the reward paid for the step.
+	Reward float32
+	// Done reports whether the episode ended in a terminal state.
+	Done bool
+	// Truncated reports whether the episode was cut off at the step limit without ending.
+	Truncated bool
+	// Final is the state arrived in, before any reset.
+	Final mdp.State
+	// Return is the undiscounted return of the episode if it just ended or was truncated, and 0 otherwise.
+	Return float32
+}
+
+// A VecEnv runs several independent environments side by side, stepping all of them at once. Environments whose
+// episodes end are reset automatically, so every environment always has a current state.
+type VecEnv interface {
+	// Len returns the number of environments.
+	Len() int
+	// Actions returns the actions available in each environment's current state.
+	Actions() [][]mdp.Action
+	// Reset starts a new episode in every environment and returns their first states.
+	Reset() ([]mdp.State, error)
+	// Step takes `actions[i]` in the i-th environment, for every environment.
+	Step(actions []mdp.Action) (*VecStep, error)
+}
+
+// An AsyncVecEnv is a VecEnv whose environments also step independently, each in its own goroutine, so that fast
+// environments needn't wait for slow ones. Its methods must be called from a single goroutine.
+type AsyncVecEnv interface {
+	VecEnv
+	// Send starts taking `action` in the i-th environment, which must not be stepping already, without waiting for it
+	// to finish.
+	Send(i int, action mdp.Action) error
+	// Receive waits for any environment that was sent an action to finish its step and returns the result.
+	Receive() (*EnvStep, error)
+	// Pending returns the number of environments that were sent an action and haven't been received from.
+	Pending() int
+	// Close stops the environments' goroutines, after which the AsyncVecEnv can't be used.
+	Close()
+}
+
+// worker runs one environment of a VecEnv, counting the steps and return of its current episode.
+type worker struct {
+	e        Environment
+	maxSteps int
+	steps    int
+	total    float32
+}
+
+// reset starts a new episode in the worker's environment.
+// Returns the first state and its actions, and a nil error on success, or returns nil and a non-nil error on failure.
+func (w *worker) reset() (mdp.State, []mdp.Action, error) {
+	state, err := w.e.Reset()
+	if err != nil {
+		return nil, nil, err
+	}
+	w.steps = 0
+	w.total = 0
+	actions := w.e.Actions()
+	if len(actions) == 0 {
+		return nil, nil, errors.New("environment has no actions available after a reset")
+	}
+	return state, actions, nil
+}
+
+// step takes `action`, starting a new episode if the current one ends or reaches the step limit.
+func (w *worker) step(action mdp.Action) (*EnvStep, error) {
+	next, reward, done, err := w.e.Step(action)
+	if err != nil {
+		return nil, err
+	}
+	w.steps++
+	w.total += reward
+	s := &EnvStep{}
+	s.State = next
+	s.Actions = w.e.Actions()
+	s.Reward = reward
+	s.Done = done || len(s.Actions) == 0
+	s.Truncated = !s.Done && w.steps >= w.maxSteps
+	s.Final = next
+	if s.Done || s.Truncated {
+		s.Return = w.total
+		s.State, s.Actions, err = w.reset()
+	}
+	return s, err
+}
+
+// newWorkers creates a worker for each of `envs`, checking that they are distinct.
+func newWorkers(envs []Environment, maxSteps int) ([]*worker, error) {
+	if len(envs) == 0 {
+		return nil, errors.New("at least one environment must be provided")
+	} else if maxSteps <= 0 {
+		return nil, errors.New("max steps must be positive")
+	}
+	workers := make([]*worker, len(envs))
+	for i, e := range envs {
+		if e == nil {
+			return nil, errors.New("environments must not be nil")
+		}
+		for _, other := range envs[:i] {
+			if other == e {
+				return nil, errors.New("environments must be distinct")
+			}
+		}
+		workers[i] = &worker{e: e, maxSteps: maxSteps}
+	}
+	return workers, nil
+}
+
+// newVecStep gathers the steps of every environment, given in any order, into a VecStep.
+func newVecStep(steps []*EnvStep) *VecStep {
+	n := len(steps)
+	v := &VecStep{}
+	v.States = make([]mdp.State, n)
+	v.Rewards = make([]float32, n)
+	v.Dones = make([]bool, n)
+	v.Truncated = make([]bool, n)
+	v.Finals = make([]mdp.State, n)
+	v.Returns = make([]float32, n)
+	for _, s := range steps {
+		v.States[s.Env] = s.State
+		v.Rewards[s.Env] = s.Reward
+		v.Dones[s.Env] = s.Done
+		v.Truncated[s.Env] = s.Truncated
+		v.Finals[s.Env] = s.Final
+		v.Returns[s.Env] = s.Return
+	}
+	return v
+}
+
+type vecEnv struct {
+	workers []*worker
+	actions [][]mdp.Action
+	started bool
+}
+
+// NewVecEnv creates a VecEnv of `envs`, which must be distinct instances, that steps them concurrently in lockstep.
+// Episodes are truncated after `maxSteps` steps, and an environment with no actions available is treated as done.
+// Returns the VecEnv and a nil error on success, or returns nil and a non-nil error on failure.
+func NewVecEnv(envs []Environment, maxSteps int) (VecEnv, error) {
+	workers, err := newWorkers(envs, maxSteps)
+	if err != nil {
+		return nil, err
+	}
+	v := &vecEnv{}
+	v.workers = workers
+	v.actions = make([][]mdp.Action, len(envs))
+	return v, nil
+}
+
+// NewMDPVecEnv creates a VecEnv of `n` simulators of `m`, as made by NewMDPEnvironment, that steps them concurrently
+// in lockstep. Each simulator gets its own source of randomness, seeded from `rng`.
+// Returns the VecEnv and a nil error on success, or returns nil and a non-nil error on failure.
+func NewMDPVecEnv(m mdp.MDP, n int, maxSteps int, rng *rand.Rand) (VecEnv, error) {
+	envs, err := newMDPEnvironments(m, n, rng)
+	if err != nil {
+		return nil, err
+	}
+	return NewVecEnv(envs, maxSteps)
+}
+
+// newMDPEnvironments creates `n` simulators of `m`, each with a source of randomness seeded from `rng`.
+func newMDPEnvironments(m mdp.MDP, n int, rng *rand.Rand) ([]Environment, error) {
+	if n <= 0 {
+		return nil, errors.New("number of environments must be positive")
+	} else if rng == nil {
+		return nil, errors.New("random source must be provided")
+	}
+	envs := make([]Environment, n)
+	for i := range envs {
+		e, err := NewMDPEnvironment(m, rand.New(rand.NewSource(rng.Int63())))
+		if err != nil {
+			return nil, err
+		}
+		envs[i] = e
+	}
+	return envs, nil
+}
+
+func (v *vecEnv) Len() int {
+	return len(v.workers)
+}
+
+func (v *vecEnv) Actions() [][]mdp.Action {
+	res := make([][]mdp.Action, len(v.actions))
+	copy(res, v.actions)
+	return res
+}
+
+func (v *vecEnv) Reset() ([]mdp.State, error) {
+	states := make([]mdp.State, len(v.workers))
+	err := v.parallel(func(i int) error {
+		var err error
+		states[i], v.actions[i], err = v.workers[i].reset()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	v.started = true
+	return states, nil
+}
+
+func (v *vecEnv) Step(actions []mdp.Action) (*VecStep, error) {
+	if !v.started {
+		return nil, errors.New("call Reset before stepping")
+	} else if len(actions) != len(v.workers) {
+		return nil, errors.New("there must be one action per environment")
+	}
+	steps := make([]*EnvStep, len(v.workers))
+	err := v.parallel(func(i int) error {
+		s, err := v.workers[i].step(actions[i])
+		if err != nil {
+			return err
+		}
+		s.Env = i
+		steps[i] = s
+		v.actions[i] = s.Actions
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newVecStep(steps), nil
+}
+
+// parallel calls `f` on the position of every environment concurrently, and returns the error of the first
+// environment that failed, if any.
+func (v *vecEnv) parallel(f func(i int) error) error {
+	errs := make([]error, len(v.workers))
+	var wg sync.WaitGroup
+	for i := range v.workers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = f(i)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// request asks a worker's goroutine to take an action, or to reset if the action is nil.
+type request struct {
+	action mdp.Action
+}
+
+// result is a worker's response to a request.
+type result struct {
+	env  int
+	step *EnvStep
+	err  error
+}
+
+type asyncVecEnv struct {
+	workers  []*worker
+	requests []chan request
+	results  chan result
+	actions  [][]mdp.Action
+	pending  []bool
+	started  bool
+	closed   bool
+}
+
+// NewAsyncVecEnv creates an AsyncVecEnv of `envs`, which must be distinct instances, each stepped by its own
+// goroutine until Close is called. Episodes are truncated after `maxSteps` steps, and an environment with no actions
+// available is treated as done.
+// Returns the AsyncVecEnv and a nil error on success, or returns nil and a non-nil error on failure.
+func NewAsyncVecEnv(envs []Environment, maxSteps int) (AsyncVecEnv, error) {
+	workers, err := newWorkers(envs, maxSteps)
+	if err != nil {
+		return nil, err
+	}
+	v := &asyncVecEnv{}
+	v.workers = workers
+	v.requests = make([]chan request, len(envs))
+	v.results = make(chan result, len(envs))
+	v.actions = make([][]mdp.Action, len(envs))
+	v.pending = make([]bool, len(envs))
+	for i := range workers {
+		v.requests[i] = make(chan request, 1)
+		go v.run(i)
+	}
+	return v, nil
+}
+
+// NewAsyncMDPVecEnv creates an AsyncVecEnv of `n` simulators of `m`, as made by NewMDPEnvironment. Each simulator gets
+// its own source of randomness, seeded from `rng`.
+// Returns the AsyncVecEnv and a nil error on success, or returns nil and a non-nil error on failure.
+func NewAsyncMDPVecEnv(m mdp.MDP, n int, maxSteps int, rng *rand.Rand) (AsyncVecEnv, error) {
+	envs, err := newMDPEnvironments(m, n, rng)
+	if err != nil {
+		return nil, err
+	}
+	return NewAsyncVecEnv(envs, maxSteps)
+}
+
+// run serves the requests to the i-th worker until its channel is closed.
+func (v *asyncVecEnv) run(i int) {
+	for r := range v.requests[i] {
+		res := result{env: i}
+		if r.action == nil {
+			res.step = &EnvStep{}
+			res.step.State, res.step.Actions, res.err = v.workers[i].reset()
+		} else {
+			res.step, res.err = v.workers[i].step(r.action)
+		}
+		if res.err != nil {
+			res.step = nil
+		} else {
+			res.step.Env = i
+		}
+		v.results <- res
+	}
+}
+
+func (v *asyncVecEnv) Len() int {
+	return len(v.workers)
+}
+
+func (v *asyncVecEnv) Actions() [][]mdp.Action {
+	res := make([][]mdp.Action, len(v.actions))
+	copy(res, v.actions)
+	return res
+}
+
+func (v *asyncVecEnv) Pending() int {
+	pending := 0
+	for _, p := range v.pending {
+		if p {
+			pending++
+		}
+	}
+	return pending
+}
+
+func (v *asyncVecEnv) Reset() ([]mdp.State, error) {
+	if err := v.ready(); err != nil {
+		return nil, err
+	}
+	for i := range v.workers {
+		v.pending[i] = true
+		v.requests[i] <- request{}
+	}
+	steps, err := v.receiveAll()
+	if err != nil {
+		return nil, err
+	}
+	v.started = true
+	return newVecStep(steps).States, nil
+}
+
+func (v *asyncVecEnv) Step(actions []mdp.Action) (*VecStep, error) {
+	if err := v.ready(); err != nil {
+		return nil, err
+	} else if len(actions) != len(v.workers) {
+		return nil, errors.New("there must be one action per environment")
+	}
+	for i, action := range actions {
+		if err := v.Send(i, action); err != nil {
+			// Leave no step unreceived
+			if _, receiveErr := v.receiveAll(); receiveErr != nil {
+				return nil, receiveErr
+			}
+			return nil, err
+		}
+	}
+	steps, err := v.receiveAll()
+	if err != nil {
+		return nil, err
+	}
+	return newVecStep(steps), nil
+}
+
+func (v *asyncVecEnv) Send(i int, action mdp.Action) error {
+	if v.closed {
+		return errors.New("environments are closed")
+	} else if !v.started {
+		return errors.New("call Reset before stepping")
+	} else if i < 0 || i >= len(v.workers) {
+		return errors.New("environment is out of range")
+	} else if v.pending[i] {
+		return errors.New("environment is already stepping")
+	} else if action == nil {
+		return errors.New("action must be provided")
+	}
+	v.pending[i] = true
+	v.requests[i] <- request{action}
+	return nil
+}
+
+func (v *asyncVecEnv) Receive() (*EnvStep, error) {
+	if v.Pending() == 0 {
+		return nil, errors.New("no environment is stepping")
+	}
+	res := <-v.results
+	v.pending[res.env] = false
+	if res.err != nil {
+		return nil, res.err
+	}
+	v.actions[res.env] = res.step.Actions
+	return res.step, nil
+}
+
+func (v *asyncVecEnv) Close() {
+	if v.closed {
+		return
+	}
+	v.closed = true
+	for _, requests := range v.requests {
+		close(requests)
+	}
+}
+
+// ready returns an error if the environments can't take new steps for everyone at once.
+func (v *asyncVecEnv) ready() error {
+	if v.closed {
+		return errors.New("environments are closed")
+	} else if v.Pending() > 0 {
+		return errors.New("receive every pending step first")
+	}
+	return nil
+}
+
+// receiveAll receives every pending step, and returns the error of the first environment that failed, if any.
+func (v *asyncVecEnv) receiveAll() ([]*EnvStep, error) {
+	var steps []*EnvStep
+	var firstErr error
+	for v.Pending() > 0 {
+		s, err := v.Receive()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		steps = append(steps, s)
+	}
+	return steps, firstErr
+}
@@ -0,0 +1,87 @@
+package linear
+
+import (
+	"github.com/anthonykrivonos/go-rl/env"
+	"github.com/anthonykrivonos/go-rl/mdp"
+	"github.com/anthonykrivonos/go-rl/tabular"
+)
+
+// A linear learner of action values, with a weight vector per action and Q(s, a) = w_aᵀx(s).
+type QLearner interface {
+	tabular.QLearner
+	// Weights returns the learned weight vector of `action`.
+	Weights(action mdp.Action) []float32
+}
+
+type semiGradientSARSA struct {
+	config   tabular.Config
+	features Features
+	w        map[string][]float32
+	// The state and action already chosen for the next step
+	nextState  mdp.State
+	nextAction mdp.Action
+}
+
+// NewSemiGradientSARSA creates an on-policy semi-gradient SARSA agent, which moves the weights of each action taken by
+// α[r + ɣQ(s', a') - Q(s, a)]x(s), where a' is the action it goes on to take in s'.
+func NewSemiGradientSARSA(config tabular.Config, features Features) (QLearner, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	} else if features == nil {
+		return nil, errFeatures
+	}
+	l := &semiGradientSARSA{}
+	l.config = config
+	l.features = features
+	l.w = make(map[string][]float32)
+	return l, nil
+}
+
+func (l *semiGradientSARSA) Act(state mdp.State, actions []mdp.Action) mdp.Action {
+	if l.nextAction != nil && l.nextState.Equals(state) {
+		action := l.nextAction
+		l.nextState, l.nextAction = nil, nil
+		return action
+	}
+	return l.choose(state, actions)
+}
+
+func (l *semiGradientSARSA) Learn(step env.Step, nextActions []mdp.Action) {
+	target := step.Reward
+	l.nextState, l.nextAction = nil, nil
+	if !step.Done && len(nextActions) > 0 {
+		l.nextState = step.NextState
+		l.nextAction = l.choose(step.NextState, nextActions)
+		target += l.config.DiscountRate * l.Q(step.NextState, l.nextAction)
+	}
+	x := l.features.Extract(step.State)
+	w := l.Weights(step.Action)
+	addScaled(w, l.config.LearningRate*(target-dot(w, x)), x)
+}
+
+func (l *semiGradientSARSA) EndEpisode() {
+	l.nextState, l.nextAction = nil, nil
+}
+
+func (l *semiGradientSARSA) Q(state mdp.State, action mdp.Action) float32 {
+	return dot(l.Weights(action), l.features.Extract(state))
+}
+
+func (l *semiGradientSARSA) Weights(action mdp.Action) []float32 {
+	w, ok := l.w[action.Name()]
+	if !ok {
+		w = make([]float32, l.features.Size())
+		l.w[action.Name()] = w
+	}
+	return w
+}
+
+// choose returns the explorer's choice among `actions` in `state`.
+func (l *semiGradientSARSA) choose(state mdp.State, actions []mdp.Action) mdp.Action {
+	x := l.features.Extract(state)
+	values := make([]float32, len(actions))
+	for i, action := range actions {
+		values[i] = dot(l.Weights(action), x)
+	}
+	return l.config.Explorer.Choose(actions, values, l.config.Random)
+}
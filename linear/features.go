@@ -0,0 +1,239 @@
+package linear
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"math"
+
+	"github.com/anthonykrivonos/go-rl/env"
+	"github.com/anthonykrivonos/go-rl/mdp"
+)
+
+// A feature extractor, which maps states to feature vectors of a fixed size.
+type Features interface {
+	// Size returns the length of every feature vector.
+	Size() int
+	// Extract returns the feature vector of `state`.
+	Extract(state mdp.State) []float32
+}
+
+type oneHot struct {
+	size int
+}
+
+// NewOneHot creates a feature extractor for `size` states that sets only the feature at the state's index. States with
+// an index outside [0, size) have no features set. Linear learners with one-hot features are equivalent to tabular ones.
+func NewOneHot(size int) (Features, error) {
+	if size <= 0 {
+		return nil, errors.New("size must be positive")
+	}
+	return &oneHot{size}, nil
+}
+
+func (f *oneHot) Size() int {
+	return f.size
+}
+
+func (f *oneHot) Extract(state mdp.State) []float32 {
+	features := make([]float32, f.size)
+	if index := state.Index(); index >= 0 && index < f.size {
+		features[index] = 1
+	}
+	return features
+}
+
+// Coordinates maps a state to a point in continuous space, such as the row and column of a grid cell.
+type Coordinates func(state mdp.State) []float64
+
+// VectorCoordinates places observations at their vector and any other state on a line by its index.
+func VectorCoordinates(state mdp.State) []float64 {
+	if o, ok := state.(env.Observation); ok {
+		return o.Vector()
+	}
+	return []float64{float64(state.Index())}
+}
+
+type tileCoding struct {
+	coordinates Coordinates
+	low         []float64
+	width       []float64
+	tiles       int
+	tilings     int
+	perTiling   int
+	// The number of features tiles are hashed into, or 0 to give every tile its own feature
+	hashed int
+}
+
+// NewTileCoding creates a tile coding feature extractor. Each of the `tilings` tilings covers the box from `low` to
+// `high` with `tiles` tiles per dimension, and is offset from the first by a fraction of a tile that differs per
+// dimension, so that nearby points share most of their tiles. Exactly one feature per tiling is set: the tile the point
+// given by `coordinates` falls in. Points outside the box are clamped to its edge tiles.
+func NewTileCoding(coordinates Coordinates, low []float64, high []float64, tiles int, tilings int) (Features, error) {
+	return newTileCoding(coordinates, low, high, tiles, tilings, 0)
+}
+
+// NewHashedTileCoding creates a tile coding feature extractor like NewTileCoding, but hashes every tile into one of
+// `size` features. This bounds the number of weights when there are many dimensions or tiles, at the cost of unrelated
+// tiles occasionally sharing a feature.
+func NewHashedTileCoding(coordinates Coordinates, low []float64, high []float64, tiles int, tilings int, size int) (Features, error) {
+	if size <= 0 {
+		return nil, errors.New("size must be positive")
+	}
+	return newTileCoding(coordinates, low, high, tiles, tilings, size)
+}
+
+func newTileCoding(coordinates Coordinates, low []float64, high []float64, tiles int, tilings int, hashed int) (Features, error) {
+	if coordinates == nil {
+		return nil, errors.New("coordinates must be provided")
+	} else if len(low) == 0 || len(low) != len(high) {
+		return nil, errors.New("low and high must be non-empty and of equal length")
+	} else if tiles <= 0 || tilings <= 0 {
+		return nil, errors.New("tiles and tilings must be positive")
+	}
+	f := &tileCoding{}
+	f.coordinates = coordinates
+	f.low = low
+	f.width = make([]float64, len(low))
+	for d := range low {
+		if high[d] <= low[d] {
+			return nil, errors.New("high must be greater than low in every dimension")
+		}
+		f.width[d] = (high[d] - low[d]) / float64(tiles)
+	}
+	f.tiles = tiles
+	f.tilings = tilings
+	// Offset tilings need one more tile per dimension to cover the box
+	f.perTiling = 1
+	for range low {
+		f.perTiling *= tiles + 1
+	}
+	f.hashed = hashed
+	return f, nil
+}
+
+func (f *tileCoding) Size() int {
+	if f.hashed > 0 {
+		return f.hashed
+	}
+	return f.tilings * f.perTiling
+}
+
+func (f *tileCoding) Extract(state mdp.State) []float32 {
+	features := make([]float32, f.Size())
+	for _, tile := range f.active(f.coordinates(state)) {
+		features[tile] = 1
+	}
+	return features
+}
+
+// active returns the index of the tile `point` falls in for each tiling.
+func (f *tileCoding) active(point []float64) []int {
+	tiles := make([]int, f.tilings)
+	for t := range tiles {
+		index := 0
+		for d := range f.low {
+			// Asymmetric offsets of (2d + 1) / tilings of a tile avoid tilings lining up along the diagonals
+			offset := float64(t*(2*d+1)%f.tilings) / float64(f.tilings)
+			position := 0.0
+			if d < len(point) {
+				position = (point[d]-f.low[d])/f.width[d] + offset
+			}
+			coordinate := int(math.Floor(position))
+			if coordinate < 0 {
+				coordinate = 0
+			} else if coordinate > f.tiles {
+				coordinate = f.tiles
+			}
+			index = index*(f.tiles+1) + coordinate
+		}
+		tiles[t] = t*f.perTiling + index
+		if f.hashed > 0 {
+			hash := fnv.New32a()
+			var buffer [8]byte
+			binary.LittleEndian.PutUint64(buffer[:], uint64(tiles[t]))
+			hash.Write(buffer[:])
+			tiles[t] = int(hash.Sum32() % uint32(f.hashed))
+		}
+	}
+	return tiles
+}
+
+type rbf struct {
+	coordinates Coordinates
+	centers     [][]float64
+	width       float64
+}
+
+// NewRBF creates a radial basis function feature extractor with a Gaussian feature exp(-‖x - c‖² / 2σ²) per center c,
+// where x is the point given by `coordinates` and σ is `width`.
+func NewRBF(coordinates Coordinates, centers [][]float64, width float64) (Features, error) {
+	if coordinates == nil {
+		return nil, errors.New("coordinates must be provided")
+	} else if len(centers) == 0 {
+		return nil, errors.New("at least one center must be provided")
+	} else if width <= 0 {
+		return nil, errors.New("width must be positive")
+	}
+	return &rbf{coordinates, centers, width}, nil
+}
+
+func (f *rbf) Size() int {
+	return len(f.centers)
+}
+
+func (f *rbf) Extract(state mdp.State) []float32 {
+	point := f.coordinates(state)
+	features := make([]float32, len(f.centers))
+	for i, center := range f.centers {
+		distance := 0.0
+		for d := range center {
+			difference := center[d]
+			if d < len(point) {
+				difference -= point[d]
+			}
+			distance += difference * difference
+		}
+		features[i] = float32(math.Exp(-distance / (2 * f.width * f.width)))
+	}
+	return features
+}
+
+// GridCenters returns RBF centers evenly spaced over the box from `low` to `high`, `perDimension` to a dimension and
+// including the box's corners.
+func GridCenters(low []float64, high []float64, perDimension int) [][]float64 {
+	if len(low) == 0 || len(low) != len(high) || perDimension <= 0 {
+		return nil
+	}
+	centers := [][]float64{{}}
+	for d := range low {
+		var next [][]float64
+		for _, center := range centers {
+			for i := 0; i < perDimension; i++ {
+				value := low[d]
+				if perDimension > 1 {
+					value += (high[d] - low[d]) * float64(i) / float64(perDimension-1)
+				}
+				next = append(next, append(append([]float64{}, center...), value))
+			}
+		}
+		centers = next
+	}
+	return centers
+}
+
+// dot returns the dot product of two vectors.
+func dot(a, b []float32) float32 {
+	total := float32(0)
+	for i := range a {
+		total += a[i] * b[i]
+	}
+	return total
+}
+
+// addScaled adds `scale` times `x` to the weights `w`.
+func addScaled(w []float32, scale float32, x []float32) {
+	for i := range w {
+		w[i] += scale * x[i]
+	}
+}
@@ -0,0 +1,204 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/anthonykrivonos/go-rl/env"
+	"github.com/anthonykrivonos/go-rl/mdp"
+	"github.com/anthonykrivonos/go-rl/tabular"
+	"github.com/stretchr/testify/assert"
+)
+
+// newCorridorEnvironment creates a corridor of `length` states, where moving right from the second to last state
+// reaches the terminal and pays 1.
+func newCorridorEnvironment(t *testing.T, length int, rng *rand.Rand) env.Environment {
+	m, err := mdp.NewDefaultMDP()
+	assert.NoError(t, err)
+	left := mdp.NewAction("left")
+	right := mdp.NewAction("right")
+	states := make([]mdp.State, length)
+	for i := range states {
+		states[i] = mdp.NewState(fmt.Sprint("C", i), i, i == length-1)
+	}
+	for i, state := range states {
+		if state.Terminal() {
+			assert.NoError(t, m.AddStateObject(state, 1, nil))
+			continue
+		}
+		previous := states[i]
+		if i > 0 {
+			previous = states[i-1]
+		}
+		assert.NoError(t, m.AddStateObject(state, 0, map[mdp.Action]mdp.Transition{
+			left:  mdp.NewTransition(1, previous),
+			right: mdp.NewTransition(1, states[i+1]),
+		}))
+	}
+	e, err := env.NewMDPEnvironment(m, rng)
+	assert.NoError(t, err)
+	return e
+}
+
+func alwaysRight(_ mdp.State, actions []mdp.Action) mdp.Action {
+	for _, action := range actions {
+		if action.Name() == "right" {
+			return action
+		}
+	}
+	return actions[0]
+}
+
+// position places corridor states on a line by their index.
+func position(state mdp.State) []float64 {
+	return []float64{float64(state.Index())}
+}
+
+func TestOneHot(t *testing.T) {
+	f, err := NewOneHot(3)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, f.Size())
+	assert.Equal(t, []float32{0, 1, 0}, f.Extract(mdp.NewState("S1", 1, false)))
+	assert.Equal(t, []float32{0, 0, 0}, f.Extract(mdp.NewState("S5", 5, false)))
+	_, err = NewOneHot(0)
+	assert.Error(t, err)
+}
+
+func TestTileCoding(t *testing.T) {
+	f, err := NewTileCoding(position, []float64{0}, []float64{10}, 5, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, 4*6, f.Size())
+
+	active := func(x float64) map[int]bool {
+		tiles := make(map[int]bool)
+		for i, value := range f.Extract(mdp.NewState("S", int(x), false)) {
+			if value != 0 {
+				tiles[i] = true
+			}
+		}
+		return tiles
+	}
+	shared := func(a, b map[int]bool) int {
+		count := 0
+		for i := range a {
+			if b[i] {
+				count++
+			}
+		}
+		return count
+	}
+	assert.Len(t, active(3), 4)
+	assert.Equal(t, 4, shared(active(2), active(2)))
+	assert.Greater(t, shared(active(2), active(3)), shared(active(2), active(6)))
+	assert.Equal(t, 0, shared(active(0), active(9)))
+	// Points outside the box are clamped to the edge tiles
+	assert.Len(t, active(50), 4)
+
+	_, err = NewTileCoding(position, []float64{0}, []float64{0}, 5, 4)
+	assert.Error(t, err)
+	_, err = NewTileCoding(position, []float64{0}, []float64{1, 2}, 5, 4)
+	assert.Error(t, err)
+	_, err = NewTileCoding(nil, []float64{0}, []float64{1}, 5, 4)
+	assert.Error(t, err)
+}
+
+func TestPredictionWithOneHotFeatures(t *testing.T) {
+	length := 6
+	gamma := float32(0.9)
+	features, err := NewOneHot(length)
+	assert.NoError(t, err)
+	config := tabular.Config{LearningRate: 0.2, DiscountRate: gamma}
+
+	learners := map[string]func() (VLearner, error){
+		"semi-gradient TD": func() (VLearner, error) { return NewSemiGradientTD(config, features, alwaysRight) },
+		"gradient MC":      func() (VLearner, error) { return NewGradientMC(config, features, alwaysRight) },
+	}
+	for name, newLearner := range learners {
+		learner, err := newLearner()
+		assert.NoError(t, err)
+		e := newCorridorEnvironment(t, length, rand.New(rand.NewSource(1)))
+		_, err = env.Train(e, learner, 200, 100)
+		assert.NoError(t, err)
+		for i := 0; i < length-1; i++ {
+			expected := math.Pow(float64(gamma), float64(length-2-i))
+			assert.InDelta(t, expected, learner.V(mdp.NewState(fmt.Sprint("C", i), i, false)), 1e-3, name)
+		}
+		assert.Len(t, learner.Weights(), length)
+	}
+
+	_, err = NewSemiGradientTD(config, nil, alwaysRight)
+	assert.Error(t, err)
+	_, err = NewGradientMC(config, features, nil)
+	assert.Error(t, err)
+}
+
+func TestSemiGradientSARSAWithTileCoding(t *testing.T) {
+	length := 10
+	features, err := NewTileCoding(position, []float64{0}, []float64{float64(length)}, 5, 8)
+	assert.NoError(t, err)
+	explorer, err := tabular.NewEpsilonGreedy(0.1)
+	assert.NoError(t, err)
+	config := tabular.Config{
+		LearningRate: 0.1 / 8,
+		DiscountRate: 0.9,
+		Explorer:     explorer,
+		Random:       rand.New(rand.NewSource(2)),
+	}
+	learner, err := NewSemiGradientSARSA(config, features)
+	assert.NoError(t, err)
+
+	e := newCorridorEnvironment(t, length, rand.New(rand.NewSource(3)))
+	returns, err := env.Train(e, learner, 300, 200)
+	assert.NoError(t, err)
+	assert.Equal(t, float32(1), returns[len(returns)-1])
+
+	left, right := mdp.NewAction("left"), mdp.NewAction("right")
+	for i := 0; i < length-1; i++ {
+		state := mdp.NewState(fmt.Sprint("C", i), i, false)
+		assert.Greater(t, learner.Q(state, right), learner.Q(state, left), state.String())
+	}
+	assert.Len(t, learner.Weights(right), features.Size())
+
+	_, err = NewSemiGradientSARSA(tabular.Config{LearningRate: 0.1, DiscountRate: 0.9}, features)
+	assert.Error(t, err)
+}
+
+func TestRBF(t *testing.T) {
+	centers := GridCenters([]float64{0, 0}, []float64{1, 2}, 3)
+	assert.Len(t, centers, 9)
+	assert.Equal(t, []float64{0, 0}, centers[0])
+	assert.Equal(t, []float64{0.5, 1}, centers[4])
+	assert.Equal(t, []float64{1, 2}, centers[8])
+
+	f, err := NewRBF(VectorCoordinates, centers, 0.5)
+	assert.NoError(t, err)
+	features := f.Extract(env.NewObservation([]float64{0.5, 1}, false))
+	assert.Equal(t, float32(1), features[4])
+	assert.InDelta(t, math.Exp(-2), features[3], 1e-6)
+	assert.Less(t, features[0], features[3])
+
+	_, err = NewRBF(VectorCoordinates, nil, 0.5)
+	assert.Error(t, err)
+	_, err = NewRBF(VectorCoordinates, centers, 0)
+	assert.Error(t, err)
+}
+
+func TestHashedTileCoding(t *testing.T) {
+	f, err := NewHashedTileCoding(VectorCoordinates, []float64{0, 0}, []float64{1, 1}, 10, 4, 64)
+	assert.NoError(t, err)
+	assert.Equal(t, 64, f.Size())
+	a := f.Extract(env.NewObservation([]float64{0.3, 0.3}, false))
+	b := f.Extract(env.NewObservation([]float64{0.3, 0.3}, false))
+	assert.Equal(t, a, b)
+	total := float32(0)
+	for _, value := range a {
+		total += value
+	}
+	assert.LessOrEqual(t, total, float32(4))
+	assert.Greater(t, total, float32(0))
+
+	_, err = NewHashedTileCoding(VectorCoordinates, []float64{0}, []float64{1}, 10, 4, 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,80 @@
+package linear
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/anthonykrivonos/go-rl/classic"
+	"github.com/anthonykrivonos/go-rl/env"
+	"github.com/anthonykrivonos/go-rl/mdp"
+	"github.com/anthonykrivonos/go-rl/tabular"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMountainCar(t *testing.T) env.Environment {
+	e, err := classic.NewMountainCar(rand.New(rand.NewSource(2)))
+	assert.NoError(t, err)
+	return e
+}
+
+// mean returns the mean of `values`.
+func mean(values []float32) float32 {
+	total := float32(0)
+	for _, value := range values {
+		total += value
+	}
+	return total / float32(len(values))
+}
+
+func TestMountainCar(t *testing.T) {
+	tilings := 8
+	extractors := map[string]func() (Features, error){
+		"tile coding": func() (Features, error) {
+			return NewTileCoding(VectorCoordinates, classic.MountainCarLow, classic.MountainCarHigh, 8, tilings)
+		},
+		"hashed tile coding": func() (Features, error) {
+			return NewHashedTileCoding(VectorCoordinates, classic.MountainCarLow, classic.MountainCarHigh, 8, tilings, 1024)
+		},
+	}
+	for name, newFeatures := range extractors {
+		features, err := newFeatures()
+		assert.NoError(t, err)
+		config := tabular.Config{
+			LearningRate: 0.5 / float32(tilings),
+			DiscountRate: 1,
+			Explorer:     tabular.NewGreedy(),
+			Random:       rand.New(rand.NewSource(1)),
+		}
+		learner, err := NewSemiGradientSARSA(config, features)
+		assert.NoError(t, err)
+
+		// Values start optimistic at 0, so greedy learning explores until it finds the goal
+		returns, err := env.Train(newMountainCar(t), learner, 100, 5000)
+		assert.NoError(t, err)
+		assert.Greater(t, mean(returns[90:]), float32(-250), name)
+		assert.Greater(t, mean(returns[90:]), mean(returns[:10]), name)
+	}
+}
+
+func TestRBFOnMountainCar(t *testing.T) {
+	// Scale velocity so that both dimensions span the same range
+	coordinates := func(state mdp.State) []float64 {
+		v := VectorCoordinates(state)
+		return []float64{v[0], v[1] * 12}
+	}
+	features, err := NewRBF(coordinates, GridCenters([]float64{-1.2, -0.84}, []float64{0.6, 0.84}, 10), 0.12)
+	assert.NoError(t, err)
+	assert.Equal(t, 100, features.Size())
+
+	config := tabular.Config{
+		LearningRate: 0.05,
+		DiscountRate: 1,
+		Explorer:     tabular.NewGreedy(),
+		Random:       rand.New(rand.NewSource(1)),
+	}
+	learner, err := NewSemiGradientSARSA(config, features)
+	assert.NoError(t, err)
+	returns, err := env.Train(newMountainCar(t), learner, 100, 5000)
+	assert.NoError(t, err)
+	assert.Greater(t, mean(returns[90:]), mean(returns[:10]))
+}
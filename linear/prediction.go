@@ -0,0 +1,118 @@
+package linear
+
+import (
+	"errors"
+
+	"github.com/anthonykrivonos/go-rl/env"
+	"github.com/anthonykrivonos/go-rl/mdp"
+	"github.com/anthonykrivonos/go-rl/tabular"
+)
+
+var (
+	errFeatures = errors.New("features must be provided")
+	errPolicy   = errors.New("policy must be provided")
+)
+
+// A linear learner of the state values of a fixed policy, with V(s) = wᵀx(s).
+type VLearner interface {
+	tabular.VLearner
+	// Weights returns the learned weight vector w.
+	Weights() []float32
+}
+
+// validatePrediction returns an error describing the first invalid argument of a prediction learner, or nil if they're
+// valid.
+func validatePrediction(config tabular.Config, features Features, policy tabular.Policy) error {
+	if err := config.ValidateRates(); err != nil {
+		return err
+	} else if features == nil {
+		return errFeatures
+	} else if policy == nil {
+		return errPolicy
+	}
+	return nil
+}
+
+// predictor holds what the linear prediction learners share.
+type predictor struct {
+	config   tabular.Config
+	features Features
+	policy   tabular.Policy
+	w        []float32
+}
+
+func newPredictor(config tabular.Config, features Features, policy tabular.Policy) predictor {
+	return predictor{config, features, policy, make([]float32, features.Size())}
+}
+
+func (p *predictor) Act(state mdp.State, actions []mdp.Action) mdp.Action {
+	return p.policy(state, actions)
+}
+
+func (p *predictor) V(state mdp.State) float32 {
+	return dot(p.w, p.features.Extract(state))
+}
+
+func (p *predictor) Weights() []float32 {
+	return p.w
+}
+
+type semiGradientTD struct {
+	predictor
+}
+
+// NewSemiGradientTD creates a semi-gradient TD(0) prediction agent, which follows `policy` and moves the weights by
+// α[r + ɣV(s') - V(s)]x(s) every step. The explorer and random source of `config` are unused.
+func NewSemiGradientTD(config tabular.Config, features Features, policy tabular.Policy) (VLearner, error) {
+	if err := validatePrediction(config, features, policy); err != nil {
+		return nil, err
+	}
+	return &semiGradientTD{newPredictor(config, features, policy)}, nil
+}
+
+func (l *semiGradientTD) Learn(step env.Step, nextActions []mdp.Action) {
+	target := step.Reward
+	if !step.Done && len(nextActions) > 0 {
+		target += l.config.DiscountRate * l.V(step.NextState)
+	}
+	x := l.features.Extract(step.State)
+	addScaled(l.w, l.config.LearningRate*(target-dot(l.w, x)), x)
+}
+
+func (l *semiGradientTD) EndEpisode() {}
+
+type gradientMC struct {
+	predictor
+	steps []env.Step
+}
+
+// NewGradientMC creates a gradient Monte Carlo prediction agent, which follows `policy` and, once each episode is over,
+// moves the weights by α[G - V(s)]x(s) for every state visited, where G is the discounted return that followed it.
+// Episodes cut short are learned from as if they had ended. The explorer and random source of `config` are unused.
+func NewGradientMC(config tabular.Config, features Features, policy tabular.Policy) (VLearner, error) {
+	if err := validatePrediction(config, features, policy); err != nil {
+		return nil, err
+	}
+	return &gradientMC{predictor: newPredictor(config, features, policy)}, nil
+}
+
+func (l *gradientMC) Learn(step env.Step, nextActions []mdp.Action) {
+	l.steps = append(l.steps, step)
+	if step.Done || len(nextActions) == 0 {
+		l.EndEpisode()
+	}
+}
+
+func (l *gradientMC) EndEpisode() {
+	returns := make([]float32, len(l.steps))
+	g := float32(0)
+	for t := len(l.steps) - 1; t >= 0; t-- {
+		g = l.steps[t].Reward + l.config.DiscountRate*g
+		returns[t] = g
+	}
+	for t, s := range l.steps {
+		x := l.features.Extract(s.State)
+		addScaled(l.w, l.config.LearningRate*(returns[t]-dot(l.w, x)), x)
+	}
+	l.steps = nil
+}
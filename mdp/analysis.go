@@ -0,0 +1,153 @@
+package mdp
+
+import (
+	"sort"
+
+	"github.com/anthonykrivonos/go-rl/utils"
+)
+
+// Analysis is a structural report on an MDP's transition graph, used to catch modelling bugs before solving.
+// The graph has an edge from each non-terminal state to every state an available action can lead to, and terminal states
+// have no outgoing edges.
+type Analysis struct {
+	// Unreachable lists the states that cannot be reached from the initial state.
+	Unreachable []State
+	// DeadEnds lists the non-terminal states that have no actions.
+	DeadEnds []State
+	// AbsorbingSets lists the closed sets of non-terminal states, which no action ever leaves.
+	AbsorbingSets [][]State
+	// Components lists the strongly connected components of the transition graph.
+	Components [][]State
+	// CannotTerminate lists the states from which no terminal state can be reached.
+	CannotTerminate []State
+	// DanglingTransitions maps state indices to the actions whose transitions lead to states not in the MDP.
+	DanglingTransitions map[int][]Action
+}
+
+// Analyze builds a structural report on the MDP's transition graph.
+func Analyze(m MDP) *Analysis {
+	states := m.States()
+	position := make(map[int]int)
+	for i, state := range states {
+		position[state.Index()] = i
+	}
+
+	a := &Analysis{}
+	a.DanglingTransitions = make(map[int][]Action)
+
+	successors := make([][]int, len(states))
+	for i, state := range states {
+		if state.Terminal() {
+			continue
+		}
+		actions := m.AvailableActions(state)
+		if len(actions) == 0 {
+			a.DeadEnds = append(a.DeadEnds, state)
+			continue
+		}
+		seen := make(map[int]bool)
+		for _, action := range actions {
+			transition := m.TByIndex(state.Index(), action.Name())
+			if transition.NextState() == nil {
+				a.DanglingTransitions[state.Index()] = append(a.DanglingTransitions[state.Index()], action)
+				continue
+			}
+			dangling := false
+			for _, outcome := range Outcomes(state, transition) {
+				next, ok := position[outcome.NextState().Index()]
+				if !ok {
+					dangling = true
+					continue
+				}
+				if outcome.Probability() > 0 && !seen[next] {
+					seen[next] = true
+					successors[i] = append(successors[i], next)
+				}
+			}
+			if dangling {
+				a.DanglingTransitions[state.Index()] = append(a.DanglingTransitions[state.Index()], action)
+			}
+		}
+	}
+	edges := func(v int) []int {
+		return successors[v]
+	}
+
+	toStates := func(positions []int) []State {
+		res := make([]State, len(positions))
+		for i, p := range positions {
+			res[i] = states[p]
+		}
+		sort.Slice(res, func(i, j int) bool {
+			return res[i].Index() < res[j].Index()
+		})
+		return res
+	}
+
+	// Reachability from the initial state
+	if initial := m.InitialState(); initial != nil {
+		if start, ok := position[initial.Index()]; ok {
+			reached := utils.Reachable(len(states), []int{start}, edges)
+			for i, state := range states {
+				if !reached[i] {
+					a.Unreachable = append(a.Unreachable, state)
+				}
+			}
+		}
+	}
+
+	// Strongly connected components and the closed ones among them
+	components := utils.StronglyConnectedComponents(len(states), edges)
+	component := make([]int, len(states))
+	for c, positions := range components {
+		for _, p := range positions {
+			component[p] = c
+		}
+	}
+	for c, positions := range components {
+		closed := true
+		for _, p := range positions {
+			if states[p].Terminal() {
+				closed = false
+			}
+			for _, next := range successors[p] {
+				if component[next] != c {
+					closed = false
+				}
+			}
+		}
+		if closed {
+			a.AbsorbingSets = append(a.AbsorbingSets, toStates(positions))
+		}
+		a.Components = append(a.Components, toStates(positions))
+	}
+	byFirstIndex := func(sets [][]State) func(i, j int) bool {
+		return func(i, j int) bool {
+			return sets[i][0].Index() < sets[j][0].Index()
+		}
+	}
+	sort.Slice(a.Components, byFirstIndex(a.Components))
+	sort.Slice(a.AbsorbingSets, byFirstIndex(a.AbsorbingSets))
+
+	// States that can reach a terminal, found by searching backwards from the terminals
+	predecessors := make([][]int, len(states))
+	var terminals []int
+	for i, state := range states {
+		if state.Terminal() {
+			terminals = append(terminals, i)
+		}
+		for _, next := range successors[i] {
+			predecessors[next] = append(predecessors[next], i)
+		}
+	}
+	canTerminate := utils.Reachable(len(states), terminals, func(v int) []int {
+		return predecessors[v]
+	})
+	for i, state := range states {
+		if !canTerminate[i] {
+			a.CannotTerminate = append(a.CannotTerminate, state)
+		}
+	}
+
+	return a
+}
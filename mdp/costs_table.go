@@ -0,0 +1,92 @@
+package mdp
+
+import (
+	"fmt"
+	"sort"
+)
+
+type CostsTable interface {
+	Get(channel string, state State, action Action) float32
+	Set(channel string, state State, action Action, cost float32)
+	Remove(state State)
+	Channels() []string
+	String(prefix string) string
+}
+
+// costKey identifies the cost of taking an action in a state. An empty action name is the cost of being in the state,
+// which applies to every action.
+type costKey struct {
+	index  int
+	action string
+}
+
+type costsTable struct {
+	table    map[string]map[costKey]float32
+	stateMap map[int]State
+}
+
+func (c *costsTable) Get(channel string, state State, action Action) float32 {
+	costs := c.table[channel]
+	cost := costs[costKey{state.Index(), ""}]
+	if action != nil {
+		cost += costs[costKey{state.Index(), action.Name()}]
+	}
+	return cost
+}
+
+func (c *costsTable) Set(channel string, state State, action Action, cost float32) {
+	if _, ok := c.table[channel]; !ok {
+		c.table[channel] = make(map[costKey]float32)
+	}
+	key := costKey{state.Index(), ""}
+	if action != nil {
+		key.action = action.Name()
+	}
+	c.table[channel][key] = cost
+	c.stateMap[state.Index()] = state
+}
+
+func (c *costsTable) Remove(state State) {
+	for _, costs := range c.table {
+		for key := range costs {
+			if key.index == state.Index() {
+				delete(costs, key)
+			}
+		}
+	}
+	delete(c.stateMap, state.Index())
+}
+
+func (c *costsTable) Channels() []string {
+	channels := make([]string, 0, len(c.table))
+	for channel := range c.table {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+	return channels
+}
+
+func (c *costsTable) String(prefix string) string {
+	res := "{\n"
+	for _, channel := range c.Channels() {
+		res += prefix + "	" + channel + ": {\n"
+		for key, cost := range c.table[channel] {
+			res += prefix + "		" + c.stateMap[key.index].String()
+			if key.action != "" {
+				res += " " + key.action
+			}
+			res += ": " + fmt.Sprint(cost) + ",\n"
+		}
+		res += prefix + "	},\n"
+	}
+	res = res[:len(res)-2]
+	res += "\n" + prefix + "}"
+	return res
+}
+
+func NewCosts() CostsTable {
+	c := &costsTable{}
+	c.table = make(map[string]map[costKey]float32)
+	c.stateMap = make(map[int]State)
+	return c
+}
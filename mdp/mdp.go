@@ -3,6 +3,7 @@ package mdp
 import (
 	"errors"
 	"fmt"
+	"sort"
 )
 
 // Starting number of available states
@@ -14,6 +15,18 @@ type MDP interface {
 	RByIndex(stateIndex int) float32
 	T(state string, action string) Transition
 	TByIndex(stateIndex int, action string) Transition
+	InitialState() State
+	StateByName(state string) State
+	StateByIndex(index int) State
+	States() []State
+	Actions() []Action
+	AvailableActions(state State) []Action
+	Predecessors(state State) []State
+	DiscountRate() float32
+	C(channel string, state string, action string) float32
+	CByIndex(channel string, stateIndex int, action string) float32
+	CostChannels() []string
+	SetCost(channel string, state string, action string, cost float32) error
 	SetState(state string, index int, terminal bool, reward float32, transitions map[string]Transition) error
 	SetStateObject(state State, reward float32, transitions map[Action]Transition) error
 	SetInitialState(state string, reward float32, transitions map[string]Transition) error
@@ -44,11 +57,21 @@ type mdp struct {
 	actions map[string]Action
 
 	rewards RewardsTable
+	costs CostsTable
 	transitions TransitionTable
 	discountRate float32
 
 	stateMap map[string]State
 	stateIndexMap map[int]State
+
+	// predecessors maps each state index to the transitions that lead into it
+	predecessors map[int]map[incomingTransition]bool
+}
+
+// incomingTransition identifies a transition by the index of the state it leaves and the name of its action.
+type incomingTransition struct {
+	index int
+	action string
 }
 
 // NewMDP constructs a new Markov Decision process.
@@ -71,8 +94,10 @@ func NewMDP(initialState string, states, terminals, actions []string, rewards ma
 	m.states = make([]State, m.statesCapacity)
 	m.stateMap = make(map[string]State)
 	m.stateIndexMap = make(map[int]State)
+	m.predecessors = make(map[int]map[incomingTransition]bool)
 	m.actions = make(map[string]Action)
 	m.rewards = NewRewards(nil)
+	m.costs = NewCosts()
 	m.transitions = NewTransitionTable(nil)
 
 	// Create initial state
@@ -114,12 +139,15 @@ func NewMDP(initialState string, states, terminals, actions []string, rewards ma
 			m.rewards.Set(s, rewards[state])
 			entry := NewTransitionTableEntry(nil)
 			for action, _ := range transitions[state] {
-				if _, ok := terminalMap[state]; !ok {
+				if err := ValidateTransition(transitions[state][action]); err != nil {
+					return nil, err
+				} else if _, ok := terminalMap[state]; !ok {
 					return nil, errors.New("action with name " + action + " not in MDP")
 				}
-				entry.Set(m.actions[action], transitions[state][action].Probability(), transitions[state][action].NextState())
+				entry.SetTransition(m.actions[action], copyTransition(transitions[state][action]))
 			}
 			m.transitions.Set(s, entry)
+			m.indexTransitions(s)
 			i++
 		}
 	}
@@ -197,6 +225,108 @@ func (m *mdp) TByIndex(stateIndex int, action string) Transition {
 	return m.transitions.Get(m.getStateByIndex(stateIndex)).Get(m.getAction(action))
 }
 
+// InitialState returns the MDP's initial State object, or nil if none has been set.
+func (m *mdp) InitialState() State {
+	return m.initialState
+}
+
+// StateByName returns the State object with the provided name, or nil if not found.
+func (m *mdp) StateByName(state string) State {
+	return m.getStateByName(state)
+}
+
+// StateByIndex returns the State object at the provided index, or nil if not found.
+func (m *mdp) StateByIndex(index int) State {
+	return m.getStateByIndex(index)
+}
+
+// States returns every State object in the MDP, ordered by index.
+func (m *mdp) States() []State {
+	states := make([]State, 0, len(m.stateIndexMap))
+	for _, state := range m.stateIndexMap {
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Index() < states[j].Index()
+	})
+	return states
+}
+
+// Actions returns every Action object in the MDP, ordered by name.
+func (m *mdp) Actions() []Action {
+	actions := make([]Action, 0, len(m.actions))
+	for _, action := range m.actions {
+		actions = append(actions, action)
+	}
+	sort.Slice(actions, func(i, j int) bool {
+		return actions[i].Name() < actions[j].Name()
+	})
+	return actions
+}
+
+// AvailableActions returns the actions with a transition out of the provided state, ordered by name.
+func (m *mdp) AvailableActions(state State) []Action {
+	if state == nil {
+		return nil
+	}
+	entry := m.transitions.Get(m.getStateByIndex(state.Index()))
+	if entry == nil {
+		return nil
+	}
+	return entry.Actions()
+}
+
+// DiscountRate returns the MDP's discount rate (gamma).
+func (m *mdp) DiscountRate() float32 {
+	return m.discountRate
+}
+
+// C returns the cost in the given channel of taking the action with the provided name in the state with the provided
+// name. This includes the state's own cost in that channel. Use an empty action name for the state's cost alone.
+func (m *mdp) C(channel string, state string, action string) float32 {
+	s := m.getStateByName(state)
+	if s == nil {
+		return 0
+	}
+	return m.costs.Get(channel, s, m.getAction(action))
+}
+
+// CByIndex returns the cost in the given channel of taking the action with the provided name in the state with the
+// provided index. This includes the state's own cost in that channel. Use an empty action name for the state's cost alone.
+func (m *mdp) CByIndex(channel string, stateIndex int, action string) float32 {
+	s := m.getStateByIndex(stateIndex)
+	if s == nil {
+		return 0
+	}
+	return m.costs.Get(channel, s, m.getAction(action))
+}
+
+// CostChannels returns the names of every cost channel with a cost set, ordered by name.
+func (m *mdp) CostChannels() []string {
+	return m.costs.Channels()
+}
+
+// SetCost sets the cost in the given channel of taking the action with the provided name in the state with the provided
+// name. Use an empty action name to set a cost for being in the state, which applies to every action.
+func (m *mdp) SetCost(channel string, state string, action string, cost float32) error {
+	if channel == "" {
+		return errors.New("channel must be provided")
+	}
+	s := m.getStateByName(state)
+	if s == nil {
+		return errors.New("state with name " + state + " doesn't exist")
+	}
+	var a Action
+	if action != "" {
+		a = m.getAction(action)
+		if a == nil {
+			return errors.New("action with name " + action + " doesn't exist")
+		}
+	}
+	m.costs.Set(channel, s, a, cost)
+	return nil
+}
+
 // SetState creates and sets a state with provided properties in the MDP. Overwrites if necessary.
 func (m *mdp) SetState(state string, index int, terminal bool, reward float32, transitions map[string]Transition) error {
 	if index < 0 {
@@ -204,12 +334,19 @@ func (m *mdp) SetState(state string, index int, terminal bool, reward float32, t
 	} else if state == "" {
 		return errors.New("name must be provided")
 	}
+	for _, transition := range transitions {
+		if err := ValidateTransition(transition); err != nil {
+			return err
+		}
+	}
 
 	s := NewState(state, index, terminal)
 	if m.getStateByIndex(index) != nil {
 		// Delete the old state at the given index
 		sOld := m.getStateByIndex(index)
+		m.unindexTransitions(sOld)
 		m.rewards.Remove(sOld)
+		m.costs.Remove(sOld)
 		m.transitions.Remove(sOld)
 	}
 	err := m.appendStateToList(s)
@@ -231,9 +368,10 @@ func (m *mdp) SetState(state string, index int, terminal bool, reward float32, t
 				return err
 			}
 		}
-		entry.Set(a, transitions[action].Probability(), transitions[action].NextState())
+		entry.SetTransition(a, copyTransition(transitions[action]))
 	}
 	m.transitions.Set(s, entry)
+	m.indexTransitions(s)
 
 	// Update the initial state if the index is 0
 	if index == 0 {
@@ -250,11 +388,18 @@ func (m *mdp) SetStateObject(state State, reward float32, transitions map[Action
 	} else if state.Name() == "" {
 		return errors.New("name must be provided")
 	}
+	for _, transition := range transitions {
+		if err := ValidateTransition(transition); err != nil {
+			return err
+		}
+	}
 
 	if m.getStateByIndex(state.Index()) != nil {
 		// Delete the old state at the given index
 		sOld := m.getStateByIndex(state.Index())
+		m.unindexTransitions(sOld)
 		m.rewards.Remove(sOld)
+		m.costs.Remove(sOld)
 		m.transitions.Remove(sOld)
 	}
 	err := m.appendStateToList(state)
@@ -274,9 +419,10 @@ func (m *mdp) SetStateObject(state State, reward float32, transitions map[Action
 				return err
 			}
 		}
-		entry.Set(action, transitions[action].Probability(), transitions[action].NextState())
+		entry.SetTransition(action, copyTransition(transitions[action]))
 	}
 	m.transitions.Set(state, entry)
+	m.indexTransitions(state)
 
 	// Update the initial state if the index is 0
 	if state.Index() == 0 {
@@ -291,9 +437,14 @@ func (m *mdp) SetInitialState(state string, reward float32, transitions map[stri
 	return m.SetState(state, 0, false, reward, transitions)
 }
 
-// SetInitialState sets a new initial State object (same as SetStateObject on index 0).
+// SetInitialStateObject sets a State object like SetStateObject and makes it the initial state, whatever its index.
+// Setting a state at index 0 afterwards makes that state the initial state instead.
 func (m *mdp) SetInitialStateObject(state State, reward float32, transitions map[Action]Transition) error {
-	return m.SetStateObject(state, reward, transitions)
+	if err := m.SetStateObject(state, reward, transitions); err != nil {
+		return err
+	}
+	m.initialState = state
+	return nil
 }
 
 // AddState creates and adds a new State object without overwriting any states.
@@ -324,15 +475,31 @@ func (m *mdp) AddStateObject(state State, reward float32, transitions map[Action
 	return m.SetStateObject(state, reward, transitions)
 }
 
-// RemoveStateByIndex removes a State object at the provided `index`.
+// RemoveStateByIndex removes a State object at the provided `index`, along with every transition outcome that leads into
+// it. A distribution keeps its other outcomes, and the probability of the removed one is left over to stay put; any other
+// transition into the state is removed with its action. Use Predecessors beforehand to find the states whose transitions
+// will change.
 func (m *mdp) RemoveStateByIndex(index int) error {
 	// Delete the old state at the given index
 	sOld := m.getStateByIndex(index)
 	if sOld == nil {
 		return errors.New("state at index " + fmt.Sprint(index) + " doesn't exist")
 	}
+
+	// Remove the transitions into the state so that no dangling next states survive
+	for incoming := range m.predecessors[index] {
+		if incoming.index != index {
+			m.removeOutcome(m.getStateByIndex(incoming.index), incoming.action, sOld)
+		}
+	}
+	m.unindexTransitions(sOld)
+	delete(m.predecessors, index)
+
 	m.states[index] = nil
+	delete(m.stateMap, sOld.Name())
+	delete(m.stateIndexMap, index)
 	m.rewards.Remove(sOld)
+	m.costs.Remove(sOld)
 	m.transitions.Remove(sOld)
 	return nil
 }
@@ -379,8 +546,8 @@ func (m *mdp) RemoveAction(action string) error {
 		return errors.New("action with name " + action + " doesn't exist")
 	}
 	delete(m.actions, action)
-	for _, state := range m.states {
-		m.transitions.Get(state).Remove(a)
+	for _, state := range m.stateIndexMap {
+		m.removeTransition(state, action)
 	}
 	return nil
 }
@@ -402,17 +569,134 @@ func (m *mdp) SetDiscountRate(discountRate float32) error {
 // SetTransition updates the transition between two states with provided names, given the action and the probability of
 // pursuing this action.
 func (m *mdp) SetTransition(startState, endState string, action string, probability float32) {
-	m.transitions.Update(m.getStateByName(startState), m.getAction(action), probability, m.getStateByName(endState))
+	s := m.getStateByName(startState)
+	if s == nil || m.getAction(action) == nil || m.transitions.Get(s) == nil {
+		return
+	}
+	m.unindexTransitions(s)
+	m.removeTransition(s, action)
+	m.transitions.Update(s, m.getAction(action), probability, m.getStateByName(endState))
+	m.indexTransitions(s)
 }
 
 // RemoveTransition removes the transition between the two states with given names.
 func (m *mdp) RemoveTransition(startState, endState string) {
-	m.transitions.Get(m.getStateByName(startState)).RemoveTransition(m.getStateByName(endState))
+	s, next := m.getStateByName(startState), m.getStateByName(endState)
+	if s == nil || next == nil || m.transitions.Get(s) == nil {
+		return
+	}
+	for _, action := range m.transitions.Get(s).Actions() {
+		for _, target := range targets(m.transitions.Get(s).Get(action)) {
+			if target.Equals(next) {
+				m.removeTransition(s, action.Name())
+				break
+			}
+		}
+	}
 }
 
 // RemoveTransition removes the transition taken via the provided action name from the given `startState`
 func (m *mdp) RemoveTransitionByAction(startState, action string) {
-	m.transitions.Get(m.getStateByName(startState)).Remove(m.getAction(action))
+	m.removeTransition(m.getStateByName(startState), action)
+}
+
+// Predecessors returns the states with a transition that leads into the provided state, ordered by index.
+func (m *mdp) Predecessors(state State) []State {
+	if state == nil {
+		return nil
+	}
+	seen := make(map[int]bool)
+	var states []State
+	for incoming := range m.predecessors[state.Index()] {
+		if s := m.getStateByIndex(incoming.index); s != nil && !seen[incoming.index] {
+			seen[incoming.index] = true
+			states = append(states, s)
+		}
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Index() < states[j].Index()
+	})
+	return states
+}
+
+// removeTransition removes the transition taken via the provided action name from `state`, keeping the predecessor
+// index up to date. Transitions are looked up by action name, since they may be keyed by any Action object with that name.
+func (m *mdp) removeTransition(state State, action string) {
+	if state == nil {
+		return
+	}
+	entry := m.transitions.Get(state)
+	if entry == nil {
+		return
+	}
+	for _, a := range entry.Actions() {
+		if a.Name() == action {
+			for _, next := range targets(entry.Get(a)) {
+				delete(m.predecessors[next.Index()], incomingTransition{state.Index(), action})
+			}
+			entry.Remove(a)
+		}
+	}
+}
+
+// removeOutcome removes the outcome leading to `target` from the transition taken via the provided action name from
+// `state`. The other outcomes of a distribution are kept, and a transition left with no outcomes is removed.
+func (m *mdp) removeOutcome(state State, action string, target State) {
+	if state == nil {
+		return
+	}
+	entry := m.transitions.Get(state)
+	if entry == nil {
+		return
+	}
+	for _, a := range entry.Actions() {
+		if a.Name() != action {
+			continue
+		}
+		var kept []Transition
+		if d, ok := entry.Get(a).(*distribution); ok {
+			for _, outcome := range d.outcomes {
+				if !outcome.NextState().Equals(target) {
+					kept = append(kept, outcome)
+				}
+			}
+		}
+		if len(kept) == 0 {
+			m.removeTransition(state, action)
+			return
+		}
+		delete(m.predecessors[target.Index()], incomingTransition{state.Index(), action})
+		entry.SetTransition(a, NewDistribution(kept...))
+	}
+}
+
+// indexTransitions adds every transition out of `state` to the predecessor index.
+func (m *mdp) indexTransitions(state State) {
+	entry := m.transitions.Get(state)
+	if entry == nil {
+		return
+	}
+	for _, action := range entry.Actions() {
+		for _, next := range targets(entry.Get(action)) {
+			if _, ok := m.predecessors[next.Index()]; !ok {
+				m.predecessors[next.Index()] = make(map[incomingTransition]bool)
+			}
+			m.predecessors[next.Index()][incomingTransition{state.Index(), action.Name()}] = true
+		}
+	}
+}
+
+// unindexTransitions removes every transition out of `state` from the predecessor index.
+func (m *mdp) unindexTransitions(state State) {
+	entry := m.transitions.Get(state)
+	if entry == nil {
+		return
+	}
+	for _, action := range entry.Actions() {
+		for _, next := range targets(entry.Get(action)) {
+			delete(m.predecessors[next.Index()], incomingTransition{state.Index(), action.Name()})
+		}
+	}
 }
 
 func (m *mdp) String() string {
@@ -90,4 +90,187 @@ func TestDefaultMDP(t *testing.T) {
 	assert.NoError(t, err)
 
 	fmt.Print(mdp.String())
-}
\ No newline at end of file
+}
+
+func TestCosts(t *testing.T) {
+	mdp, err := NewDefaultMDP()
+	assert.NoError(t, err)
+
+	move := NewAction("M")
+	start := NewState("S", 0, false)
+	end := NewState("E", 1, true)
+	assert.NoError(t, mdp.AddStateObject(start, 0, map[Action]Transition{move: NewTransition(1, end)}))
+	assert.NoError(t, mdp.AddStateObject(end, 1, nil))
+
+	assert.NoError(t, mdp.SetCost("fuel", "S", "", 1))
+	assert.NoError(t, mdp.SetCost("fuel", "S", "M", 2))
+	assert.NoError(t, mdp.SetCost("risk", "E", "", 5))
+	assert.Error(t, mdp.SetCost("fuel", "X", "", 1))
+	assert.Error(t, mdp.SetCost("fuel", "S", "X", 1))
+	assert.Error(t, mdp.SetCost("", "S", "", 1))
+
+	assert.Equal(t, []string{"fuel", "risk"}, mdp.CostChannels())
+	assert.Equal(t, float32(1), mdp.C("fuel", "S", ""))
+	assert.Equal(t, float32(3), mdp.C("fuel", "S", "M"))
+	assert.Equal(t, float32(0), mdp.C("risk", "S", "M"))
+	assert.Equal(t, float32(5), mdp.CByIndex("risk", 1, ""))
+
+	assert.NoError(t, mdp.RemoveStateByName("E"))
+	assert.Equal(t, float32(0), mdp.CByIndex("risk", 1, ""))
+}
+
+func TestAnalyze(t *testing.T) {
+	mdp, err := NewDefaultMDP()
+	assert.NoError(t, err)
+
+	next := NewAction("N")
+	back := NewAction("B")
+	start := NewState("start", 0, false)
+	loopA := NewState("loopA", 1, false)
+	loopB := NewState("loopB", 2, false)
+	stuck := NewState("stuck", 3, false)
+	goal := NewState("goal", 4, true)
+	island := NewState("island", 5, false)
+	ghost := NewState("ghost", 6, false)
+
+	assert.NoError(t, mdp.AddStateObject(start, 0, map[Action]Transition{next: NewTransition(1, loopA), back: NewTransition(0.5, goal)}))
+	assert.NoError(t, mdp.AddStateObject(loopA, 0, map[Action]Transition{next: NewTransition(1, loopB)}))
+	assert.NoError(t, mdp.AddStateObject(loopB, 0, map[Action]Transition{next: NewTransition(1, loopA), back: NewTransition(1, stuck)}))
+	assert.NoError(t, mdp.AddStateObject(stuck, 0, nil))
+	assert.NoError(t, mdp.AddStateObject(goal, 1, nil))
+	assert.NoError(t, mdp.AddStateObject(island, 0, map[Action]Transition{next: NewTransition(1, ghost)}))
+
+	a := Analyze(mdp)
+	names := func(states []State) []string {
+		var res []string
+		for _, s := range states {
+			res = append(res, s.Name())
+		}
+		return res
+	}
+
+	assert.Equal(t, []string{"island"}, names(a.Unreachable))
+	assert.Equal(t, []string{"stuck"}, names(a.DeadEnds))
+	assert.Len(t, a.AbsorbingSets, 2)
+	assert.Equal(t, []string{"stuck"}, names(a.AbsorbingSets[0]))
+	assert.Equal(t, []string{"island"}, names(a.AbsorbingSets[1]))
+	assert.Len(t, a.Components, 5)
+	assert.Equal(t, []string{"loopA", "loopB"}, names(a.Components[1]))
+	assert.Equal(t, []string{"loopA", "loopB", "stuck", "island"}, names(a.CannotTerminate))
+	assert.Equal(t, []Action{next}, a.DanglingTransitions[island.Index()])
+}
+
+func TestPredecessors(t *testing.T) {
+	mdp, err := NewDefaultMDP()
+	assert.NoError(t, err)
+
+	left := NewAction("L")
+	right := NewAction("R")
+	a := NewState("A", 0, false)
+	b := NewState("B", 1, false)
+	c := NewState("C", 2, false)
+	assert.NoError(t, mdp.AddStateObject(a, 0, map[Action]Transition{right: NewTransition(1, b)}))
+	assert.NoError(t, mdp.AddStateObject(b, 0, map[Action]Transition{left: NewTransition(1, a), right: NewTransition(1, c)}))
+	assert.NoError(t, mdp.AddStateObject(c, 0, map[Action]Transition{left: NewTransition(1, b), right: NewTransition(1, c)}))
+
+	names := func(states []State) []string {
+		res := []string{}
+		for _, s := range states {
+			res = append(res, s.Name())
+		}
+		return res
+	}
+	assert.Equal(t, []string{"B"}, names(mdp.Predecessors(a)))
+	assert.Equal(t, []string{"A", "C"}, names(mdp.Predecessors(b)))
+	assert.Equal(t, []string{"B", "C"}, names(mdp.Predecessors(c)))
+
+	// The index follows changes to transitions
+	mdp.SetTransition("A", "C", "R", 1)
+	assert.Equal(t, []string{"C"}, names(mdp.Predecessors(b)))
+	assert.Equal(t, []string{"A", "B", "C"}, names(mdp.Predecessors(c)))
+	mdp.RemoveTransition("C", "C")
+	assert.Equal(t, []string{"A", "B"}, names(mdp.Predecessors(c)))
+	mdp.RemoveTransitionByAction("B", "L")
+	assert.Equal(t, []string{}, names(mdp.Predecessors(a)))
+	assert.NoError(t, mdp.SetStateObject(NewState("A2", 0, false), 0, map[Action]Transition{left: NewTransition(1, b)}))
+	assert.Equal(t, []string{"A2", "C"}, names(mdp.Predecessors(b)))
+	assert.Equal(t, []string{"B"}, names(mdp.Predecessors(c)))
+
+	// Removing a state removes every transition into it
+	assert.NoError(t, mdp.RemoveStateByName("B"))
+	assert.Nil(t, mdp.T("A2", "L"))
+	assert.Nil(t, mdp.T("C", "L"))
+	assert.Empty(t, mdp.AvailableActions(mdp.StateByName("A2")))
+	assert.Equal(t, []string{}, names(mdp.Predecessors(c)))
+	assert.Empty(t, Analyze(mdp).DanglingTransitions)
+
+	// Removing an action removes it from the index too
+	mdp.SetTransition("A2", "C", "R", 1)
+	assert.Equal(t, []string{"A2"}, names(mdp.Predecessors(c)))
+	assert.NoError(t, mdp.RemoveAction("R"))
+	assert.Equal(t, []string{}, names(mdp.Predecessors(c)))
+}
+
+func TestDistribution(t *testing.T) {
+	a := NewState("A", 0, false)
+	b := NewState("B", 1, false)
+	c := NewState("C", 2, true)
+	d := NewDistribution(NewTransition(0.2, a), NewTransition(0.5, b), NewTransition(0.1, b), NewTransition(0.5, nil))
+	assert.Equal(t, float32(0.6), d.Probability())
+	assert.Equal(t, "B", d.NextState().Name())
+	assert.Equal(t, "[(0.2000, A), (0.6000, B)]", d.String())
+
+	// The left over mass stays put, merged with any outcome that already does
+	outcomes := Outcomes(a, d)
+	assert.Len(t, outcomes, 2)
+	assert.Equal(t, "A", outcomes[0].NextState().Name())
+	assert.InDelta(t, 0.4, outcomes[0].Probability(), 1e-6)
+	outcomes = Outcomes(c, d)
+	assert.Len(t, outcomes, 3)
+	assert.InDelta(t, 0.2, outcomes[2].Probability(), 1e-6)
+
+	mdp, err := NewDefaultMDP()
+	assert.NoError(t, err)
+	slip := NewAction("slip")
+	assert.NoError(t, mdp.AddStateObject(a, 0, map[Action]Transition{
+		slip: NewDistribution(NewTransition(0.5, b), NewTransition(0.5, c)),
+	}))
+	assert.NoError(t, mdp.AddStateObject(b, 0, nil))
+	assert.NoError(t, mdp.AddStateObject(c, 1, nil))
+	assert.Len(t, Outcomes(a, mdp.T("A", "slip")), 2)
+	assert.Equal(t, []State{a}, mdp.Predecessors(b))
+	assert.Equal(t, []State{a}, mdp.Predecessors(c))
+
+	// Negative probabilities, or probabilities summing to more than 1, are refused
+	for _, invalid := range []Transition{
+		NewDistribution(NewTransition(-0.1, b), NewTransition(0.5, c)),
+		NewDistribution(NewTransition(0.7, b), NewTransition(0.4, c)),
+	} {
+		assert.Error(t, mdp.SetStateObject(a, 0, map[Action]Transition{slip: invalid}))
+		assert.Error(t, mdp.SetState("A", 0, false, 0, map[string]Transition{"slip": invalid}))
+	}
+	assert.Equal(t, "[(0.5000, B), (0.5000, C)]", mdp.T("A", "slip").String())
+
+	// Removing a transition into any outcome removes the whole action
+	mdp.RemoveTransition("A", "C")
+	assert.Nil(t, mdp.T("A", "slip"))
+	assert.Empty(t, mdp.Predecessors(b))
+
+	// Removing a state removes only the outcomes into it, and their probability stays put
+	assert.NoError(t, mdp.SetStateObject(a, 0, map[Action]Transition{
+		slip: NewDistribution(NewTransition(0.5, b), NewTransition(0.3, c)),
+	}))
+	assert.NoError(t, mdp.RemoveStateByName("C"))
+	outcomes = Outcomes(a, mdp.T("A", "slip"))
+	assert.Len(t, outcomes, 2)
+	assert.Equal(t, "B", outcomes[0].NextState().Name())
+	assert.InDelta(t, 0.5, outcomes[0].Probability(), 1e-6)
+	assert.Equal(t, "A", outcomes[1].NextState().Name())
+	assert.InDelta(t, 0.5, outcomes[1].Probability(), 1e-6)
+	assert.Equal(t, []State{a}, mdp.Predecessors(b))
+
+	// The last outcome takes its action with it
+	assert.NoError(t, mdp.RemoveStateByName("B"))
+	assert.Nil(t, mdp.T("A", "slip"))
+	assert.Empty(t, mdp.AvailableActions(a))
+}
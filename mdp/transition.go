@@ -1,9 +1,13 @@
 package mdp
 
 import (
+	"errors"
 	"fmt"
 )
 
+// distributionTolerance is how far above 1 the probabilities of a distribution's outcomes may sum, to allow for rounding.
+const distributionTolerance = 1e-5
+
 type Transition interface {
 	Probability() float32
 	NextState() State
@@ -33,3 +37,149 @@ func (t *transition) NextState() State {
 func (t *transition) String() string {
 	return "(" + fmt.Sprintf("%.4f", t.probability) + ", " + t.nextState.Name() + ")"
 }
+
+// A distribution is a transition with several possible next states.
+type distribution struct {
+	outcomes []Transition
+	// The most likely outcome
+	likeliest Transition
+	// Why the outcomes aren't a valid distribution, or nil if they are
+	err error
+}
+
+// NewDistribution creates a transition that can lead to several next states, each reached with the probability of its
+// outcome, such as a move on slippery ground. Outcomes leading to the same state are merged, and outcomes without a next
+// state are ignored. Any probability mass the outcomes leave over keeps the process in the state the transition is taken
+// from. Probability and NextState describe the most likely outcome; use Outcomes for the full distribution.
+// The probabilities must be non-negative and sum to at most 1; MDPs and environments refuse distributions that fail
+// ValidateTransition.
+func NewDistribution(outcomes ...Transition) Transition {
+	d := &distribution{}
+	total := float32(0)
+	for _, outcome := range outcomes {
+		if outcome == nil || outcome.NextState() == nil {
+			continue
+		}
+		if p := outcome.Probability(); !(p >= 0) {
+			d.err = errors.New("outcome probabilities must be non-negative")
+		}
+		total += outcome.Probability()
+		d.outcomes = merge(d.outcomes, outcome.Probability(), outcome.NextState())
+	}
+	if d.err == nil && total > 1+distributionTolerance {
+		d.err = errors.New("outcome probabilities must sum to at most 1")
+	}
+	for _, outcome := range d.outcomes {
+		if d.likeliest == nil || outcome.Probability() > d.likeliest.Probability() {
+			d.likeliest = outcome
+		}
+	}
+	return d
+}
+
+func (d *distribution) Probability() float32 {
+	if d.likeliest == nil {
+		return 0
+	}
+	return d.likeliest.Probability()
+}
+
+func (d *distribution) NextState() State {
+	if d.likeliest == nil {
+		return nil
+	}
+	return d.likeliest.NextState()
+}
+
+func (d *distribution) String() string {
+	res := "["
+	for i, outcome := range d.outcomes {
+		if i > 0 {
+			res += ", "
+		}
+		res += outcome.String()
+	}
+	return res + "]"
+}
+
+// merge adds an outcome reaching `nextState` with `probability` to `outcomes`, combining it with any outcome that
+// already reaches the same state.
+func merge(outcomes []Transition, probability float32, nextState State) []Transition {
+	for i, outcome := range outcomes {
+		if outcome.NextState().Equals(nextState) {
+			outcomes[i] = NewTransition(outcome.Probability()+probability, nextState)
+			return outcomes
+		}
+	}
+	return append(outcomes, NewTransition(probability, nextState))
+}
+
+// ValidateTransition returns a non-nil error if `transition` is a distribution with a negative probability or with
+// probabilities that sum to more than 1.
+func ValidateTransition(transition Transition) error {
+	if d, ok := transition.(*distribution); ok {
+		return d.err
+	}
+	return nil
+}
+
+// copyTransition returns a transition with the same outcomes as `transition`.
+func copyTransition(transition Transition) Transition {
+	if d, ok := transition.(*distribution); ok {
+		return d
+	}
+	return NewTransition(transition.Probability(), transition.NextState())
+}
+
+// targets returns the next states `transition` can lead to, not counting staying put with the left over probability.
+func targets(transition Transition) []State {
+	if d, ok := transition.(*distribution); ok {
+		states := make([]State, len(d.outcomes))
+		for i, outcome := range d.outcomes {
+			states[i] = outcome.NextState()
+		}
+		return states
+	}
+	if transition == nil || transition.NextState() == nil {
+		return nil
+	}
+	return []State{transition.NextState()}
+}
+
+// Outcomes returns the possible results of following `transition` out of `state`. The transition's next state is reached
+// with the transition's probability, and the remaining probability mass leaves the process in `state`. A transition
+// created by NewDistribution has one result per outcome, plus `state` if its probabilities sum to less than 1.
+func Outcomes(state State, transition Transition) []Transition {
+	if d, ok := transition.(*distribution); ok {
+		// Outcomes were merged on creation, so only the left over mass needs merging
+		outcomes := make([]Transition, 0, len(d.outcomes)+1)
+		remaining := float32(1)
+		for _, outcome := range d.outcomes {
+			if outcome.Probability() > 0 {
+				outcomes = append(outcomes, outcome)
+				remaining -= outcome.Probability()
+			}
+		}
+		if remaining > 1e-6 || len(outcomes) == 0 {
+			outcomes = merge(outcomes, remaining, state)
+		}
+		return outcomes
+	}
+	if transition == nil || transition.NextState() == nil {
+		return []Transition{NewTransition(1, state)}
+	}
+	p := transition.Probability()
+	if p >= 1 || transition.NextState().Equals(state) {
+		return []Transition{NewTransition(1, transition.NextState())}
+	}
+	if p <= 0 {
+		return []Transition{NewTransition(1, state)}
+	}
+	return []Transition{NewTransition(p, transition.NextState()), NewTransition(1-p, state)}
+}
+
+// TransitionRewarder is implemented by MDPs that pay a reward on each transition on top of the reward for being in a
+// state, such as MDPs with reward shaping. Solvers add the expected transition reward to each action's value.
+type TransitionRewarder interface {
+	TransitionReward(state State, nextState State) float32
+}
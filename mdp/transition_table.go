@@ -1,8 +1,12 @@
 package mdp
 
+import "sort"
+
 type TransitionTableEntry interface {
 	Get(Action) Transition
+	Actions() []Action
 	Set(Action, float32, State)
+	SetTransition(Action, Transition)
 	Remove(Action)
 	RemoveTransition(nextState State)
 	String(prefix string) string
@@ -19,18 +23,36 @@ func (t *transitionTableEntry) Get(action Action) Transition {
 	return nil
 }
 
+func (t *transitionTableEntry) Actions() []Action {
+	actions := make([]Action, 0, len(t.entry))
+	for action := range t.entry {
+		actions = append(actions, action)
+	}
+	sort.Slice(actions, func(i, j int) bool {
+		return actions[i].Name() < actions[j].Name()
+	})
+	return actions
+}
+
 func (t *transitionTableEntry) Set(action Action, probability float32, nextState State) {
 	t.entry[action] = NewTransition(probability, nextState)
 }
 
+func (t *transitionTableEntry) SetTransition(action Action, transition Transition) {
+	t.entry[action] = transition
+}
+
 func (t *transitionTableEntry) Remove(action Action) {
 	delete(t.entry, action)
 }
 
 func (t *transitionTableEntry) RemoveTransition(nextState State) {
 	for action, transition := range t.entry {
-		if transition.NextState().Equals(nextState) {
-			t.Remove(action)
+		for _, next := range targets(transition) {
+			if next.Equals(nextState) {
+				t.Remove(action)
+				break
+			}
 		}
 	}
 }
@@ -0,0 +1,34 @@
+package nn
+
+import "math"
+
+// SquaredError returns half the squared difference between `output` and `target`, summed over outputs, and its
+// gradient with respect to the outputs.
+func SquaredError(output []float64, target []float64) (float64, []float64) {
+	loss := 0.0
+	gradient := make([]float64, len(output))
+	for i := range output {
+		d := output[i] - target[i]
+		loss += d * d / 2
+		gradient[i] = d
+	}
+	return loss, gradient
+}
+
+// Huber returns the Huber loss between `output` and `target`, summed over outputs, and its gradient with respect to the
+// outputs. The loss is quadratic for differences up to `delta` and linear beyond, so outliers have bounded gradients.
+func Huber(output []float64, target []float64, delta float64) (float64, []float64) {
+	loss := 0.0
+	gradient := make([]float64, len(output))
+	for i := range output {
+		d := output[i] - target[i]
+		if math.Abs(d) <= delta {
+			loss += d * d / 2
+			gradient[i] = d
+		} else {
+			loss += delta * (math.Abs(d) - delta/2)
+			gradient[i] = math.Copysign(delta, d)
+		}
+	}
+	return loss, gradient
+}
@@ -0,0 +1,272 @@
+package nn
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// An Activation is a nonlinearity applied to each output of a layer.
+type Activation int
+
+const (
+	// Identity leaves its input unchanged.
+	Identity Activation = iota
+	// ReLU clips negative inputs to zero.
+	ReLU
+	// Tanh squashes its input into (-1, 1).
+	Tanh
+)
+
+// apply returns the activation of `x`.
+func (a Activation) apply(x float64) float64 {
+	switch a {
+	case ReLU:
+		return math.Max(0, x)
+	case Tanh:
+		return math.Tanh(x)
+	}
+	return x
+}
+
+// derivative returns the derivative of the activation at the input whose activation is `y`.
+func (a Activation) derivative(y float64) float64 {
+	switch a {
+	case ReLU:
+		if y > 0 {
+			return 1
+		}
+		return 0
+	case Tanh:
+		return 1 - y*y
+	}
+	return 1
+}
+
+// A multilayer perceptron of dense layers. All parameters live in one flat slice, with each layer's weights, row by
+// row, followed by its biases, and their gradients are accumulated in a slice of the same layout. Forward may be
+// called from several goroutines at once, but Backward and Update may not.
+type Network interface {
+	// Sizes returns the number of inputs, the size of each hidden layer and the number of outputs.
+	Sizes() []int
+	// Forward returns the network's outputs for `input`, which must hold one value per input. Forward panics otherwise,
+	// rather than read past or silently ignore part of the input.
+	Forward(input []float64) []float64
+	// Backward adds the gradient of a loss with respect to the parameters to the accumulated gradient, given the input
+	// and the gradient of the loss with respect to the outputs, and returns the gradient with respect to the input. Like
+	// Forward, it panics unless the input holds one value per input and the output gradient one value per output.
+	Backward(input []float64, outputGradient []float64) []float64
+	// Parameters returns the network's parameters, which may be changed in place.
+	Parameters() []float64
+	// Gradients returns the accumulated gradient of each parameter.
+	Gradients() []float64
+	// GradientNorm returns the Euclidean norm of the accumulated gradient.
+	GradientNorm() float64
+	// ClipGradient scales the accumulated gradient down so that its norm is at most `maxNorm`. A negative `maxNorm` is
+	// treated as 0, clearing the gradient.
+	ClipGradient(maxNorm float64)
+	// ZeroGradient clears the accumulated gradient.
+	ZeroGradient()
+	// Update lets `optimizer` change the parameters by the accumulated gradient, then clears it.
+	Update(optimizer Optimizer)
+	// Clone returns a copy of the network with its own parameters and no accumulated gradient.
+	Clone() Network
+	// CopyFrom sets the network's parameters to those of `other`, which must have the same sizes.
+	CopyFrom(other Network) error
+}
+
+type network struct {
+	sizes      []int
+	activation Activation
+	parameters []float64
+	gradients  []float64
+	// The position of each layer's weights in the parameters
+	offsets []int
+}
+
+// NewMLP creates a multilayer perceptron with `sizes[0]` inputs, hidden layers of the sizes in between, and
+// `sizes[len(sizes) - 1]` linear outputs. Hidden layers apply `activation`. Weights are initialized for the activation,
+// He for ReLU and Glorot otherwise, and biases start at zero. `rng` is the source of randomness for initialization.
+func NewMLP(sizes []int, activation Activation, rng *rand.Rand) (Network, error) {
+	if len(sizes) < 2 {
+		return nil, errors.New("sizes must include inputs and outputs")
+	} else if activation < Identity || activation > Tanh {
+		return nil, errors.New("activation must be Identity, ReLU or Tanh")
+	} else if rng == nil {
+		return nil, errors.New("random source must be provided")
+	}
+	for _, size := range sizes {
+		if size <= 0 {
+			return nil, errors.New("sizes must be positive")
+		}
+	}
+	n := newNetwork(sizes, activation)
+	for l := 0; l < n.layers(); l++ {
+		in, out := sizes[l], sizes[l+1]
+		scale := math.Sqrt(2.0 / float64(in+out))
+		if activation == ReLU && l < n.layers()-1 {
+			scale = math.Sqrt(2.0 / float64(in))
+		}
+		weights := n.parameters[n.offsets[l] : n.offsets[l]+in*out]
+		for i := range weights {
+			weights[i] = scale * rng.NormFloat64()
+		}
+	}
+	return n, nil
+}
+
+// newNetwork creates a network of the given sizes with all parameters zero.
+func newNetwork(sizes []int, activation Activation) *network {
+	n := &network{}
+	n.sizes = append([]int{}, sizes...)
+	n.activation = activation
+	count := 0
+	for l := 0; l+1 < len(sizes); l++ {
+		n.offsets = append(n.offsets, count)
+		count += (sizes[l] + 1) * sizes[l+1]
+	}
+	n.parameters = make([]float64, count)
+	n.gradients = make([]float64, count)
+	return n
+}
+
+// layers returns the number of dense layers.
+func (n *network) layers() int {
+	return len(n.sizes) - 1
+}
+
+// layerActivation returns the activation of layer `l`; the output layer is linear.
+func (n *network) layerActivation(l int) Activation {
+	if l == n.layers()-1 {
+		return Identity
+	}
+	return n.activation
+}
+
+// forward returns the outputs of every layer, starting with the input itself.
+func (n *network) forward(input []float64) [][]float64 {
+	if len(input) != n.sizes[0] {
+		panic("nn: input has " + fmt.Sprint(len(input)) + " values, but the network takes " + fmt.Sprint(n.sizes[0]))
+	}
+	outputs := make([][]float64, n.layers()+1)
+	outputs[0] = input
+	for l := 0; l < n.layers(); l++ {
+		in, out := n.sizes[l], n.sizes[l+1]
+		weights := n.parameters[n.offsets[l] : n.offsets[l]+in*out]
+		biases := n.parameters[n.offsets[l]+in*out : n.offsets[l]+(in+1)*out]
+		activation := n.layerActivation(l)
+		x := outputs[l]
+		y := make([]float64, out)
+		for j := range y {
+			sum := biases[j]
+			row := weights[j*in : (j+1)*in]
+			for i, w := range row {
+				sum += w * x[i]
+			}
+			y[j] = activation.apply(sum)
+		}
+		outputs[l+1] = y
+	}
+	return outputs
+}
+
+func (n *network) Sizes() []int {
+	return n.sizes
+}
+
+func (n *network) Forward(input []float64) []float64 {
+	outputs := n.forward(input)
+	return outputs[len(outputs)-1]
+}
+
+func (n *network) Backward(input []float64, outputGradient []float64) []float64 {
+	if outputs := n.sizes[len(n.sizes)-1]; len(outputGradient) != outputs {
+		panic("nn: output gradient has " + fmt.Sprint(len(outputGradient)) + " values, but the network has " +
+			fmt.Sprint(outputs) + " outputs")
+	}
+	outputs := n.forward(input)
+	delta := append([]float64{}, outputGradient...)
+	for l := n.layers() - 1; l >= 0; l-- {
+		in, out := n.sizes[l], n.sizes[l+1]
+		weights := n.parameters[n.offsets[l] : n.offsets[l]+in*out]
+		weightGradients := n.gradients[n.offsets[l] : n.offsets[l]+in*out]
+		biasGradients := n.gradients[n.offsets[l]+in*out : n.offsets[l]+(in+1)*out]
+		activation := n.layerActivation(l)
+		x, y := outputs[l], outputs[l+1]
+
+		previous := make([]float64, in)
+		for j := 0; j < out; j++ {
+			d := delta[j] * activation.derivative(y[j])
+			if d == 0 {
+				continue
+			}
+			biasGradients[j] += d
+			row := weights[j*in : (j+1)*in]
+			rowGradients := weightGradients[j*in : (j+1)*in]
+			for i := range row {
+				rowGradients[i] += d * x[i]
+				previous[i] += d * row[i]
+			}
+		}
+		delta = previous
+	}
+	return delta
+}
+
+func (n *network) Parameters() []float64 {
+	return n.parameters
+}
+
+func (n *network) Gradients() []float64 {
+	return n.gradients
+}
+
+func (n *network) GradientNorm() float64 {
+	total := 0.0
+	for _, g := range n.gradients {
+		total += g * g
+	}
+	return math.Sqrt(total)
+}
+
+func (n *network) ClipGradient(maxNorm float64) {
+	maxNorm = math.Max(0, maxNorm)
+	norm := n.GradientNorm()
+	if norm <= maxNorm || norm == 0 {
+		return
+	}
+	for i := range n.gradients {
+		n.gradients[i] *= maxNorm / norm
+	}
+}
+
+func (n *network) ZeroGradient() {
+	for i := range n.gradients {
+		n.gradients[i] = 0
+	}
+}
+
+func (n *network) Update(optimizer Optimizer) {
+	optimizer.Update(n.parameters, n.gradients)
+	n.ZeroGradient()
+}
+
+func (n *network) Clone() Network {
+	c := newNetwork(n.sizes, n.activation)
+	copy(c.parameters, n.parameters)
+	return c
+}
+
+func (n *network) CopyFrom(other Network) error {
+	if len(other.Sizes()) != len(n.sizes) {
+		return errors.New("networks must have the same sizes")
+	}
+	for i, size := range other.Sizes() {
+		if size != n.sizes[i] {
+			return errors.New("networks must have the same sizes")
+		}
+	}
+	copy(n.parameters, other.Parameters())
+	return nil
+}
@@ -0,0 +1,161 @@
+package nn
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGradientsMatchFiniteDifferences(t *testing.T) {
+	for _, activation := range []Activation{Identity, ReLU, Tanh} {
+		n, err := NewMLP([]int{3, 5, 4, 2}, activation, rand.New(rand.NewSource(1)))
+		assert.NoError(t, err)
+		input := []float64{0.5, -1, 2}
+		target := []float64{1, -1}
+		loss := func() float64 {
+			l, _ := SquaredError(n.Forward(input), target)
+			return l
+		}
+		_, outputGradient := SquaredError(n.Forward(input), target)
+		inputGradient := n.Backward(input, outputGradient)
+
+		epsilon := 1e-6
+		parameters := n.Parameters()
+		for i := range parameters {
+			original := parameters[i]
+			parameters[i] = original + epsilon
+			plus := loss()
+			parameters[i] = original - epsilon
+			minus := loss()
+			parameters[i] = original
+			assert.InDelta(t, (plus-minus)/(2*epsilon), n.Gradients()[i], 1e-5)
+		}
+		for i := range input {
+			original := input[i]
+			input[i] = original + epsilon
+			plus := loss()
+			input[i] = original - epsilon
+			minus := loss()
+			input[i] = original
+			assert.InDelta(t, (plus-minus)/(2*epsilon), inputGradient[i], 1e-5)
+		}
+	}
+}
+
+// train fits `n` to the examples for `epochs` full-batch epochs and returns the final mean loss.
+func train(n Network, optimizer Optimizer, inputs, targets [][]float64, epochs int) float64 {
+	loss := 0.0
+	for epoch := 0; epoch < epochs; epoch++ {
+		loss = 0
+		for i, input := range inputs {
+			l, gradient := SquaredError(n.Forward(input), targets[i])
+			loss += l / float64(len(inputs))
+			for j := range gradient {
+				gradient[j] /= float64(len(inputs))
+			}
+			n.Backward(input, gradient)
+		}
+		n.Update(optimizer)
+	}
+	return loss
+}
+
+func TestLearnsXOR(t *testing.T) {
+	inputs := [][]float64{{0, 0}, {0, 1}, {1, 0}, {1, 1}}
+	targets := [][]float64{{0}, {1}, {1}, {0}}
+	for _, activation := range []Activation{ReLU, Tanh} {
+		n, err := NewMLP([]int{2, 8, 1}, activation, rand.New(rand.NewSource(3)))
+		assert.NoError(t, err)
+		optimizer, err := NewAdam(0.05)
+		assert.NoError(t, err)
+		assert.Less(t, train(n, optimizer, inputs, targets, 500), 1e-3)
+		for i, input := range inputs {
+			assert.InDelta(t, targets[i][0], n.Forward(input)[0], 0.1)
+		}
+	}
+}
+
+func TestSGDFitsALine(t *testing.T) {
+	n, err := NewMLP([]int{1, 1}, Identity, rand.New(rand.NewSource(1)))
+	assert.NoError(t, err)
+	var inputs, targets [][]float64
+	for x := -1.0; x <= 1; x += 0.25 {
+		inputs = append(inputs, []float64{x})
+		targets = append(targets, []float64{3*x - 1})
+	}
+	optimizer, err := NewSGD(0.1, 0.9)
+	assert.NoError(t, err)
+	assert.Less(t, train(n, optimizer, inputs, targets, 300), 1e-6)
+	assert.InDelta(t, 3, n.Parameters()[0], 1e-3)
+	assert.InDelta(t, -1, n.Parameters()[1], 1e-3)
+
+	_, err = NewSGD(0, 0)
+	assert.Error(t, err)
+	_, err = NewSGD(0.1, 1)
+	assert.Error(t, err)
+	_, err = NewAdam(-1)
+	assert.Error(t, err)
+}
+
+func TestCloneAndCopy(t *testing.T) {
+	n, err := NewMLP([]int{2, 3, 1}, Tanh, rand.New(rand.NewSource(1)))
+	assert.NoError(t, err)
+	c := n.Clone()
+	assert.Equal(t, n.Forward([]float64{1, 2}), c.Forward([]float64{1, 2}))
+	n.Parameters()[0] += 1
+	assert.NotEqual(t, n.Forward([]float64{1, 2}), c.Forward([]float64{1, 2}))
+	assert.NoError(t, c.CopyFrom(n))
+	assert.Equal(t, n.Forward([]float64{1, 2}), c.Forward([]float64{1, 2}))
+
+	other, err := NewMLP([]int{2, 4, 1}, Tanh, rand.New(rand.NewSource(1)))
+	assert.NoError(t, err)
+	assert.Error(t, c.CopyFrom(other))
+
+	_, err = NewMLP([]int{2}, Tanh, rand.New(rand.NewSource(1)))
+	assert.Error(t, err)
+	_, err = NewMLP([]int{2, 0, 1}, Tanh, rand.New(rand.NewSource(1)))
+	assert.Error(t, err)
+	_, err = NewMLP([]int{2, 1}, Tanh, nil)
+	assert.Error(t, err)
+}
+
+func TestGradientClipping(t *testing.T) {
+	n, err := NewMLP([]int{1, 1}, Identity, rand.New(rand.NewSource(1)))
+	assert.NoError(t, err)
+	n.Backward([]float64{3}, []float64{4})
+	// The weight's gradient is 12 and the bias's is 4
+	assert.InDelta(t, math.Sqrt(160), n.GradientNorm(), 1e-9)
+	n.ClipGradient(1)
+	assert.InDelta(t, 1, n.GradientNorm(), 1e-9)
+	n.ZeroGradient()
+	assert.Equal(t, 0.0, n.GradientNorm())
+
+	// A negative limit clears the gradient rather than flip its sign
+	n.Backward([]float64{3}, []float64{4})
+	n.ClipGradient(-1)
+	for _, g := range n.Gradients() {
+		assert.Equal(t, 0.0, g)
+	}
+}
+
+func TestInputSizes(t *testing.T) {
+	n, err := NewMLP([]int{2, 3, 1}, Tanh, rand.New(rand.NewSource(1)))
+	assert.NoError(t, err)
+	assert.Len(t, n.Forward([]float64{1, 2}), 1)
+	assert.Panics(t, func() { n.Forward([]float64{1}) })
+	assert.Panics(t, func() { n.Forward([]float64{1, 2, 3}) })
+	assert.Panics(t, func() { n.Backward([]float64{1}, []float64{1}) })
+	assert.Panics(t, func() { n.Backward([]float64{1, 2}, []float64{1, 1}) })
+}
+
+func TestLosses(t *testing.T) {
+	loss, gradient := SquaredError([]float64{1, 2}, []float64{0, 4})
+	assert.Equal(t, 2.5, loss)
+	assert.Equal(t, []float64{1, -2}, gradient)
+
+	loss, gradient = Huber([]float64{0.5, 3, -3}, []float64{0, 0, 0}, 1)
+	assert.Equal(t, 0.125+2.5+2.5, loss)
+	assert.Equal(t, []float64{0.5, 1, -1}, gradient)
+}
@@ -0,0 +1,74 @@
+package nn
+
+import (
+	"errors"
+	"math"
+)
+
+// An optimizer, which changes parameters to descend the gradient of a loss.
+type Optimizer interface {
+	// Update changes `parameters` in place by their `gradients`. Optimizers with per-parameter state expect the same
+	// parameters on every call.
+	Update(parameters []float64, gradients []float64)
+}
+
+type sgd struct {
+	learningRate float64
+	momentum     float64
+	velocity     []float64
+}
+
+// NewSGD creates a stochastic gradient descent optimizer with step size `learningRate` and classical momentum
+// `momentum`, where 0 disables momentum.
+func NewSGD(learningRate float64, momentum float64) (Optimizer, error) {
+	if learningRate <= 0 {
+		return nil, errors.New("learning rate must be positive")
+	} else if momentum < 0 || momentum >= 1 {
+		return nil, errors.New("momentum must be in [0, 1)")
+	}
+	return &sgd{learningRate: learningRate, momentum: momentum}, nil
+}
+
+func (o *sgd) Update(parameters []float64, gradients []float64) {
+	if len(o.velocity) != len(parameters) {
+		o.velocity = make([]float64, len(parameters))
+	}
+	for i, g := range gradients {
+		o.velocity[i] = o.momentum*o.velocity[i] - o.learningRate*g
+		parameters[i] += o.velocity[i]
+	}
+}
+
+type adam struct {
+	learningRate float64
+	beta1, beta2 float64
+	epsilon      float64
+	m, v         []float64
+	t            int
+}
+
+// NewAdam creates an Adam optimizer with step size `learningRate` and the usual decay rates of 0.9 and 0.999 for its
+// moment estimates.
+func NewAdam(learningRate float64) (Optimizer, error) {
+	if learningRate <= 0 {
+		return nil, errors.New("learning rate must be positive")
+	}
+	return &adam{learningRate: learningRate, beta1: 0.9, beta2: 0.999, epsilon: 1e-8}, nil
+}
+
+func (o *adam) Update(parameters []float64, gradients []float64) {
+	if len(o.m) != len(parameters) {
+		o.m = make([]float64, len(parameters))
+		o.v = make([]float64, len(parameters))
+		o.t = 0
+	}
+	o.t++
+	// Correct the bias of moment estimates that start at zero
+	correction1 := 1 - math.Pow(o.beta1, float64(o.t))
+	correction2 := 1 - math.Pow(o.beta2, float64(o.t))
+	for i, g := range gradients {
+		o.m[i] = o.beta1*o.m[i] + (1-o.beta1)*g
+		o.v[i] = o.beta2*o.v[i] + (1-o.beta2)*g*g
+		parameters[i] -= o.learningRate * (o.m[i] / correction1) / (math.Sqrt(o.v[i]/correction2) + o.epsilon)
+	}
+}
@@ -0,0 +1,124 @@
+package pg
+
+import (
+	"errors"
+
+	"github.com/anthonykrivonos/go-rl/env"
+	"github.com/anthonykrivonos/go-rl/mdp"
+	"github.com/anthonykrivonos/go-rl/nn"
+)
+
+// An actor-critic agent, which learns from rollouts of several environments at once.
+type ActorCritic interface {
+	Actor
+	// Critic returns the critic network, which estimates state values.
+	Critic() nn.Network
+	// Train resets `vec` and runs `updates` updates on its environments.
+	// Returns the undiscounted returns of the episodes that ended or were truncated, in the order they did so, and a nil
+	// error on success, or returns nil and a non-nil error on failure.
+	Train(vec env.VecEnv, updates int) ([]float32, error)
+}
+
+type a2c struct {
+	config ActorCriticConfig
+	policy *softmaxPolicy
+	critic nn.Network
+	norms  []float64
+}
+
+// NewA2C creates a synchronous advantage actor-critic (A2C) agent for `actions`, whose policy and critic map the
+// `inputs` values `encoder` gives each state to a logit per action and a state value. Each update takes NSteps steps
+// in every environment, then moves the policy along the mean of A_t ∇ log π(a_t | s_t) + β ∇ H(π(· | s_t)), where A_t
+// is the generalized advantage estimate and H the entropy, and moves the critic towards A_t + V(s_t).
+// Episodes truncated by the environments are bootstrapped from the critic's value of the state they were cut off in.
+func NewA2C(actions []mdp.Action, encoder env.Encoder, inputs int, config ActorCriticConfig) (ActorCritic, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return newA2C(actions, encoder, inputs, config)
+}
+
+// newA2C creates an A2C agent from a valid configuration.
+func newA2C(actions []mdp.Action, encoder env.Encoder, inputs int, config ActorCriticConfig) (*a2c, error) {
+	policy, err := newSoftmaxPolicy(actions, encoder, inputs, config.Hidden, config.Activation, config.Random)
+	if err != nil {
+		return nil, err
+	}
+	l := &a2c{}
+	l.config = config
+	l.policy = policy
+	l.critic, err = nn.NewMLP(append(append([]int{inputs}, config.Hidden...), 1), config.Activation, config.Random)
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *a2c) Act(state mdp.State, actions []mdp.Action) mdp.Action {
+	if l.config.Deterministic {
+		return l.policy.mostProbable(state, actions)
+	}
+	return l.policy.act(state, actions, l.config.Random)
+}
+
+func (l *a2c) Probabilities(state mdp.State, actions []mdp.Action) []float32 {
+	return l.policy.probabilities(state, actions)
+}
+
+func (l *a2c) Policy() nn.Network {
+	return l.policy.network
+}
+
+func (l *a2c) Critic() nn.Network {
+	return l.critic
+}
+
+func (l *a2c) GradientNorms() []float64 {
+	return l.norms
+}
+
+func (l *a2c) Train(vec env.VecEnv, updates int) ([]float32, error) {
+	if vec == nil {
+		return nil, errors.New("vectorized environment must be provided")
+	} else if updates <= 0 {
+		return nil, errors.New("updates must be positive")
+	}
+	c, err := newCollector(vec, l.policy, l.critic, l.config.Random)
+	if err != nil {
+		return nil, err
+	}
+	for u := 0; u < updates; u++ {
+		r, err := c.collect(l.config.NSteps)
+		if err != nil {
+			return nil, err
+		}
+		r.estimateAdvantages(vec.Len(), float64(l.config.DiscountRate), float64(l.config.Lambda))
+		l.update(r)
+	}
+	return c.episodes, nil
+}
+
+// update takes one gradient step on the policy and the critic using every step of the rollout.
+func (l *a2c) update(r *rollout) {
+	scale := 1 / float64(len(r.inputs))
+	for k, input := range r.inputs {
+		if r.available[k] == nil {
+			continue
+		}
+		p := probabilities(l.policy.network.Forward(input), r.available[k])
+		gradient := logProbabilityGradient(p, r.actions[k])
+		_, entropyGradient := entropy(p)
+		// Ascend the objective by descending its negation
+		for i := range gradient {
+			gradient[i] = -(r.advantages[k]*gradient[i] + l.config.EntropyCoefficient*entropyGradient[i]) * scale
+		}
+		l.policy.network.Backward(input, gradient)
+
+		_, valueGradient := nn.SquaredError(l.critic.Forward(input), r.returns[k:k+1])
+		valueGradient[0] *= scale
+		l.critic.Backward(input, valueGradient)
+	}
+	l.norms = append(l.norms, l.policy.network.GradientNorm())
+	descend(l.policy.network, l.config.Optimizer, l.config.MaxGradientNorm)
+	descend(l.critic, l.config.CriticOptimizer, l.config.MaxGradientNorm)
+}
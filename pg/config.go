@@ -0,0 +1,141 @@
+package pg
+
+import (
+	"errors"
+	"math/rand"
+
+	"github.com/anthonykrivonos/go-rl/nn"
+)
+
+// Config holds the settings of a REINFORCE agent.
+type Config struct {
+	// Hidden holds the size of each hidden layer of the policy and baseline networks. Without hidden layers, both are
+	// linear in the encoded state.
+	Hidden []int
+	// Activation is the activation of the hidden layers.
+	Activation nn.Activation
+	// Optimizer trains the policy network.
+	Optimizer nn.Optimizer
+	// BaselineOptimizer trains the state-value baseline, or is nil to learn without a baseline.
+	BaselineOptimizer nn.Optimizer
+	// DiscountRate is the discount rate for learning, ɣ (gamma).
+	DiscountRate float32
+	// NormalizeReturns scales each episode's returns, less the baseline, to zero mean and unit standard deviation.
+	NormalizeReturns bool
+	// MaxGradientNorm clips the norm of each update's gradient, or is 0 to leave gradients unclipped.
+	MaxGradientNorm float64
+	// Random is the source of randomness for network initialization and action sampling.
+	Random *rand.Rand
+}
+
+// validate returns an error describing the first invalid setting, or nil if the configuration is valid.
+func (c Config) validate() error {
+	for _, size := range c.Hidden {
+		if size <= 0 {
+			return errors.New("hidden layer sizes must be positive")
+		}
+	}
+	if c.Optimizer == nil {
+		return errors.New("optimizer must be provided")
+	} else if c.DiscountRate <= 0 || c.DiscountRate > 1.0 {
+		return errors.New("discount rate must be in (0, 1.0]")
+	} else if c.MaxGradientNorm < 0 {
+		return errors.New("max gradient norm must be non-negative")
+	} else if c.Random == nil {
+		return errors.New("random source must be provided")
+	}
+	return nil
+}
+
+// ActorCriticConfig holds the settings of an actor-critic agent.
+type ActorCriticConfig struct {
+	// Hidden holds the size of each hidden layer of the policy and critic networks.
+	Hidden []int
+	// Activation is the activation of the hidden layers.
+	Activation nn.Activation
+	// Optimizer trains the policy network.
+	Optimizer nn.Optimizer
+	// CriticOptimizer trains the critic, which estimates state values.
+	CriticOptimizer nn.Optimizer
+	// DiscountRate is the discount rate for learning, ɣ (gamma).
+	DiscountRate float32
+	// Lambda trades the bias of advantage estimates for their variance, λ (lambda), from one-step TD errors at 0 to
+	// Monte Carlo returns at 1.
+	Lambda float32
+	// NSteps is the number of steps each environment takes between updates.
+	NSteps int
+	// EntropyCoefficient weights the bonus for the policy's entropy, which discourages it from becoming deterministic
+	// too soon.
+	EntropyCoefficient float64
+	// MaxGradientNorm clips the norm of each update's gradient, or is 0 to leave gradients unclipped.
+	MaxGradientNorm float64
+	// Deterministic makes Act take the most probable action rather than sampling one, so that evaluation episodes are
+	// reproducible. Training always samples.
+	Deterministic bool
+	// Random is the source of randomness for network initialization and action sampling.
+	Random *rand.Rand
+}
+
+// validate returns an error describing the first invalid setting, or nil if the configuration is valid.
+func (c ActorCriticConfig) validate() error {
+	for _, size := range c.Hidden {
+		if size <= 0 {
+			return errors.New("hidden layer sizes must be positive")
+		}
+	}
+	if c.Optimizer == nil {
+		return errors.New("optimizer must be provided")
+	} else if c.CriticOptimizer == nil {
+		return errors.New("critic optimizer must be provided")
+	} else if c.DiscountRate <= 0 || c.DiscountRate > 1.0 {
+		return errors.New("discount rate must be in (0, 1.0]")
+	} else if c.Lambda < 0 || c.Lambda > 1.0 {
+		return errors.New("lambda must be in [0, 1.0]")
+	} else if c.NSteps <= 0 {
+		return errors.New("n steps must be positive")
+	} else if c.EntropyCoefficient < 0 {
+		return errors.New("entropy coefficient must be non-negative")
+	} else if c.MaxGradientNorm < 0 {
+		return errors.New("max gradient norm must be non-negative")
+	} else if c.Random == nil {
+		return errors.New("random source must be provided")
+	}
+	return nil
+}
+
+// PPOConfig holds the settings of a PPO agent.
+type PPOConfig struct {
+	ActorCriticConfig
+	// ClipRange bounds how far each update may move the probability ratio π(a | s) / π_old(a | s) from 1, ε (epsilon).
+	ClipRange float64
+	// ValueClipRange bounds how far each update may move the critic's values from those of the rollout, or is 0 to
+	// leave them unclipped.
+	ValueClipRange float64
+	// Epochs is the number of passes over each rollout.
+	Epochs int
+	// MinibatchSize is the number of steps in each gradient step.
+	MinibatchSize int
+	// TargetKL stops the passes over a rollout once the approximate KL divergence of the policy from the rollout's
+	// exceeds 1.5 times it, or is 0 to always finish them.
+	TargetKL float64
+	// NormalizeAdvantages scales each minibatch's advantages to zero mean and unit standard deviation.
+	NormalizeAdvantages bool
+}
+
+// validate returns an error describing the first invalid setting, or nil if the configuration is valid.
+func (c PPOConfig) validate() error {
+	if err := c.ActorCriticConfig.validate(); err != nil {
+		return err
+	} else if c.ClipRange <= 0 {
+		return errors.New("clip range must be positive")
+	} else if c.ValueClipRange < 0 {
+		return errors.New("value clip range must be non-negative")
+	} else if c.Epochs <= 0 {
+		return errors.New("epochs must be positive")
+	} else if c.MinibatchSize <= 0 {
+		return errors.New("minibatch size must be positive")
+	} else if c.TargetKL < 0 {
+		return errors.New("target kl must be non-negative")
+	}
+	return nil
+}
@@ -0,0 +1,374 @@
+package pg
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/anthonykrivonos/go-rl/classic"
+	"github.com/anthonykrivonos/go-rl/env"
+	"github.com/anthonykrivonos/go-rl/mdp"
+	"github.com/anthonykrivonos/go-rl/nn"
+	"github.com/anthonykrivonos/go-rl/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func mean(values []float32) float32 {
+	total := float32(0)
+	for _, v := range values {
+		total += v
+	}
+	return total / float32(len(values))
+}
+
+func newReinforceConfig(t *testing.T, hidden []int, baseline bool, seed int64) Config {
+	optimizer, err := nn.NewAdam(0.01)
+	assert.NoError(t, err)
+	config := Config{
+		Hidden:           hidden,
+		Activation:       nn.Tanh,
+		Optimizer:        optimizer,
+		DiscountRate:     0.99,
+		NormalizeReturns: true,
+		Random:           rand.New(rand.NewSource(seed)),
+	}
+	if baseline {
+		config.BaselineOptimizer, err = nn.NewAdam(0.01)
+		assert.NoError(t, err)
+	}
+	return config
+}
+
+func TestProbabilities(t *testing.T) {
+	p := probabilities([]float64{1, 2, 3}, []int{0, 2})
+	assert.InDelta(t, 1/(1+math.E*math.E), p[0], 1e-9)
+	assert.Equal(t, 0.0, p[1])
+	assert.InDelta(t, 1-p[0], p[2], 1e-9)
+
+	// Large logits don't overflow
+	p = probabilities([]float64{1000, 1000}, []int{0, 1})
+	assert.InDelta(t, 0.5, p[0], 1e-9)
+
+	gradient := logProbabilityGradient([]float64{0.25, 0.75}, 1)
+	assert.InDeltaSlice(t, []float64{-0.25, 0.25}, gradient, 1e-9)
+}
+
+func TestReinforceValidation(t *testing.T) {
+	actions := []mdp.Action{mdp.NewAction("left"), mdp.NewAction("right")}
+	_, err := NewReinforce(actions, env.EncodeVector, 4, newReinforceConfig(t, nil, false, 1))
+	assert.NoError(t, err)
+	_, err = NewReinforce(nil, env.EncodeVector, 4, newReinforceConfig(t, nil, false, 1))
+	assert.Error(t, err)
+	_, err = NewReinforce(actions, nil, 4, newReinforceConfig(t, nil, false, 1))
+	assert.Error(t, err)
+	_, err = NewReinforce(actions, env.EncodeVector, 0, newReinforceConfig(t, nil, false, 1))
+	assert.Error(t, err)
+
+	for _, change := range []func(c *Config){
+		func(c *Config) { c.Hidden = []int{0} },
+		func(c *Config) { c.Optimizer = nil },
+		func(c *Config) { c.DiscountRate = 0 },
+		func(c *Config) { c.MaxGradientNorm = -1 },
+		func(c *Config) { c.Random = nil },
+	} {
+		config := newReinforceConfig(t, nil, false, 1)
+		change(&config)
+		_, err = NewReinforce(actions, env.EncodeVector, 4, config)
+		assert.Error(t, err)
+	}
+}
+
+func TestReinforceRespectsAvailableActions(t *testing.T) {
+	actions := []mdp.Action{mdp.NewAction("a"), mdp.NewAction("b"), mdp.NewAction("c")}
+	l, err := NewReinforce(actions, env.EncodeVector, 2, newReinforceConfig(t, []int{4}, false, 1))
+	assert.NoError(t, err)
+	state := env.NewObservation([]float64{1, -1}, false)
+	available := []mdp.Action{actions[0], actions[2]}
+	for i := 0; i < 100; i++ {
+		assert.NotEqual(t, "b", l.Act(state, available).Name())
+	}
+	p := l.Probabilities(state, available)
+	assert.InDelta(t, 1, p[0]+p[1], 1e-6)
+}
+
+func TestReinforceCartPole(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		hidden   []int
+		baseline bool
+	}{
+		{"linear", nil, false},
+		{"linear with baseline", nil, true},
+		{"mlp with baseline", []int{16}, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			e, err := classic.NewCartPole(rand.New(rand.NewSource(1)))
+			assert.NoError(t, err)
+			actions := []mdp.Action{mdp.NewAction("left"), mdp.NewAction("right")}
+			l, err := NewReinforce(actions, env.EncodeVector, 4, newReinforceConfig(t, test.hidden, test.baseline, 1))
+			assert.NoError(t, err)
+			assert.Equal(t, test.baseline, l.Baseline() != nil)
+
+			returns, err := env.Train(e, l, 1000, 500)
+			assert.NoError(t, err)
+			assert.Len(t, l.GradientNorms(), 1000)
+			assert.Greater(t, mean(returns[900:]), float32(150))
+			assert.Greater(t, mean(returns[900:]), 3*mean(returns[:50]))
+		})
+	}
+}
+
+func TestEntropy(t *testing.T) {
+	h, _ := entropy([]float64{0.5, 0.5, 0})
+	assert.InDelta(t, math.Log(2), h, 1e-9)
+
+	// The gradient matches finite differences of the entropy of the softmax of the logits
+	logits := []float64{0.3, -1.2, 0.8}
+	all := []int{0, 1, 2}
+	_, gradient := entropy(probabilities(logits, all))
+	for i := range logits {
+		shifted := append([]float64{}, logits...)
+		shifted[i] += 1e-6
+		up, _ := entropy(probabilities(shifted, all))
+		shifted[i] -= 2e-6
+		down, _ := entropy(probabilities(shifted, all))
+		assert.InDelta(t, (up-down)/2e-6, gradient[i], 1e-6)
+	}
+}
+
+func TestGAE(t *testing.T) {
+	rewards := []float64{1, 1, 1, 1}
+	values := []float64{0.5, 0.5, 0.5, 0.5}
+	nextValues := []float64{0.5, 0, 0.5, 2}
+	ends := []bool{false, true, false, false}
+
+	// With λ = 0, advantages are one-step TD errors
+	assert.InDeltaSlice(t, []float64{0.95, 0.5, 0.95, 2.3}, gae(rewards, values, nextValues, ends, 0.9, 0), 1e-9)
+
+	// With λ = 1, advantages are discounted returns less the value, cut at the end of the episode and bootstrapped at
+	// the end of the rollout
+	assert.InDeltaSlice(t, []float64{1 + 0.9 - 0.5, 0.5, 1 + 0.9*(1+0.9*2) - 0.5, 1 + 0.9*2 - 0.5},
+		gae(rewards, values, nextValues, ends, 0.9, 1), 1e-9)
+}
+
+func newActorCriticConfig(t *testing.T, seed int64) ActorCriticConfig {
+	optimizer, err := nn.NewAdam(0.001)
+	assert.NoError(t, err)
+	criticOptimizer, err := nn.NewAdam(0.005)
+	assert.NoError(t, err)
+	return ActorCriticConfig{
+		Hidden:             []int{32},
+		Activation:         nn.Tanh,
+		Optimizer:          optimizer,
+		CriticOptimizer:    criticOptimizer,
+		DiscountRate:       0.99,
+		Lambda:             0.95,
+		NSteps:             16,
+		EntropyCoefficient: 0.01,
+		MaxGradientNorm:    1,
+		Random:             rand.New(rand.NewSource(seed)),
+	}
+}
+
+func newCartPoles(t *testing.T, n int, seed int64) env.VecEnv {
+	envs := make([]env.Environment, n)
+	for i := range envs {
+		e, err := classic.NewCartPole(rand.New(rand.NewSource(seed + int64(i))))
+		assert.NoError(t, err)
+		envs[i] = e
+	}
+	vec, err := env.NewVecEnv(envs, 500)
+	assert.NoError(t, err)
+	return vec
+}
+
+// evaluate returns the mean undiscounted return of `actor` over `episodes` CartPole episodes.
+func evaluate(t *testing.T, actor Actor, episodes int) float32 {
+	e, err := classic.NewCartPole(rand.New(rand.NewSource(101)))
+	assert.NoError(t, err)
+	returns, err := env.Train(e, &follower{actor}, episodes, 500)
+	assert.NoError(t, err)
+	return mean(returns)
+}
+
+// follower acts by an actor without learning.
+type follower struct {
+	Actor
+}
+
+func (f *follower) Learn(env.Step, []mdp.Action) {}
+
+func (f *follower) EndEpisode() {}
+
+func TestA2CValidation(t *testing.T) {
+	actions := []mdp.Action{mdp.NewAction("left"), mdp.NewAction("right")}
+	_, err := NewA2C(actions, env.EncodeVector, 4, newActorCriticConfig(t, 1))
+	assert.NoError(t, err)
+	_, err = NewA2C(nil, env.EncodeVector, 4, newActorCriticConfig(t, 1))
+	assert.Error(t, err)
+
+	for _, change := range []func(c *ActorCriticConfig){
+		func(c *ActorCriticConfig) { c.CriticOptimizer = nil },
+		func(c *ActorCriticConfig) { c.Lambda = 1.5 },
+		func(c *ActorCriticConfig) { c.NSteps = 0 },
+		func(c *ActorCriticConfig) { c.EntropyCoefficient = -1 },
+		func(c *ActorCriticConfig) { c.Random = nil },
+	} {
+		config := newActorCriticConfig(t, 1)
+		change(&config)
+		_, err = NewA2C(actions, env.EncodeVector, 4, config)
+		assert.Error(t, err)
+	}
+
+	l, err := NewA2C(actions, env.EncodeVector, 4, newActorCriticConfig(t, 1))
+	assert.NoError(t, err)
+	_, err = l.Train(nil, 1)
+	assert.Error(t, err)
+	_, err = l.Train(newCartPoles(t, 2, 1), 0)
+	assert.Error(t, err)
+}
+
+func TestA2CCartPole(t *testing.T) {
+	actions := []mdp.Action{mdp.NewAction("left"), mdp.NewAction("right")}
+	config := newActorCriticConfig(t, 1)
+	config.Deterministic = true
+	l, err := NewA2C(actions, env.EncodeVector, 4, config)
+	assert.NoError(t, err)
+
+	before := evaluate(t, l, 5)
+	_, err = l.Train(newCartPoles(t, 8, 1), 1500)
+	assert.NoError(t, err)
+	assert.Len(t, l.GradientNorms(), 1500)
+	after := evaluate(t, l, 5)
+	assert.Greater(t, after, float32(195))
+	assert.Greater(t, after, before)
+}
+
+func TestA2CIsReproducible(t *testing.T) {
+	actions := []mdp.Action{mdp.NewAction("left"), mdp.NewAction("right")}
+	// Trains on CartPoles with every source of randomness derived from a run-level seed
+	run := func(seed int64, async bool) ([]float32, []float64) {
+		seeds := utils.NewSeeds(seed)
+		var envs []env.Environment
+		for _, rng := range seeds.Randoms("env", 4) {
+			e, err := classic.NewCartPole(rng)
+			assert.NoError(t, err)
+			envs = append(envs, e)
+		}
+		var vec env.VecEnv
+		var err error
+		if async {
+			a, err := env.NewAsyncVecEnv(envs, 500)
+			assert.NoError(t, err)
+			defer a.Close()
+			vec = a
+		} else {
+			vec, err = env.NewVecEnv(envs, 500)
+			assert.NoError(t, err)
+		}
+		config := newActorCriticConfig(t, 0)
+		config.Random = seeds.Random("agent")
+		l, err := NewA2C(actions, env.EncodeVector, 4, config)
+		assert.NoError(t, err)
+		returns, err := l.Train(vec, 50)
+		assert.NoError(t, err)
+		return returns, l.Policy().Parameters()
+	}
+	returns, parameters := run(7, false)
+	assert.NotEmpty(t, returns)
+	for _, async := range []bool{false, true} {
+		otherReturns, otherParameters := run(7, async)
+		assert.Equal(t, returns, otherReturns)
+		assert.Equal(t, parameters, otherParameters)
+	}
+	differentReturns, _ := run(8, false)
+	assert.NotEqual(t, returns, differentReturns)
+}
+
+func newPPOConfig(t *testing.T, seed int64) PPOConfig {
+	config := newActorCriticConfig(t, seed)
+	var err error
+	config.Optimizer, err = nn.NewAdam(0.001)
+	assert.NoError(t, err)
+	config.CriticOptimizer, err = nn.NewAdam(0.001)
+	assert.NoError(t, err)
+	config.NSteps = 64
+	config.MaxGradientNorm = 0.5
+	return PPOConfig{
+		ActorCriticConfig:   config,
+		ClipRange:           0.2,
+		ValueClipRange:      10,
+		Epochs:              4,
+		MinibatchSize:       64,
+		TargetKL:            0.02,
+		NormalizeAdvantages: true,
+	}
+}
+
+func TestPPOValidation(t *testing.T) {
+	actions := []mdp.Action{mdp.NewAction("left"), mdp.NewAction("right")}
+	_, err := NewPPO(actions, env.EncodeVector, 4, newPPOConfig(t, 1))
+	assert.NoError(t, err)
+	for _, change := range []func(c *PPOConfig){
+		func(c *PPOConfig) { c.DiscountRate = 0 },
+		func(c *PPOConfig) { c.ClipRange = 0 },
+		func(c *PPOConfig) { c.ValueClipRange = -1 },
+		func(c *PPOConfig) { c.Epochs = 0 },
+		func(c *PPOConfig) { c.MinibatchSize = 0 },
+		func(c *PPOConfig) { c.TargetKL = -1 },
+	} {
+		config := newPPOConfig(t, 1)
+		change(&config)
+		_, err = NewPPO(actions, env.EncodeVector, 4, config)
+		assert.Error(t, err)
+	}
+}
+
+func TestPPOValueClipping(t *testing.T) {
+	l := &ppo{config: PPOConfig{ValueClipRange: 0.5}}
+	// Within the clip range, the value moves freely
+	assert.InDelta(t, -0.8, l.valueGradient(1.2, 1, 2), 1e-9)
+	// Past the clip range, the clipped value's larger error has no gradient
+	assert.Equal(t, 0.0, l.valueGradient(1.8, 1, 3))
+	// Past the clip range but overshooting the target, the unclipped value's larger error still applies
+	assert.InDelta(t, 0.8, l.valueGradient(2, 1, 1.2), 1e-9)
+	l.config.ValueClipRange = 0
+	assert.InDelta(t, -1.2, l.valueGradient(1.8, 1, 3), 1e-9)
+}
+
+func TestPPOEarlyStop(t *testing.T) {
+	actions := []mdp.Action{mdp.NewAction("left"), mdp.NewAction("right")}
+	config := newPPOConfig(t, 1)
+	var err error
+	config.Optimizer, err = nn.NewSGD(1, 0)
+	assert.NoError(t, err)
+	config.TargetKL = 1e-6
+	l, err := NewPPO(actions, env.EncodeVector, 4, config)
+	assert.NoError(t, err)
+	_, err = l.Train(newCartPoles(t, 2, 1), 5)
+	assert.NoError(t, err)
+
+	// Each update takes at least its first step, as the policy matches the rollout's, and stops before finishing
+	assert.Len(t, l.KLs(), 5)
+	assert.GreaterOrEqual(t, len(l.GradientNorms()), 5)
+	assert.Less(t, len(l.GradientNorms()), 5*config.Epochs*2)
+	for _, kl := range l.KLs() {
+		assert.Greater(t, kl, 1.5e-6)
+	}
+}
+
+func TestPPOCartPole(t *testing.T) {
+	actions := []mdp.Action{mdp.NewAction("left"), mdp.NewAction("right")}
+	config := newPPOConfig(t, 1)
+	config.Deterministic = true
+	l, err := NewPPO(actions, env.EncodeVector, 4, config)
+	assert.NoError(t, err)
+
+	_, err = l.Train(newCartPoles(t, 8, 1), 150)
+	assert.NoError(t, err)
+	after := evaluate(t, l, 5)
+	assert.Greater(t, after, float32(195))
+	for _, kl := range l.KLs() {
+		assert.GreaterOrEqual(t, kl, 0.0)
+	}
+}
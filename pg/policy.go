@@ -0,0 +1,138 @@
+package pg
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+
+	"github.com/anthonykrivonos/go-rl/env"
+	"github.com/anthonykrivonos/go-rl/mdp"
+	"github.com/anthonykrivonos/go-rl/nn"
+	"github.com/anthonykrivonos/go-rl/utils"
+)
+
+// softmaxPolicy is a stochastic policy over a fixed set of actions, whose network maps encoded states to one logit per
+// action. Actions that aren't available are given no probability.
+type softmaxPolicy struct {
+	actions []mdp.Action
+	encoder env.Encoder
+	network nn.Network
+}
+
+// newSoftmaxPolicy creates a softmax policy whose network has `inputs` inputs, hidden layers of sizes `hidden` with
+// activation `activation`, and one output per action. Without hidden layers, the policy is linear in the inputs.
+func newSoftmaxPolicy(actions []mdp.Action, encoder env.Encoder, inputs int, hidden []int, activation nn.Activation, rng *rand.Rand) (*softmaxPolicy, error) {
+	if len(actions) == 0 {
+		return nil, errors.New("at least one action must be provided")
+	} else if encoder == nil {
+		return nil, errors.New("encoder must be provided")
+	} else if inputs <= 0 {
+		return nil, errors.New("inputs must be positive")
+	}
+	network, err := nn.NewMLP(append(append([]int{inputs}, hidden...), len(actions)), activation, rng)
+	if err != nil {
+		return nil, err
+	}
+	return &softmaxPolicy{actions, encoder, network}, nil
+}
+
+// positions returns the output of each of `actions`, skipping any the policy doesn't know.
+func (p *softmaxPolicy) positions(actions []mdp.Action) []int {
+	var positions []int
+	for _, action := range actions {
+		for i, a := range p.actions {
+			if a.Name() == action.Name() {
+				positions = append(positions, i)
+				break
+			}
+		}
+	}
+	return positions
+}
+
+// probabilities returns the probability of each action given its logits, where only the actions at `available` may be
+// taken.
+func probabilities(logits []float64, available []int) []float64 {
+	probabilities := make([]float64, len(logits))
+	values := make([]float64, len(available))
+	for j, i := range available {
+		values[j] = logits[i]
+	}
+	for j, p := range utils.Softmax(values, 1) {
+		probabilities[available[j]] = p
+	}
+	return probabilities
+}
+
+// act returns an action drawn from the policy among the available `actions`, or the first if the policy knows none of
+// them.
+func (p *softmaxPolicy) act(state mdp.State, actions []mdp.Action, rng *rand.Rand) mdp.Action {
+	available := p.positions(actions)
+	if len(available) == 0 {
+		return actions[0]
+	}
+	return p.actions[utils.SampleCategorical(probabilities(p.network.Forward(p.encoder(state)), available), rng)]
+}
+
+// probabilities returns the probability of taking each of `actions` in `state`.
+func (p *softmaxPolicy) probabilities(state mdp.State, actions []mdp.Action) []float32 {
+	all := probabilities(p.network.Forward(p.encoder(state)), p.positions(actions))
+	res := make([]float32, len(actions))
+	for i, action := range actions {
+		if position := p.positions([]mdp.Action{action}); len(position) > 0 {
+			res[i] = float32(all[position[0]])
+		}
+	}
+	return res
+}
+
+// mostProbable returns the most probable of the available `actions`, or the first if the policy knows none of them.
+func (p *softmaxPolicy) mostProbable(state mdp.State, actions []mdp.Action) mdp.Action {
+	available := p.positions(actions)
+	if len(available) == 0 {
+		return actions[0]
+	}
+	logits := p.network.Forward(p.encoder(state))
+	values := make([]float64, len(available))
+	for j, i := range available {
+		values[j] = logits[i]
+	}
+	return p.actions[available[utils.Argmax(values, nil)]]
+}
+
+// logProbabilityGradient returns the gradient of log π(a | s) with respect to the logits, 1[b = a] - π(b | s) for each
+// action b, where a is at position `action`.
+func logProbabilityGradient(probabilities []float64, action int) []float64 {
+	gradient := make([]float64, len(probabilities))
+	for i, p := range probabilities {
+		gradient[i] = -p
+	}
+	gradient[action]++
+	return gradient
+}
+
+// entropy returns the entropy of `probabilities` and its gradient with respect to the logits.
+func entropy(probabilities []float64) (float64, []float64) {
+	h := 0.0
+	for _, p := range probabilities {
+		if p > 0 {
+			h -= p * math.Log(p)
+		}
+	}
+	gradient := make([]float64, len(probabilities))
+	for i, p := range probabilities {
+		if p > 0 {
+			gradient[i] = -p * (math.Log(p) + h)
+		}
+	}
+	return h, gradient
+}
+
+// descend clips the accumulated gradient of `network` to `maxGradientNorm`, unless it is 0, and lets `optimizer` apply
+// it.
+func descend(network nn.Network, optimizer nn.Optimizer, maxGradientNorm float64) {
+	if maxGradientNorm > 0 {
+		network.ClipGradient(maxGradientNorm)
+	}
+	network.Update(optimizer)
+}
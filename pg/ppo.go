@@ -0,0 +1,165 @@
+package pg
+
+import (
+	"errors"
+	"math"
+
+	"github.com/anthonykrivonos/go-rl/env"
+	"github.com/anthonykrivonos/go-rl/mdp"
+)
+
+// A Proximal Policy Optimization agent.
+type PPO interface {
+	ActorCritic
+	// KLs returns the approximate KL divergence of the policy from the rollout's policy at the end of each update.
+	KLs() []float64
+}
+
+type ppo struct {
+	*a2c
+	config PPOConfig
+	kls    []float64
+}
+
+// NewPPO creates a Proximal Policy Optimization (PPO) agent for `actions`, whose policy and critic map the `inputs`
+// values `encoder` gives each state to a logit per action and a state value. Each update takes NSteps steps in every
+// environment and estimates advantages A_t as A2C does, then takes gradient steps on shuffled minibatches of the
+// rollout for several epochs. The policy maximizes the clipped surrogate min(ρ_t A_t, clip(ρ_t, 1 - ε, 1 + ε) A_t) plus
+// the entropy bonus, where ρ_t = π(a_t | s_t) / π_old(a_t | s_t), and the critic minimizes the squared error from
+// A_t + V_old(s_t), taking the worse of its unclipped and clipped values if value clipping is on. An update stops
+// early once a minibatch's approximate KL divergence exceeds 1.5 times TargetKL, without stepping on it.
+func NewPPO(actions []mdp.Action, encoder env.Encoder, inputs int, config PPOConfig) (PPO, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	a, err := newA2C(actions, encoder, inputs, config.ActorCriticConfig)
+	if err != nil {
+		return nil, err
+	}
+	l := &ppo{}
+	l.a2c = a
+	l.config = config
+	return l, nil
+}
+
+func (l *ppo) KLs() []float64 {
+	return l.kls
+}
+
+func (l *ppo) Train(vec env.VecEnv, updates int) ([]float32, error) {
+	if vec == nil {
+		return nil, errors.New("vectorized environment must be provided")
+	} else if updates <= 0 {
+		return nil, errors.New("updates must be positive")
+	}
+	c, err := newCollector(vec, l.policy, l.critic, l.config.Random)
+	if err != nil {
+		return nil, err
+	}
+	for u := 0; u < updates; u++ {
+		r, err := c.collect(l.config.NSteps)
+		if err != nil {
+			return nil, err
+		}
+		r.estimateAdvantages(vec.Len(), float64(l.config.DiscountRate), float64(l.config.Lambda))
+		l.update(r)
+	}
+	return c.episodes, nil
+}
+
+// update takes minibatch gradient steps on the policy and the critic for each epoch over the rollout, unless the policy
+// moves too far from the rollout's.
+func (l *ppo) update(r *rollout) {
+	var steps []int
+	for k := range r.inputs {
+		if r.available[k] != nil {
+			steps = append(steps, k)
+		}
+	}
+	kl := 0.0
+	for epoch := 0; epoch < l.config.Epochs; epoch++ {
+		l.config.Random.Shuffle(len(steps), func(i, j int) {
+			steps[i], steps[j] = steps[j], steps[i]
+		})
+		for start := 0; start < len(steps); start += l.config.MinibatchSize {
+			end := start + l.config.MinibatchSize
+			if end > len(steps) {
+				end = len(steps)
+			}
+			kl = l.step(r, steps[start:end])
+			if l.config.TargetKL > 0 && kl > 1.5*l.config.TargetKL {
+				l.kls = append(l.kls, kl)
+				return
+			}
+		}
+	}
+	l.kls = append(l.kls, kl)
+}
+
+// step accumulates the gradients of a minibatch of the rollout's steps and, unless the policy has moved too far from
+// the rollout's, applies them.
+// Returns the approximate KL divergence of the policy from the rollout's on the minibatch, before the step.
+func (l *ppo) step(r *rollout, minibatch []int) float64 {
+	advantages := make([]float64, len(minibatch))
+	for i, k := range minibatch {
+		advantages[i] = r.advantages[k]
+	}
+	if l.config.NormalizeAdvantages && len(advantages) > 1 {
+		normalize(advantages)
+	}
+
+	scale := 1 / float64(len(minibatch))
+	kl := 0.0
+	for i, k := range minibatch {
+		input := r.inputs[k]
+		p := probabilities(l.policy.network.Forward(input), r.available[k])
+		ratio := p[r.actions[k]] / r.probabilities[k]
+		// An estimate of KL(π_old || π) that is never negative
+		kl += ((ratio - 1) - math.Log(ratio)) * scale
+
+		// The surrogate's gradient vanishes where clipping makes it flat
+		gradient := make([]float64, len(p))
+		clipped := math.Max(1-l.config.ClipRange, math.Min(ratio, 1+l.config.ClipRange))
+		if ratio*advantages[i] <= clipped*advantages[i] {
+			gradient = logProbabilityGradient(p, r.actions[k])
+			for j := range gradient {
+				gradient[j] *= ratio * advantages[i]
+			}
+		}
+		_, entropyGradient := entropy(p)
+		// Ascend the objective by descending its negation
+		for j := range gradient {
+			gradient[j] = -(gradient[j] + l.config.EntropyCoefficient*entropyGradient[j]) * scale
+		}
+		l.policy.network.Backward(input, gradient)
+
+		l.critic.Backward(input, []float64{l.valueGradient(l.critic.Forward(input)[0], r.values[k], r.returns[k]) * scale})
+	}
+	if l.config.TargetKL > 0 && kl > 1.5*l.config.TargetKL {
+		l.policy.network.ZeroGradient()
+		l.critic.ZeroGradient()
+		return kl
+	}
+	l.norms = append(l.norms, l.policy.network.GradientNorm())
+	descend(l.policy.network, l.config.Optimizer, l.config.MaxGradientNorm)
+	descend(l.critic, l.config.CriticOptimizer, l.config.MaxGradientNorm)
+	return kl
+}
+
+// valueGradient returns the gradient of the critic's loss with respect to its value `value` of a state whose value in
+// the rollout was `old`, given the return `target`. With value clipping, the loss is the larger of the squared errors of
+// the value and of the value clipped to within ValueClipRange of `old`.
+func (l *ppo) valueGradient(value, old, target float64) float64 {
+	if l.config.ValueClipRange == 0 {
+		return value - target
+	}
+	clipped := old + math.Max(-l.config.ValueClipRange, math.Min(value-old, l.config.ValueClipRange))
+	if math.Abs(clipped-target) > math.Abs(value-target) {
+		// The clipped value is constant in the value wherever clipping is active
+		if clipped != value {
+			return 0
+		}
+		return clipped - target
+	}
+	return value - target
+}
@@ -0,0 +1,173 @@
+package pg
+
+import (
+	"math"
+
+	"github.com/anthonykrivonos/go-rl/env"
+	"github.com/anthonykrivonos/go-rl/mdp"
+	"github.com/anthonykrivonos/go-rl/nn"
+)
+
+// An Actor follows a learned softmax policy over discrete actions.
+type Actor interface {
+	// Act chooses an action to take in `state` from the available `actions`.
+	Act(state mdp.State, actions []mdp.Action) mdp.Action
+	// Probabilities returns the probability of taking each of `actions` in `state`.
+	Probabilities(state mdp.State, actions []mdp.Action) []float32
+	// Policy returns the policy network, with one logit per action.
+	Policy() nn.Network
+	// GradientNorms returns the norm of the policy gradient of each update so far, before clipping.
+	GradientNorms() []float64
+}
+
+// A policy gradient agent that learns from the episodes it takes part in.
+type Learner interface {
+	env.Agent
+	Actor
+}
+
+// A REINFORCE agent, whose baseline is available if it learns one.
+type Reinforce interface {
+	Learner
+	// Baseline returns the state-value baseline network, or nil if there is none.
+	Baseline() nn.Network
+}
+
+// visit is a step of the current episode, along with the actions that were available.
+type visit struct {
+	step      env.Step
+	available []int
+}
+
+type reinforce struct {
+	config    Config
+	policy    *softmaxPolicy
+	baseline  nn.Network
+	available []int
+	episode   []visit
+	norms     []float64
+}
+
+// NewReinforce creates a Monte Carlo policy gradient (REINFORCE) agent for `actions`, whose softmax policy maps the
+// `inputs` values `encoder` gives each state to a logit per action. At the end of each episode it moves the policy
+// along Σ_t (G_t - b(s_t)) ∇ log π(a_t | s_t) / T, where G_t is the discounted return from step t and b is a learned
+// state-value baseline, or 0 without one. The baseline is moved towards G_t under the squared error.
+// Truncated episodes are learned from as if they had ended.
+func NewReinforce(actions []mdp.Action, encoder env.Encoder, inputs int, config Config) (Reinforce, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	policy, err := newSoftmaxPolicy(actions, encoder, inputs, config.Hidden, config.Activation, config.Random)
+	if err != nil {
+		return nil, err
+	}
+	l := &reinforce{}
+	l.config = config
+	l.policy = policy
+	if config.BaselineOptimizer != nil {
+		l.baseline, err = nn.NewMLP(append(append([]int{inputs}, config.Hidden...), 1), config.Activation, config.Random)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+func (l *reinforce) Act(state mdp.State, actions []mdp.Action) mdp.Action {
+	l.available = l.policy.positions(actions)
+	return l.policy.act(state, actions, l.config.Random)
+}
+
+func (l *reinforce) Learn(step env.Step, _ []mdp.Action) {
+	if len(l.policy.positions([]mdp.Action{step.Action})) == 0 {
+		return
+	}
+	l.episode = append(l.episode, visit{step, l.available})
+}
+
+func (l *reinforce) EndEpisode() {
+	if len(l.episode) > 0 {
+		l.update()
+	}
+	l.episode = nil
+	l.available = nil
+}
+
+func (l *reinforce) Probabilities(state mdp.State, actions []mdp.Action) []float32 {
+	return l.policy.probabilities(state, actions)
+}
+
+func (l *reinforce) Policy() nn.Network {
+	return l.policy.network
+}
+
+func (l *reinforce) Baseline() nn.Network {
+	return l.baseline
+}
+
+func (l *reinforce) GradientNorms() []float64 {
+	return l.norms
+}
+
+// update takes one gradient step on the policy, and on the baseline if there is one, using the episode's returns.
+func (l *reinforce) update() {
+	T := len(l.episode)
+	returns := make([]float64, T)
+	g := 0.0
+	for t := T - 1; t >= 0; t-- {
+		g = float64(l.episode[t].step.Reward) + float64(l.config.DiscountRate)*g
+		returns[t] = g
+	}
+
+	inputs := make([][]float64, T)
+	advantages := make([]float64, T)
+	for t, v := range l.episode {
+		inputs[t] = l.policy.encoder(v.step.State)
+		advantages[t] = returns[t]
+		if l.baseline != nil {
+			value := l.baseline.Forward(inputs[t])
+			advantages[t] -= value[0]
+			_, gradient := nn.SquaredError(value, []float64{returns[t]})
+			gradient[0] /= float64(T)
+			l.baseline.Backward(inputs[t], gradient)
+		}
+	}
+	if l.config.NormalizeReturns {
+		normalize(advantages)
+	}
+
+	for t, v := range l.episode {
+		logits := l.policy.network.Forward(inputs[t])
+		gradient := logProbabilityGradient(probabilities(logits, v.available), l.policy.positions([]mdp.Action{v.step.Action})[0])
+		// Ascend the objective by descending its negation
+		for i := range gradient {
+			gradient[i] *= -advantages[t] / float64(T)
+		}
+		l.policy.network.Backward(inputs[t], gradient)
+	}
+	l.norms = append(l.norms, l.policy.network.GradientNorm())
+	descend(l.policy.network, l.config.Optimizer, l.config.MaxGradientNorm)
+	if l.baseline != nil {
+		descend(l.baseline, l.config.BaselineOptimizer, l.config.MaxGradientNorm)
+	}
+}
+
+// normalize scales `values` in place to zero mean and unit standard deviation, or only centres them if they're all equal.
+func normalize(values []float64) {
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	std := math.Sqrt(variance / float64(len(values)))
+	for i := range values {
+		values[i] -= mean
+		if std > 1e-8 {
+			values[i] /= std
+		}
+	}
+}
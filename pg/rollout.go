@@ -0,0 +1,147 @@
+package pg
+
+import (
+	"math/rand"
+
+	"github.com/anthonykrivonos/go-rl/env"
+	"github.com/anthonykrivonos/go-rl/mdp"
+	"github.com/anthonykrivonos/go-rl/nn"
+	"github.com/anthonykrivonos/go-rl/utils"
+)
+
+// rollout holds a fixed number of steps of every environment of a VecEnv. Steps are stored time step by time step, so
+// step t of the i-th of n environments is at t*n + i.
+type rollout struct {
+	inputs    [][]float64
+	available [][]int
+	actions   []int
+	// probabilities holds the probability the policy gave each action when it was taken.
+	probabilities []float64
+	rewards       []float64
+	values        []float64
+	// nextValues holds the critic's value of the state each step arrived in, or 0 if the episode ended there.
+	nextValues []float64
+	// ends reports the steps after which the episode ended or was truncated.
+	ends       []bool
+	advantages []float64
+	returns    []float64
+}
+
+// collector steps a VecEnv by a softmax policy, carrying the environments' states from one rollout to the next.
+type collector struct {
+	vec    env.VecEnv
+	policy *softmaxPolicy
+	critic nn.Network
+	rng    *rand.Rand
+	states []mdp.State
+	// episodes holds the undiscounted returns of the episodes that have ended or been truncated.
+	episodes []float32
+}
+
+// newCollector resets `vec` and creates a collector for it.
+func newCollector(vec env.VecEnv, policy *softmaxPolicy, critic nn.Network, rng *rand.Rand) (*collector, error) {
+	states, err := vec.Reset()
+	if err != nil {
+		return nil, err
+	}
+	return &collector{vec: vec, policy: policy, critic: critic, rng: rng, states: states}, nil
+}
+
+// collect takes `steps` steps in every environment, sampling actions from the policy.
+func (c *collector) collect(steps int) (*rollout, error) {
+	n := c.vec.Len()
+	size := steps * n
+	r := &rollout{}
+	r.inputs = make([][]float64, size)
+	r.available = make([][]int, size)
+	r.actions = make([]int, size)
+	r.probabilities = make([]float64, size)
+	r.rewards = make([]float64, size)
+	r.values = make([]float64, size)
+	r.nextValues = make([]float64, size)
+	r.ends = make([]bool, size)
+
+	for t := 0; t < steps; t++ {
+		available := c.vec.Actions()
+		actions := make([]mdp.Action, n)
+		inputs := env.EncodeAll(c.policy.encoder, c.states)
+		for i := range c.states {
+			k := t*n + i
+			r.inputs[k] = inputs[i]
+			r.available[k] = c.policy.positions(available[i])
+			r.values[k] = c.critic.Forward(r.inputs[k])[0]
+			if len(r.available[k]) == 0 {
+				// The policy knows none of the actions, so take one without learning from it
+				r.available[k] = nil
+				actions[i] = available[i][0]
+				continue
+			}
+			p := probabilities(c.policy.network.Forward(r.inputs[k]), r.available[k])
+			r.actions[k] = utils.SampleCategorical(p, c.rng)
+			r.probabilities[k] = p[r.actions[k]]
+			actions[i] = c.policy.actions[r.actions[k]]
+		}
+
+		s, err := c.vec.Step(actions)
+		if err != nil {
+			return nil, err
+		}
+		for i := range c.states {
+			k := t*n + i
+			r.rewards[k] = float64(s.Rewards[i])
+			r.ends[k] = s.Dones[i] || s.Truncated[i]
+			if r.ends[k] {
+				c.episodes = append(c.episodes, s.Returns[i])
+			}
+			// The next step's value is filled in below unless the episode was cut off here or the rollout is over
+			if !s.Dones[i] && (s.Truncated[i] || t == steps-1) {
+				r.nextValues[k] = c.critic.Forward(c.policy.encoder(s.Finals[i]))[0]
+			}
+		}
+		c.states = s.States
+	}
+	for k := 0; k+n < size; k++ {
+		if !r.ends[k] {
+			r.nextValues[k] = r.values[k+n]
+		}
+	}
+	return r, nil
+}
+
+// estimateAdvantages sets the rollout's generalized advantage estimates, for `n` environments, and the returns the
+// critic is moved towards.
+func (r *rollout) estimateAdvantages(n int, discountRate, lambda float64) {
+	r.advantages = make([]float64, len(r.rewards))
+	r.returns = make([]float64, len(r.rewards))
+	for i := 0; i < n; i++ {
+		var rewards, values, nextValues []float64
+		var ends []bool
+		for k := i; k < len(r.rewards); k += n {
+			rewards = append(rewards, r.rewards[k])
+			values = append(values, r.values[k])
+			nextValues = append(nextValues, r.nextValues[k])
+			ends = append(ends, r.ends[k])
+		}
+		for t, advantage := range gae(rewards, values, nextValues, ends, discountRate, lambda) {
+			k := t*n + i
+			r.advantages[k] = advantage
+			r.returns[k] = advantage + r.values[k]
+		}
+	}
+}
+
+// gae returns the generalized advantage estimate of each of an environment's steps, Σ_l (ɣλ)^l δ_{t+l}, where
+// δ_t = r_t + ɣ V(s_{t+1}) - V(s_t) and the sum stops at the end of each episode.
+func gae(rewards, values, nextValues []float64, ends []bool, discountRate, lambda float64) []float64 {
+	advantages := make([]float64, len(rewards))
+	next := 0.0
+	for t := len(rewards) - 1; t >= 0; t-- {
+		if ends[t] {
+			next = 0
+		}
+		delta := rewards[t] + discountRate*nextValues[t] - values[t]
+		advantages[t] = delta + discountRate*lambda*next
+		next = advantages[t]
+	}
+	return advantages
+}
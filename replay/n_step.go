@@ -0,0 +1,61 @@
+package replay
+
+import (
+	"errors"
+
+	"github.com/anthonykrivonos/go-rl/env"
+)
+
+// An NStep assembles n-step steps from one-step ones, for learners that bootstrap from the state n steps later. An
+// n-step step starts in a state with an action, has the discounted sum of the next n rewards as its reward, and ends in
+// the state reached after them, so a learner bootstraps with ɣⁿ. If the episode ends sooner, the step ends there and is
+// done.
+type NStep struct {
+	n            int
+	discountRate float32
+	pending      []env.Step
+}
+
+// NewNStep creates an NStep that assembles `n`-step steps with discount rate `discountRate`.
+func NewNStep(n int, discountRate float32) (*NStep, error) {
+	if n <= 0 {
+		return nil, errors.New("n must be positive")
+	} else if discountRate <= 0 || discountRate > 1.0 {
+		return nil, errors.New("discount rate must be in (0, 1.0]")
+	}
+	return &NStep{n: n, discountRate: discountRate}, nil
+}
+
+// Add takes the episode's next step and returns the n-step steps it completes. When `step` ends the episode, every
+// pending step is completed. Steps still pending when an episode is cut short without ending are dropped by Reset,
+// since they span fewer than n steps and can't be bootstrapped with ɣⁿ.
+func (a *NStep) Add(step env.Step) []env.Step {
+	a.pending = append(a.pending, step)
+	var completed []env.Step
+	if step.Done {
+		for len(a.pending) > 0 {
+			completed = append(completed, a.assemble())
+		}
+	} else if len(a.pending) == a.n {
+		completed = append(completed, a.assemble())
+	}
+	return completed
+}
+
+// Reset drops any pending steps, to start a new episode.
+func (a *NStep) Reset() {
+	a.pending = nil
+}
+
+// assemble returns the n-step step starting at the oldest pending step, which it then drops.
+func (a *NStep) assemble() env.Step {
+	first, last := a.pending[0], a.pending[len(a.pending)-1]
+	reward := float32(0)
+	discount := float32(1)
+	for _, step := range a.pending {
+		reward += discount * step.Reward
+		discount *= a.discountRate
+	}
+	a.pending = a.pending[1:]
+	return env.Step{State: first.State, Action: first.Action, Reward: reward, NextState: last.NextState, Done: last.Done}
+}
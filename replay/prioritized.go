@@ -0,0 +1,151 @@
+package replay
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+
+	"github.com/anthonykrivonos/go-rl/env"
+)
+
+// minPriority keeps steps with zero error sampleable.
+const minPriority = 1e-6
+
+// sumTree is a binary tree over a fixed number of leaves in which every node holds the sum of its leaves, so that
+// leaves can be updated and sampled in proportion to their values in logarithmic time.
+type sumTree struct {
+	// Node i has children 2i and 2i + 1, and the leaves start at `leaves`
+	nodes  []float64
+	leaves int
+}
+
+func newSumTree(size int) *sumTree {
+	leaves := 1
+	for leaves < size {
+		leaves *= 2
+	}
+	return &sumTree{make([]float64, 2*leaves), leaves}
+}
+
+// set sets the value of leaf `i`.
+func (t *sumTree) set(i int, value float64) {
+	node := t.leaves + i
+	t.nodes[node] = value
+	for node /= 2; node >= 1; node /= 2 {
+		t.nodes[node] = t.nodes[2*node] + t.nodes[2*node+1]
+	}
+}
+
+// get returns the value of leaf `i`.
+func (t *sumTree) get(i int) float64 {
+	return t.nodes[t.leaves+i]
+}
+
+// total returns the sum of all leaves.
+func (t *sumTree) total() float64 {
+	return t.nodes[1]
+}
+
+// find returns the leaf where the running sum of leaves first exceeds `prefix`.
+func (t *sumTree) find(prefix float64) int {
+	node := 1
+	for node < t.leaves {
+		if left := t.nodes[2*node]; prefix < left || t.nodes[2*node+1] == 0 {
+			node = 2 * node
+		} else {
+			prefix -= left
+			node = 2*node + 1
+		}
+	}
+	return node - t.leaves
+}
+
+// A replay buffer that samples steps in proportion to their priority.
+type PrioritizedBuffer interface {
+	Buffer
+	// SetBeta sets how fully importance sampling weights correct for prioritized sampling, from 0 for not at all to 1
+	// for fully. It's usually annealed towards 1 over training.
+	SetBeta(beta float64)
+}
+
+type prioritized struct {
+	ring
+	tree *sumTree
+	// The largest priority given so far, which new steps get so that they're replayed at least once
+	maxPriority float64
+	alpha, beta float64
+	rng         *rand.Rand
+}
+
+// NewPrioritized creates a replay buffer of `capacity` steps that samples each step with probability proportional to
+// p^α, where its priority p is its latest absolute error. New steps get the largest priority seen so far. Importance
+// sampling weights (N P(i))^-β are scaled so that the batch's largest is 1. `rng` is the source of randomness for
+// sampling.
+func NewPrioritized(capacity int, alpha float64, beta float64, rng *rand.Rand) (PrioritizedBuffer, error) {
+	if alpha < 0 {
+		return nil, errors.New("alpha must be non-negative")
+	} else if beta < 0 || beta > 1 {
+		return nil, errors.New("beta must be in [0, 1]")
+	} else if rng == nil {
+		return nil, errors.New("random source must be provided")
+	}
+	r, err := newRing(capacity)
+	if err != nil {
+		return nil, err
+	}
+	b := &prioritized{}
+	b.ring = r
+	b.tree = newSumTree(capacity)
+	b.maxPriority = 1
+	b.alpha = alpha
+	b.beta = beta
+	b.rng = rng
+	return b, nil
+}
+
+func (b *prioritized) Add(step env.Step) {
+	b.tree.set(b.add(step), math.Pow(b.maxPriority, b.alpha))
+}
+
+func (b *prioritized) Sample(n int) (*Batch, error) {
+	if n <= 0 {
+		return nil, errBatchSize
+	} else if b.Len() == 0 {
+		return nil, errEmpty
+	}
+	batch := &Batch{make([]env.Step, n), make([]int, n), make([]float64, n)}
+	total := b.tree.total()
+	maxWeight := 0.0
+	for i := 0; i < n; i++ {
+		// Draw one step from each of n equal slices of the total priority
+		prefix := (float64(i) + b.rng.Float64()) * total / float64(n)
+		position := b.tree.find(prefix)
+		if position >= b.Len() {
+			position = b.Len() - 1
+		}
+		probability := b.tree.get(position) / total
+		batch.Steps[i] = b.steps[position]
+		batch.Positions[i] = position
+		batch.Weights[i] = math.Pow(float64(b.Len())*probability, -b.beta)
+		maxWeight = math.Max(maxWeight, batch.Weights[i])
+	}
+	for i := range batch.Weights {
+		batch.Weights[i] /= maxWeight
+	}
+	return batch, nil
+}
+
+func (b *prioritized) UpdatePriorities(positions []int, errors []float64) {
+	for i, position := range positions {
+		if position < 0 || position >= b.Len() {
+			continue
+		}
+		priority := math.Abs(errors[i]) + minPriority
+		b.maxPriority = math.Max(b.maxPriority, priority)
+		b.tree.set(position, math.Pow(priority, b.alpha))
+	}
+}
+
+func (b *prioritized) SetBeta(beta float64) {
+	b.beta = math.Max(0, math.Min(1, beta))
+}
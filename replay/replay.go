@@ -0,0 +1,114 @@
+package replay
+
+import (
+	"errors"
+	"math/rand"
+
+	"github.com/anthonykrivonos/go-rl/env"
+)
+
+// A Batch is a sample of steps from a Buffer.
+type Batch struct {
+	// Steps holds the sampled steps.
+	Steps []env.Step
+	// Positions holds where each step is stored, to update its priority.
+	Positions []int
+	// Weights holds the importance sampling weight of each step, which corrects for non-uniform sampling when
+	// multiplied into its loss.
+	Weights []float64
+}
+
+// A replay buffer, which stores a fixed number of steps of experience, overwriting the oldest once full, and samples
+// batches of them for learning.
+type Buffer interface {
+	// Add stores a step.
+	Add(step env.Step)
+	// Len returns the number of stored steps.
+	Len() int
+	// Capacity returns the most steps the buffer stores.
+	Capacity() int
+	// Sample returns `n` stored steps drawn with replacement, or an error if `n` isn't positive or the buffer is empty.
+	Sample(n int) (*Batch, error)
+	// UpdatePriorities sets the priorities of the steps stored at `positions` from their latest errors, such as TD
+	// errors. Buffers that sample uniformly ignore it.
+	UpdatePriorities(positions []int, errors []float64)
+}
+
+// ring holds the steps of a buffer, overwriting the oldest once full.
+type ring struct {
+	steps []env.Step
+	next  int
+	full  bool
+}
+
+func newRing(capacity int) (ring, error) {
+	if capacity <= 0 {
+		return ring{}, errors.New("capacity must be positive")
+	}
+	return ring{steps: make([]env.Step, capacity)}, nil
+}
+
+// add stores a step and returns its position.
+func (r *ring) add(step env.Step) int {
+	position := r.next
+	r.steps[position] = step
+	r.next = (r.next + 1) % len(r.steps)
+	r.full = r.full || r.next == 0
+	return position
+}
+
+func (r *ring) Len() int {
+	if r.full {
+		return len(r.steps)
+	}
+	return r.next
+}
+
+func (r *ring) Capacity() int {
+	return len(r.steps)
+}
+
+var (
+	errEmpty     = errors.New("buffer is empty")
+	errBatchSize = errors.New("batch size must be positive")
+)
+
+type uniform struct {
+	ring
+	rng *rand.Rand
+}
+
+// NewUniform creates a replay buffer of `capacity` steps that samples every stored step with equal probability.
+// `rng` is the source of randomness for sampling.
+func NewUniform(capacity int, rng *rand.Rand) (Buffer, error) {
+	if rng == nil {
+		return nil, errors.New("random source must be provided")
+	}
+	r, err := newRing(capacity)
+	if err != nil {
+		return nil, err
+	}
+	return &uniform{r, rng}, nil
+}
+
+func (b *uniform) Add(step env.Step) {
+	b.add(step)
+}
+
+func (b *uniform) Sample(n int) (*Batch, error) {
+	if n <= 0 {
+		return nil, errBatchSize
+	} else if b.Len() == 0 {
+		return nil, errEmpty
+	}
+	batch := &Batch{make([]env.Step, n), make([]int, n), make([]float64, n)}
+	for i := 0; i < n; i++ {
+		position := b.rng.Intn(b.Len())
+		batch.Steps[i] = b.steps[position]
+		batch.Positions[i] = position
+		batch.Weights[i] = 1
+	}
+	return batch, nil
+}
+
+func (b *uniform) UpdatePriorities([]int, []float64) {}
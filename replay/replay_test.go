@@ -0,0 +1,155 @@
+package replay
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/anthonykrivonos/go-rl/env"
+	"github.com/anthonykrivonos/go-rl/mdp"
+	"github.com/stretchr/testify/assert"
+)
+
+// newStep returns a step from state `i` to state i + 1 with reward `i`.
+func newStep(i int, done bool) env.Step {
+	return env.Step{
+		State:     mdp.NewState(fmt.Sprint("S", i), i, false),
+		Action:    mdp.NewAction("next"),
+		Reward:    float32(i),
+		NextState: mdp.NewState(fmt.Sprint("S", i+1), i+1, done),
+		Done:      done,
+	}
+}
+
+func TestUniform(t *testing.T) {
+	b, err := NewUniform(3, rand.New(rand.NewSource(1)))
+	assert.NoError(t, err)
+	_, err = b.Sample(1)
+	assert.Error(t, err)
+
+	for i := 0; i < 5; i++ {
+		b.Add(newStep(i, false))
+	}
+	assert.Equal(t, 3, b.Len())
+	assert.Equal(t, 3, b.Capacity())
+	_, err = b.Sample(0)
+	assert.Error(t, err)
+	_, err = b.Sample(-1)
+	assert.Error(t, err)
+
+	// The two oldest steps were overwritten
+	batch, err := b.Sample(300)
+	assert.NoError(t, err)
+	counts := make(map[int]int)
+	for i, step := range batch.Steps {
+		counts[step.State.Index()]++
+		assert.Equal(t, 1.0, batch.Weights[i])
+	}
+	assert.Len(t, counts, 3)
+	assert.Zero(t, counts[0]+counts[1])
+	assert.InDelta(t, 100, counts[2], 30)
+
+	_, err = NewUniform(0, rand.New(rand.NewSource(1)))
+	assert.Error(t, err)
+}
+
+func TestSumTree(t *testing.T) {
+	tree := newSumTree(3)
+	tree.set(0, 1)
+	tree.set(1, 2)
+	tree.set(2, 3)
+	assert.Equal(t, 6.0, tree.total())
+	assert.Equal(t, 0, tree.find(0.5))
+	assert.Equal(t, 1, tree.find(1))
+	assert.Equal(t, 1, tree.find(2.9))
+	assert.Equal(t, 2, tree.find(3))
+	assert.Equal(t, 2, tree.find(6))
+	tree.set(1, 0)
+	assert.Equal(t, 4.0, tree.total())
+	assert.Equal(t, 2, tree.find(1.5))
+}
+
+func TestPrioritized(t *testing.T) {
+	b, err := NewPrioritized(4, 1, 1, rand.New(rand.NewSource(1)))
+	assert.NoError(t, err)
+	for i := 0; i < 4; i++ {
+		b.Add(newStep(i, false))
+	}
+	b.UpdatePriorities([]int{0, 1, 2, 3}, []float64{1, 1, 2, -4})
+	_, err = b.Sample(0)
+	assert.Error(t, err)
+	_, err = b.Sample(-1)
+	assert.Error(t, err)
+
+	batch, err := b.Sample(8000)
+	assert.NoError(t, err)
+	counts := make(map[int]int)
+	weights := make(map[int]float64)
+	for i, step := range batch.Steps {
+		counts[step.State.Index()]++
+		weights[step.State.Index()] = batch.Weights[i]
+		assert.Equal(t, step.State.Index(), batch.Positions[i])
+	}
+	assert.InDelta(t, 1000, counts[0], 100)
+	assert.InDelta(t, 4000, counts[3], 150)
+	// Weights undo the sampling bias, with the rarest steps weighted most
+	assert.InDelta(t, 1, weights[0], 1e-6)
+	assert.InDelta(t, 0.5, weights[2], 1e-6)
+	assert.InDelta(t, 0.25, weights[3], 1e-6)
+
+	// New steps get the largest priority so far
+	b.Add(newStep(4, false))
+	assert.InDelta(t, 4, b.(*prioritized).tree.get(0), 1e-5)
+
+	// Without prioritization, sampling is uniform
+	b, err = NewPrioritized(4, 0, 1, rand.New(rand.NewSource(1)))
+	assert.NoError(t, err)
+	for i := 0; i < 4; i++ {
+		b.Add(newStep(i, false))
+	}
+	b.UpdatePriorities([]int{0, 3}, []float64{10, 0})
+	batch, err = b.Sample(10)
+	assert.NoError(t, err)
+	for _, w := range batch.Weights {
+		assert.InDelta(t, 1, w, 1e-9)
+	}
+
+	_, err = NewPrioritized(4, -1, 1, rand.New(rand.NewSource(1)))
+	assert.Error(t, err)
+	_, err = NewPrioritized(4, 1, 2, rand.New(rand.NewSource(1)))
+	assert.Error(t, err)
+}
+
+func TestNStep(t *testing.T) {
+	a, err := NewNStep(3, 0.5)
+	assert.NoError(t, err)
+	assert.Empty(t, a.Add(newStep(1, false)))
+	assert.Empty(t, a.Add(newStep(2, false)))
+	completed := a.Add(newStep(3, false))
+	assert.Len(t, completed, 1)
+	assert.Equal(t, 1, completed[0].State.Index())
+	assert.Equal(t, 4, completed[0].NextState.Index())
+	assert.Equal(t, float32(1+0.5*2+0.25*3), completed[0].Reward)
+	assert.False(t, completed[0].Done)
+
+	// The episode's end completes every pending step
+	completed = a.Add(newStep(4, true))
+	assert.Len(t, completed, 3)
+	assert.Equal(t, float32(2+0.5*3+0.25*4), completed[0].Reward)
+	assert.Equal(t, float32(4), completed[2].Reward)
+	for _, step := range completed {
+		assert.True(t, step.Done)
+		assert.Equal(t, 5, step.NextState.Index())
+	}
+
+	// Cutting an episode short drops its pending steps
+	a.Add(newStep(1, false))
+	a.Reset()
+	a.Add(newStep(5, false))
+	a.Add(newStep(6, false))
+	completed = a.Add(newStep(7, false))
+	assert.Equal(t, 5, completed[0].State.Index())
+
+	_, err = NewNStep(0, 0.5)
+	assert.Error(t, err)
+}
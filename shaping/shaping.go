@@ -0,0 +1,139 @@
+package shaping
+
+import (
+	"errors"
+	"math"
+
+	"github.com/anthonykrivonos/go-rl/env"
+	"github.com/anthonykrivonos/go-rl/mdp"
+)
+
+// Potential returns the potential Φ of a state. Shaping with a potential adds F(s, s') = ɣΦ(s') - Φ(s) to the reward of
+// every transition, which leaves the optimal policy unchanged.
+type Potential func(state mdp.State) float32
+
+// reward returns the shaping reward F(s, s') = ɣΦ(s') - Φ(s) for a transition. The potential of a state that ends the
+// episode is taken to be 0, so that shaping leaves the optimal policy unchanged.
+func (p Potential) reward(discountRate float32, state, nextState mdp.State, done bool) float32 {
+	next := float32(0)
+	if !done {
+		next = p(nextState)
+	}
+	return discountRate*next - p(state)
+}
+
+type shapedMDP struct {
+	mdp.MDP
+	potential Potential
+}
+
+// NewShapedMDP wraps an MDP so that every transition pays the potential-based shaping reward F(s, s') = ɣΦ(s') - Φ(s),
+// using the MDP's discount rate. The wrapper implements mdp.TransitionRewarder, so solvers include the shaping reward,
+// and is otherwise identical to `m`.
+func NewShapedMDP(m mdp.MDP, potential Potential) (mdp.MDP, error) {
+	if m == nil {
+		return nil, errors.New("mdp must be provided")
+	} else if potential == nil {
+		return nil, errors.New("potential must be provided")
+	}
+	s := &shapedMDP{}
+	s.MDP = m
+	s.potential = potential
+	return s, nil
+}
+
+// TransitionReward returns the shaping reward for moving from `state` to `nextState`. A next state with no actions ends
+// the episode as a terminal one does, since solvers never charge its potential back, so its potential is taken to be 0.
+func (s *shapedMDP) TransitionReward(state mdp.State, nextState mdp.State) float32 {
+	done := nextState.Terminal() || len(s.AvailableActions(nextState)) == 0
+	reward := s.potential.reward(s.DiscountRate(), state, nextState, done)
+	if rewarder, ok := s.MDP.(mdp.TransitionRewarder); ok {
+		reward += rewarder.TransitionReward(state, nextState)
+	}
+	return reward
+}
+
+type shapedEnvironment struct {
+	env.Environment
+	potential    Potential
+	discountRate float32
+	current      mdp.State
+}
+
+// NewShapedEnvironment wraps an Environment so that every step's reward includes the potential-based shaping reward
+// F(s, s') = ɣΦ(s') - Φ(s), where ɣ is `discountRate`.
+func NewShapedEnvironment(e env.Environment, potential Potential, discountRate float32) (env.Environment, error) {
+	if e == nil {
+		return nil, errors.New("environment must be provided")
+	} else if potential == nil {
+		return nil, errors.New("potential must be provided")
+	} else if discountRate <= 0 || discountRate > 1.0 {
+		return nil, errors.New("discount rate must be in (0, 1.0]")
+	}
+	s := &shapedEnvironment{}
+	s.Environment = e
+	s.potential = potential
+	s.discountRate = discountRate
+	return s, nil
+}
+
+// Reset starts a new episode of the wrapped environment.
+func (s *shapedEnvironment) Reset() (mdp.State, error) {
+	state, err := s.Environment.Reset()
+	if err != nil {
+		return nil, err
+	}
+	s.current = state
+	return state, nil
+}
+
+// Step takes a step in the wrapped environment and adds the shaping reward to its reward.
+func (s *shapedEnvironment) Step(action mdp.Action) (mdp.State, float32, bool, error) {
+	if s.current == nil {
+		return nil, 0, false, errors.New("call Reset before Step")
+	}
+	next, reward, done, err := s.Environment.Step(action)
+	if err != nil {
+		return nil, 0, done, err
+	}
+	reward += s.potential.reward(s.discountRate, s.current, next, done)
+	s.current = next
+	return next, reward, done, nil
+}
+
+// NewGridDistancePotential returns a potential for grid worlds whose states are laid out row by row, `width` columns
+// wide, so that a state's index is its row times `width` plus its column. A state's potential is `scale` times the
+// negative Manhattan distance to its nearest goal, so moving towards a goal is rewarded.
+func NewGridDistancePotential(width int, goals []mdp.State, scale float32) (Potential, error) {
+	if width <= 0 {
+		return nil, errors.New("width must be positive")
+	} else if len(goals) == 0 {
+		return nil, errors.New("at least one goal must be provided")
+	}
+	type cell struct {
+		row, column int
+	}
+	targets := make([]cell, len(goals))
+	for i, goal := range goals {
+		targets[i] = cell{goal.Index() / width, goal.Index() % width}
+	}
+	return func(state mdp.State) float32 {
+		row, column := state.Index()/width, state.Index()%width
+		nearest := math.MaxInt32
+		for _, target := range targets {
+			distance := abs(row-target.row) + abs(column-target.column)
+			if distance < nearest {
+				nearest = distance
+			}
+		}
+		return -scale * float32(nearest)
+	}, nil
+}
+
+// abs returns the absolute value of an integer.
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,163 @@
+package shaping
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/anthonykrivonos/go-rl/env"
+	"github.com/anthonykrivonos/go-rl/mdp"
+	"github.com/anthonykrivonos/go-rl/solver"
+	"github.com/stretchr/testify/assert"
+)
+
+// newGridMDP creates a 3x3 grid world with holes in the top and middle centers, a costly bottom center and a goal in
+// the bottom right.
+func newGridMDP(t *testing.T) mdp.MDP {
+	m, err := mdp.NewDefaultMDP()
+	assert.NoError(t, err)
+	assert.NoError(t, m.SetDiscountRate(0.9))
+
+	goUp := mdp.NewAction("U")
+	goRight := mdp.NewAction("R")
+	goDown := mdp.NewAction("D")
+	goLeft := mdp.NewAction("L")
+
+	names := []string{"TL", "TC", "TR", "ML", "MC", "MR", "BL", "BM", "BR"}
+	rewards := []float32{0, -2, 0, 0, -2, 0, 0, -0.5, 10}
+	states := make([]mdp.State, len(names))
+	for i, name := range names {
+		states[i] = mdp.NewState(name, i, i == 8)
+	}
+	for i, state := range states {
+		row, column := i/3, i%3
+		moves := make(map[mdp.Action]mdp.Transition)
+		if row > 0 {
+			moves[goUp] = mdp.NewTransition(1, states[i-3])
+		}
+		if column < 2 {
+			moves[goRight] = mdp.NewTransition(1, states[i+1])
+		}
+		if row < 2 {
+			moves[goDown] = mdp.NewTransition(1, states[i+3])
+		}
+		if column > 0 {
+			moves[goLeft] = mdp.NewTransition(1, states[i-1])
+		}
+		assert.NoError(t, m.AddStateObject(state, rewards[i], moves))
+	}
+	return m
+}
+
+func TestGridDistancePotential(t *testing.T) {
+	m := newGridMDP(t)
+
+	potential, err := NewGridDistancePotential(3, []mdp.State{m.StateByName("BR")}, 0.5)
+	assert.NoError(t, err)
+	assert.Equal(t, float32(-2), potential(m.StateByName("TL")))
+	assert.Equal(t, float32(-1), potential(m.StateByName("MC")))
+	assert.Equal(t, float32(0), potential(m.StateByName("BR")))
+
+	potential, err = NewGridDistancePotential(3, []mdp.State{m.StateByName("TL"), m.StateByName("BR")}, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, float32(-1), potential(m.StateByName("TC")))
+	assert.Equal(t, float32(-1), potential(m.StateByName("BM")))
+
+	_, err = NewGridDistancePotential(0, []mdp.State{m.StateByName("BR")}, 1)
+	assert.Error(t, err)
+	_, err = NewGridDistancePotential(3, nil, 1)
+	assert.Error(t, err)
+}
+
+func TestShapedMDPPreservesOptimalPolicy(t *testing.T) {
+	m := newGridMDP(t)
+	potential, err := NewGridDistancePotential(3, []mdp.State{m.StateByName("BR")}, 1)
+	assert.NoError(t, err)
+	shaped, err := NewShapedMDP(m, potential)
+	assert.NoError(t, err)
+
+	values, policy, err := solver.ValueIteration(m, 1e-6, 1000)
+	assert.NoError(t, err)
+	shapedValues, shapedPolicy, err := solver.ValueIteration(shaped, 1e-6, 1000)
+	assert.NoError(t, err)
+
+	assert.Equal(t, policy.String(), shapedPolicy.String())
+	assert.Equal(t, "{S0: D, S1: R, S2: D, S3: D, S4: R, S5: D, S6: R, S7: R}", shapedPolicy.String())
+
+	// Shaping shifts each non-terminal state's value by exactly its potential
+	for _, state := range m.States() {
+		if !state.Terminal() {
+			assert.InDelta(t, values[state.Index()]-potential(state), shapedValues[state.Index()], 1e-4)
+		}
+	}
+
+	// The linear programming solver sees the shaping reward too
+	_, lpPolicy, err := solver.SolvePrimalLP(shaped)
+	assert.NoError(t, err)
+	assert.Equal(t, policy.String(), lpPolicy.String())
+}
+
+func TestShapedEnvironment(t *testing.T) {
+	m := newGridMDP(t)
+	potential, err := NewGridDistancePotential(3, []mdp.State{m.StateByName("BR")}, 1)
+	assert.NoError(t, err)
+
+	e, err := env.NewMDPEnvironment(m, rand.New(rand.NewSource(1)))
+	assert.NoError(t, err)
+	shaped, err := NewShapedEnvironment(e, potential, 0.9)
+	assert.NoError(t, err)
+
+	_, _, _, err = shaped.Step(mdp.NewAction("D"))
+	assert.Error(t, err)
+
+	// The discounted shaping rewards telescope to -Φ(start), since the episode ends at the goal
+	state, err := shaped.Reset()
+	assert.NoError(t, err)
+	start := state
+	discount := float32(1)
+	unshaped, total := float32(0), float32(0)
+	for _, name := range []string{"D", "D", "R", "R"} {
+		next, reward, done, err := shaped.Step(mdp.NewAction(name))
+		assert.NoError(t, err)
+		unshaped += discount * m.RByIndex(next.Index())
+		total += discount * reward
+		discount *= 0.9
+		if done {
+			assert.Equal(t, "BR", next.Name())
+		}
+	}
+	assert.InDelta(t, unshaped-potential(start), total, 1e-5)
+
+	_, err = NewShapedEnvironment(e, potential, 0)
+	assert.Error(t, err)
+}
+
+func TestShapedMDPWithDeadEnd(t *testing.T) {
+	m, err := mdp.NewDefaultMDP()
+	assert.NoError(t, err)
+	assert.NoError(t, m.SetDiscountRate(0.9))
+	trap, finish := mdp.NewAction("trap"), mdp.NewAction("finish")
+	start := mdp.NewState("start", 0, false)
+	deadEnd := mdp.NewState("deadEnd", 1, false)
+	goal := mdp.NewState("goal", 2, true)
+	assert.NoError(t, m.AddStateObject(start, 0, map[mdp.Action]mdp.Transition{
+		trap:   mdp.NewTransition(1, deadEnd),
+		finish: mdp.NewTransition(1, goal),
+	}))
+	assert.NoError(t, m.AddStateObject(deadEnd, 0, nil))
+	assert.NoError(t, m.AddStateObject(goal, 1, nil))
+
+	// A large potential in the dead end must not lure the policy into it
+	shaped, err := NewShapedMDP(m, func(state mdp.State) float32 {
+		if state.Name() == "deadEnd" {
+			return 10
+		}
+		return 0
+	})
+	assert.NoError(t, err)
+	_, policy, err := solver.ValueIteration(m, 1e-6, 1000)
+	assert.NoError(t, err)
+	_, shapedPolicy, err := solver.ValueIteration(shaped, 1e-6, 1000)
+	assert.NoError(t, err)
+	assert.Equal(t, "finish", policy[start.Index()].Name())
+	assert.Equal(t, policy.String(), shapedPolicy.String())
+}
@@ -0,0 +1,221 @@
+package solver
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/anthonykrivonos/go-rl/mdp"
+	"github.com/anthonykrivonos/go-rl/utils"
+)
+
+// aperiodicityWeight is the probability of following the MDP's dynamics in the aperiodic transformation used by
+// relative value iteration. The remaining probability keeps the process in place, which leaves the gain unchanged.
+const aperiodicityWeight = 0.5
+
+// AverageRewardResult is the solution of an MDP under the average-reward (undiscounted, infinite-horizon) criterion.
+type AverageRewardResult struct {
+	// Gain is the optimal average reward per step from the initial state.
+	Gain float32
+	// Gains maps each state index to the optimal average reward per step from that state.
+	Gains map[int]float32
+	// Bias maps each state index to its relative (bias) value, normalized to 0 at the initial state.
+	Bias map[int]float32
+	// Policy is an optimal policy.
+	Policy Policy
+	// Multichain is true if the gain differs between states.
+	Multichain bool
+	// Iterations is the number of sweeps performed.
+	Iterations int
+}
+
+// RelativeValueIteration solves an MDP under the average-reward criterion. Rewards are collected on every step spent in
+// a state, terminal states and states without actions are treated as absorbing, and the MDP's discount rate is ignored.
+// Iteration stops once the per-step change in values is the same for every state to within `tolerance` (a unichain
+// model), or once the per-step change has itself stopped changing but differs by state (a multichain model).
+// Returns the result and a nil error on success or returns nil and a non-nil error on failure.
+func RelativeValueIteration(m mdp.MDP, tolerance float32, maxIterations int) (*AverageRewardResult, error) {
+	if tolerance <= 0 {
+		return nil, errors.New("tolerance must be positive")
+	} else if maxIterations <= 0 {
+		return nil, errors.New("max iterations must be positive")
+	}
+	md, err := newModel(m)
+	if err != nil {
+		return nil, err
+	}
+
+	n := md.size()
+	ref := md.reference(m)
+	tol := float64(tolerance)
+	h := make([]float64, n)
+	th := make([]float64, n)
+	diff := make([]float64, n)
+	prevDiff := make([]float64, n)
+
+	for iteration := 1; iteration <= maxIterations; iteration++ {
+		for s := 0; s < n; s++ {
+			th[s] = relativeBackup(md, s, h)
+			diff[s] = th[s] - h[s]
+		}
+
+		low, high := bounds(diff)
+		unichain := high-low < tol
+		multichain := !unichain && iteration > 1 && maxDistance(diff, prevDiff) < tol && classesDiffer(md, h, diff, tol)
+
+		offset := th[ref]
+		for s := 0; s < n; s++ {
+			h[s] = th[s] - offset
+		}
+		copy(prevDiff, diff)
+
+		if unichain || multichain {
+			result := &AverageRewardResult{}
+			result.Gains = make(map[int]float32)
+			result.Bias = make(map[int]float32)
+			result.Policy = greedyPolicy(md, h)
+			result.Multichain = !unichain
+			result.Iterations = iteration
+			for s, state := range md.states {
+				if unichain {
+					result.Gains[state.Index()] = float32((low + high) / 2)
+				} else {
+					result.Gains[state.Index()] = float32(diff[s])
+				}
+				result.Bias[state.Index()] = float32(aperiodicityWeight * h[s])
+			}
+			result.Gain = result.Gains[md.states[ref].Index()]
+			return result, nil
+		}
+	}
+
+	return nil, errors.New("relative value iteration did not converge within " + fmt.Sprint(maxIterations) + " iterations")
+}
+
+// relativeBackup returns the best immediate reward plus expected relative value from state `s` under the aperiodic
+// transformation.
+func relativeBackup(md *model, s int, h []float64) float64 {
+	if md.absorbing(s) {
+		return md.rewards[s] + h[s]
+	}
+	best := math.Inf(-1)
+	for a := range md.actions[s] {
+		v := md.actionRewards[s][a] + (1-aperiodicityWeight)*h[s] + aperiodicityWeight*md.expectation(s, a, h)
+		if v > best {
+			best = v
+		}
+	}
+	return best
+}
+
+// classesDiffer returns whether the closed classes of the policy that is greedy with respect to `h` have different
+// gains, estimated by the per-step change in value `diff` of their states.
+func classesDiffer(md *model, h, diff []float64, tolerance float64) bool {
+	choices := greedyChoices(md, h)
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, class := range closedClasses(md, choices) {
+		gain := 0.0
+		for _, s := range class {
+			gain += diff[s]
+		}
+		gain /= float64(len(class))
+		low = math.Min(low, gain)
+		high = math.Max(high, gain)
+	}
+	return high-low > tolerance
+}
+
+// closedClasses returns the closed communicating classes of the Markov chain induced by choosing the action at position
+// `choices[s]` in each state `s`. A choice of -1 keeps the state in place.
+func closedClasses(md *model, choices []int) [][]int {
+	successors := func(s int) []int {
+		if choices[s] < 0 {
+			return []int{s}
+		}
+		var next []int
+		for _, o := range md.outcomes[s][choices[s]] {
+			if o.probability > 0 {
+				next = append(next, o.next)
+			}
+		}
+		return next
+	}
+
+	var classes [][]int
+	component := make([]int, md.size())
+	for _, scc := range utils.StronglyConnectedComponents(md.size(), successors) {
+		for _, s := range scc {
+			component[s] = len(classes)
+		}
+		classes = append(classes, scc)
+	}
+
+	var closed [][]int
+	for c, class := range classes {
+		isClosed := true
+		for _, s := range class {
+			for _, next := range successors(s) {
+				if component[next] != c {
+					isClosed = false
+				}
+			}
+		}
+		if isClosed {
+			closed = append(closed, class)
+		}
+	}
+	return closed
+}
+
+// greedyPolicy returns the policy that maximizes each action's value under the relative values `values`, as in
+// relativeBackup. Ties go to the action whose name sorts first.
+func greedyPolicy(md *model, values []float64) Policy {
+	policy := make(Policy)
+	for s, a := range greedyChoices(md, values) {
+		if a >= 0 {
+			policy[md.states[s].Index()] = md.actions[s][a]
+		}
+	}
+	return policy
+}
+
+// greedyChoices returns the position of the action that maximizes its immediate reward plus the expected relative value
+// `values` of its outcomes under the aperiodic transformation in each state, or -1 for absorbing states. Ties go to the
+// action whose name sorts first.
+func greedyChoices(md *model, values []float64) []int {
+	choices := make([]int, md.size())
+	for s := range md.states {
+		choices[s] = -1
+		bestValue := math.Inf(-1)
+		for a := range md.actions[s] {
+			v := md.actionRewards[s][a] + aperiodicityWeight*md.expectation(s, a, values)
+			if v > bestValue+tieTolerance {
+				choices[s] = a
+				bestValue = v
+			}
+		}
+	}
+	return choices
+}
+
+// tieTolerance is the margin by which an action's value must exceed the best so far to replace it in a greedy policy.
+const tieTolerance = 1e-9
+
+// bounds returns the smallest and largest entries of `values`.
+func bounds(values []float64) (float64, float64) {
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		low = math.Min(low, v)
+		high = math.Max(high, v)
+	}
+	return low, high
+}
+
+// maxDistance returns the largest absolute difference between corresponding entries of `a` and `b`.
+func maxDistance(a, b []float64) float64 {
+	distance := 0.0
+	for i := range a {
+		distance = math.Max(distance, math.Abs(a[i]-b[i]))
+	}
+	return distance
+}
@@ -0,0 +1,138 @@
+package solver
+
+import (
+	"testing"
+
+	"github.com/anthonykrivonos/go-rl/mdp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelativeValueIterationCycle(t *testing.T) {
+	m, err := mdp.NewDefaultMDP()
+	assert.NoError(t, err)
+
+	next := mdp.NewAction("N")
+	a := mdp.NewState("A", 0, false)
+	b := mdp.NewState("B", 1, false)
+
+	assert.NoError(t, m.AddStateObject(a, 1, map[mdp.Action]mdp.Transition{next: mdp.NewTransition(1, b)}))
+	assert.NoError(t, m.AddStateObject(b, 3, map[mdp.Action]mdp.Transition{next: mdp.NewTransition(1, a)}))
+
+	result, err := RelativeValueIteration(m, 1e-6, 10000)
+	assert.NoError(t, err)
+	assert.False(t, result.Multichain)
+	assert.InDelta(t, 2, result.Gain, 1e-4)
+	assert.InDelta(t, 0, result.Bias[0], 1e-4)
+	assert.InDelta(t, 1, result.Bias[1], 1e-4)
+	assert.Equal(t, "N", result.Policy.Action(a).Name())
+}
+
+func TestRelativeValueIterationChoosesBestLoop(t *testing.T) {
+	m, err := mdp.NewDefaultMDP()
+	assert.NoError(t, err)
+
+	stay := mdp.NewAction("stay")
+	leave := mdp.NewAction("leave")
+	start := mdp.NewState("start", 0, false)
+	poor := mdp.NewState("poor", 1, false)
+	rich := mdp.NewState("rich", 2, false)
+
+	assert.NoError(t, m.AddStateObject(start, 0, map[mdp.Action]mdp.Transition{
+		stay:  mdp.NewTransition(1, poor),
+		leave: mdp.NewTransition(0.5, rich),
+	}))
+	assert.NoError(t, m.AddStateObject(poor, 1, map[mdp.Action]mdp.Transition{
+		stay:  mdp.NewTransition(1, poor),
+		leave: mdp.NewTransition(1, start),
+	}))
+	assert.NoError(t, m.AddStateObject(rich, 4, map[mdp.Action]mdp.Transition{
+		stay:  mdp.NewTransition(1, rich),
+		leave: mdp.NewTransition(1, start),
+	}))
+
+	result, err := RelativeValueIteration(m, 1e-6, 10000)
+	assert.NoError(t, err)
+	assert.False(t, result.Multichain)
+	assert.InDelta(t, 4, result.Gain, 1e-4)
+	for _, gain := range result.Gains {
+		assert.InDelta(t, 4, gain, 1e-4)
+	}
+	assert.Equal(t, "leave", result.Policy.Action(start).Name())
+	assert.Equal(t, "leave", result.Policy.Action(poor).Name())
+	assert.Equal(t, "stay", result.Policy.Action(rich).Name())
+}
+
+// transitionRewards wraps an MDP to pay extra rewards on transitions, keyed by the indices of the two states.
+type transitionRewards struct {
+	mdp.MDP
+	rewards map[[2]int]float32
+}
+
+func (m *transitionRewards) TransitionReward(state mdp.State, nextState mdp.State) float32 {
+	return m.rewards[[2]int{state.Index(), nextState.Index()}]
+}
+
+func TestRelativeValueIterationIncludesTransitionRewards(t *testing.T) {
+	m, err := mdp.NewDefaultMDP()
+	assert.NoError(t, err)
+
+	a := mdp.NewAction("a")
+	b := mdp.NewAction("b")
+	back := mdp.NewAction("back")
+	start := mdp.NewState("start", 0, false)
+	left := mdp.NewState("left", 1, false)
+	right := mdp.NewState("right", 2, false)
+
+	// Both loops look alike by their state rewards, so only the transition reward makes "b" better
+	assert.NoError(t, m.AddStateObject(start, 0, map[mdp.Action]mdp.Transition{
+		a: mdp.NewTransition(1, left),
+		b: mdp.NewTransition(1, right),
+	}))
+	assert.NoError(t, m.AddStateObject(left, 0, map[mdp.Action]mdp.Transition{back: mdp.NewTransition(1, start)}))
+	assert.NoError(t, m.AddStateObject(right, 0, map[mdp.Action]mdp.Transition{back: mdp.NewTransition(1, start)}))
+	shaped := &transitionRewards{m, map[[2]int]float32{{0, 2}: 2}}
+
+	result, err := RelativeValueIteration(shaped, 1e-6, 10000)
+	assert.NoError(t, err)
+	assert.False(t, result.Multichain)
+	assert.InDelta(t, 1, result.Gain, 1e-4)
+	assert.Equal(t, "b", result.Policy.Action(start).Name())
+
+	result, err = RelativeValueIteration(m, 1e-6, 10000)
+	assert.NoError(t, err)
+	assert.Equal(t, "a", result.Policy.Action(start).Name())
+}
+
+func TestRelativeValueIterationDetectsMultichain(t *testing.T) {
+	m, err := mdp.NewDefaultMDP()
+	assert.NoError(t, err)
+
+	stay := mdp.NewAction("stay")
+	left := mdp.NewState("left", 0, false)
+	right := mdp.NewState("right", 1, false)
+	end := mdp.NewState("end", 2, true)
+
+	assert.NoError(t, m.AddStateObject(left, 1, map[mdp.Action]mdp.Transition{stay: mdp.NewTransition(1, left)}))
+	assert.NoError(t, m.AddStateObject(right, 3, map[mdp.Action]mdp.Transition{stay: mdp.NewTransition(1, right)}))
+	assert.NoError(t, m.AddStateObject(end, -1, nil))
+
+	result, err := RelativeValueIteration(m, 1e-6, 10000)
+	assert.NoError(t, err)
+	assert.True(t, result.Multichain)
+	assert.InDelta(t, 1, result.Gain, 1e-4)
+	assert.InDelta(t, 1, result.Gains[0], 1e-4)
+	assert.InDelta(t, 3, result.Gains[1], 1e-4)
+	assert.InDelta(t, -1, result.Gains[2], 1e-4)
+}
+
+func TestRelativeValueIterationValidatesArguments(t *testing.T) {
+	m, err := mdp.NewDefaultMDP()
+	assert.NoError(t, err)
+
+	_, err = RelativeValueIteration(m, 0, 10)
+	assert.Error(t, err)
+	_, err = RelativeValueIteration(m, 1e-6, 0)
+	assert.Error(t, err)
+	_, err = RelativeValueIteration(m, 1e-6, 10)
+	assert.Error(t, err)
+}
@@ -0,0 +1,83 @@
+package solver
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/anthonykrivonos/go-rl/mdp"
+)
+
+// ConstrainedResult is the solution of a constrained MDP.
+type ConstrainedResult struct {
+	OccupancyResult
+	// Costs maps each constrained cost channel to the policy's expected discounted cost in that channel.
+	Costs map[string]float32
+}
+
+// SolveConstrained solves a constrained discounted MDP: it maximizes the expected discounted return subject to the
+// expected discounted cost in each channel of `budgets` being at most its budget. Costs are read from the MDP's cost
+// channels, and the problem is solved as the dual linear program over occupancy measures, so the optimal policy may be
+// stochastic. `start` maps state indices to the probability of starting there; if nil, the process starts in the
+// initial state.
+// Returns the result and a nil error on success or returns nil and a non-nil error on failure, including when no policy
+// meets the budgets.
+func SolveConstrained(m mdp.MDP, budgets map[string]float32, start map[int]float32) (*ConstrainedResult, error) {
+	known := make(map[string]bool)
+	for _, channel := range m.CostChannels() {
+		known[channel] = true
+	}
+	channels := make([]string, 0, len(budgets))
+	for channel := range budgets {
+		if !known[channel] {
+			return nil, errors.New("cost channel " + channel + " doesn't exist")
+		}
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+
+	constraints := make([]OccupancyConstraint, len(channels))
+	for i, channel := range channels {
+		constraints[i] = OccupancyConstraint{Weight: costWeight(m, channel), Bound: budgets[channel]}
+	}
+
+	occupancy, err := SolveDualLP(m, start, constraints)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ConstrainedResult{}
+	result.OccupancyResult = *occupancy
+	result.Costs = make(map[string]float32)
+	for _, channel := range channels {
+		result.Costs[channel] = ExpectedCost(m, channel, occupancy)
+	}
+	return result, nil
+}
+
+// ExpectedCost returns the expected discounted cost in a channel of the policy with the given occupancy measure.
+func ExpectedCost(m mdp.MDP, channel string, occupancy *OccupancyResult) float32 {
+	weight := costWeight(m, channel)
+	total := float32(0)
+	for index, visits := range occupancy.Visits {
+		state := m.StateByIndex(index)
+		if actions, ok := occupancy.Occupancy[index]; ok {
+			for action, x := range actions {
+				total += weight(state, action) * x
+			}
+		} else {
+			total += weight(state, nil) * visits
+		}
+	}
+	return total
+}
+
+// costWeight returns the occupancy weight of a cost channel: the cost of taking an action in a state.
+func costWeight(m mdp.MDP, channel string) func(mdp.State, mdp.Action) float32 {
+	return func(state mdp.State, action mdp.Action) float32 {
+		name := ""
+		if action != nil {
+			name = action.Name()
+		}
+		return m.CByIndex(channel, state.Index(), name)
+	}
+}
@@ -0,0 +1,40 @@
+package solver
+
+import (
+	"testing"
+
+	"github.com/anthonykrivonos/go-rl/mdp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSolveConstrained(t *testing.T) {
+	m := newCorridorMDP(t)
+
+	// A shortcut from S0 straight to the goal is faster but risky
+	shortcut := mdp.NewAction("shortcut")
+	assert.NoError(t, m.AddActionObject(shortcut))
+	m.SetTransition("S0", "goal", "shortcut", 1)
+	assert.NoError(t, m.SetCost("risk", "S0", "shortcut", 1))
+	assert.NoError(t, m.SetCost("time", "S1", "", 1))
+
+	unconstrained, err := SolveDualLP(m, nil, nil)
+	assert.NoError(t, err)
+	assert.InDelta(t, 8, unconstrained.Objective, 1e-4)
+	assert.InDelta(t, 1, ExpectedCost(m, "risk", unconstrained), 1e-4)
+
+	result, err := SolveConstrained(m, map[string]float32{"risk": 0.25}, nil)
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.25, result.Costs["risk"], 1e-4)
+	assert.InDelta(t, 0.25*8+0.75*6.2, result.Objective, 1e-4)
+	assert.InDelta(t, 0.25, result.Policy.Probability(m.StateByName("S0"), shortcut), 1e-4)
+	assert.InDelta(t, 0.75, result.Policy.Probability(m.StateByName("S0"), mdp.NewAction("move")), 1e-4)
+
+	// Budgets on both channels: avoiding S1 and the shortcut at once means staying put
+	result, err = SolveConstrained(m, map[string]float32{"risk": 0, "time": 0}, nil)
+	assert.NoError(t, err)
+	assert.InDelta(t, 0, result.Costs["time"], 1e-4)
+	assert.InDelta(t, 1, result.Policy.Probability(m.StateByName("S0"), mdp.NewAction("stay")), 1e-4)
+
+	_, err = SolveConstrained(m, map[string]float32{"fuel": 1}, nil)
+	assert.Error(t, err)
+}
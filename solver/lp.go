@@ -0,0 +1,228 @@
+package solver
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/anthonykrivonos/go-rl/mdp"
+)
+
+// OccupancyConstraint is a linear constraint on the discounted occupancy measure of a policy:
+// Σ Weight(s, a) · x(s, a) ≤ Bound, where x(s, a) is the expected discounted number of times `a` is taken in `s`.
+type OccupancyConstraint struct {
+	// Weight returns the coefficient of the occupancy of `action` in `state`. `action` is nil for terminal states and
+	// states without actions, which are occupied without taking an action.
+	Weight func(state mdp.State, action mdp.Action) float32
+	// Bound is the largest allowed value of the weighted occupancy.
+	Bound float32
+}
+
+// VisitConstraint returns a constraint that the expected discounted number of visits to `state` is at most `bound`.
+func VisitConstraint(state mdp.State, bound float32) OccupancyConstraint {
+	return OccupancyConstraint{
+		Weight: func(s mdp.State, _ mdp.Action) float32 {
+			if s.Equals(state) {
+				return 1
+			}
+			return 0
+		},
+		Bound: bound,
+	}
+}
+
+// OccupancyResult is the solution of the dual linear program of a discounted MDP.
+type OccupancyResult struct {
+	// Objective is the expected discounted return from the start distribution.
+	Objective float32
+	// Visits maps each state index to its expected discounted number of visits.
+	Visits map[int]float32
+	// Occupancy maps each state index to the expected discounted number of times each action is taken there.
+	Occupancy map[int]map[mdp.Action]float32
+	// Policy takes each action in proportion to its occupancy. States that are never visited are not included.
+	Policy StochasticPolicy
+}
+
+// occupancyVariable is a variable of the dual linear program: the occupancy of an action in a state. Absorbing states
+// have a single variable with a nil action.
+type occupancyVariable struct {
+	state  int
+	action int
+}
+
+// SolvePrimalLP solves a discounted MDP with the primal linear program: minimize Σ V(s) subject to
+// V(s) ≥ R(s) + ɣ Σ P(s' | s, a) V(s') for every state and action. Terminal states are worth their reward and states
+// without actions stay where they are forever.
+// Returns the optimal state values, a greedy optimal policy and a nil error on success, or returns nils and a non-nil
+// error on failure.
+func SolvePrimalLP(m mdp.MDP) (map[int]float32, Policy, error) {
+	md, err := newModel(m)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Values are free, so each is split into a positive and a negative part: V(s) = x[2s] - x[2s + 1]
+	n := md.size()
+	lp := newLinearProgram(2 * n)
+	for s := 0; s < n; s++ {
+		lp.objective[2*s] = -1
+		lp.objective[2*s+1] = 1
+	}
+	addValueConstraint := func(weights map[int]float64, reward float64) {
+		row := make([]float64, 2*n)
+		for next, w := range weights {
+			row[2*next] += w
+			row[2*next+1] -= w
+		}
+		lp.addConstraint(row, greaterEqual, reward)
+	}
+	for s := 0; s < n; s++ {
+		if md.terminal[s] {
+			addValueConstraint(map[int]float64{s: 1}, md.rewards[s])
+			continue
+		} else if md.absorbing(s) {
+			addValueConstraint(map[int]float64{s: 1 - md.gamma}, md.rewards[s])
+			continue
+		}
+		for a := range md.actions[s] {
+			weights := map[int]float64{s: 1}
+			for _, o := range md.outcomes[s][a] {
+				weights[o.next] -= md.gamma * o.probability
+			}
+			addValueConstraint(weights, md.actionRewards[s][a])
+		}
+	}
+
+	x, _, err := lp.maximize()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	values := make([]float64, n)
+	for s := range values {
+		values[s] = x[2*s] - x[2*s+1]
+	}
+	return stateValues(md, values), qGreedyPolicy(md, values), nil
+}
+
+// SolveDualLP solves a discounted MDP with the dual linear program over occupancy measures: maximize
+// Σ R(s) x(s, a) subject to Σ_a x(s', a) - ɣ Σ_{s, a} P(s' | s, a) x(s, a) = start(s') for every state, x ≥ 0, and
+// every provided constraint. `start` maps state indices to the probability of starting there; if nil, the process
+// starts in the initial state.
+// Returns the result and a nil error on success or returns nil and a non-nil error on failure, including when the
+// constraints cannot be satisfied.
+func SolveDualLP(m mdp.MDP, start map[int]float32, constraints []OccupancyConstraint) (*OccupancyResult, error) {
+	md, err := newModel(m)
+	if err != nil {
+		return nil, err
+	}
+	lp, variables, err := occupancyProgram(md, m, start)
+	if err != nil {
+		return nil, err
+	}
+	for _, constraint := range constraints {
+		if constraint.Weight == nil {
+			return nil, errors.New("constraint weight must be provided")
+		}
+		row := make([]float64, len(variables))
+		for v, variable := range variables {
+			row[v] = float64(constraint.Weight(md.states[variable.state], md.action(variable.state, variable.action)))
+		}
+		lp.addConstraint(row, lessEqual, float64(constraint.Bound))
+	}
+
+	x, objective, err := lp.maximize()
+	if err != nil {
+		return nil, err
+	}
+	return occupancyResult(md, variables, x, objective), nil
+}
+
+// occupancyProgram builds the unconstrained dual linear program of a model, returning it along with the state and
+// action behind each of its variables.
+func occupancyProgram(md *model, m mdp.MDP, start map[int]float32) (*linearProgram, []occupancyVariable, error) {
+	n := md.size()
+	alpha := make([]float64, n)
+	if start == nil {
+		alpha[md.reference(m)] = 1
+	} else {
+		for index, p := range start {
+			s, ok := md.position[index]
+			if !ok {
+				return nil, nil, errors.New("start state " + fmt.Sprint(index) + " doesn't exist")
+			} else if p < 0 {
+				return nil, nil, errors.New("start probabilities must be non-negative")
+			}
+			alpha[s] = float64(p)
+		}
+	}
+
+	var variables []occupancyVariable
+	for s := 0; s < n; s++ {
+		if md.absorbing(s) {
+			variables = append(variables, occupancyVariable{s, -1})
+		}
+		for a := range md.actions[s] {
+			variables = append(variables, occupancyVariable{s, a})
+		}
+	}
+
+	lp := newLinearProgram(len(variables))
+	flow := make([][]float64, n)
+	for s := range flow {
+		flow[s] = make([]float64, len(variables))
+	}
+	for v, variable := range variables {
+		s := variable.state
+		flow[s][v] += 1
+		if variable.action < 0 {
+			lp.objective[v] = md.rewards[s]
+			if !md.terminal[s] {
+				flow[s][v] -= md.gamma
+			}
+			continue
+		}
+		lp.objective[v] = md.actionRewards[s][variable.action]
+		for _, o := range md.outcomes[s][variable.action] {
+			flow[o.next][v] -= md.gamma * o.probability
+		}
+	}
+	for s := 0; s < n; s++ {
+		lp.addConstraint(flow[s], equal, alpha[s])
+	}
+	return lp, variables, nil
+}
+
+// occupancyResult converts the solution of an occupancy program into an OccupancyResult.
+func occupancyResult(md *model, variables []occupancyVariable, x []float64, objective float64) *OccupancyResult {
+	result := &OccupancyResult{}
+	result.Objective = float32(objective)
+	result.Visits = make(map[int]float32)
+	result.Occupancy = make(map[int]map[mdp.Action]float32)
+	result.Policy = make(StochasticPolicy)
+
+	visits := make([]float64, md.size())
+	for v, variable := range variables {
+		visits[variable.state] += x[v]
+	}
+	for s, state := range md.states {
+		result.Visits[state.Index()] = float32(visits[s])
+	}
+	for v, variable := range variables {
+		if variable.action < 0 {
+			continue
+		}
+		state := md.states[variable.state]
+		action := md.actions[variable.state][variable.action]
+		if result.Occupancy[state.Index()] == nil {
+			result.Occupancy[state.Index()] = make(map[mdp.Action]float32)
+		}
+		result.Occupancy[state.Index()][action] = float32(x[v])
+		if visits[variable.state] > simplexEpsilon {
+			if result.Policy[state.Index()] == nil {
+				result.Policy[state.Index()] = make(map[mdp.Action]float32)
+			}
+			result.Policy[state.Index()][action] = float32(x[v] / visits[variable.state])
+		}
+	}
+	return result
+}
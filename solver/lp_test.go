@@ -0,0 +1,120 @@
+package solver
+
+import (
+	"testing"
+
+	"github.com/anthonykrivonos/go-rl/mdp"
+	"github.com/stretchr/testify/assert"
+)
+
+// newCorridorMDP creates a corridor S0 -> S1 -> goal where each step costs 1 and the goal is worth 10.
+func newCorridorMDP(t *testing.T) mdp.MDP {
+	m, err := mdp.NewDefaultMDP()
+	assert.NoError(t, err)
+	assert.NoError(t, m.SetDiscountRate(0.9))
+
+	stay := mdp.NewAction("stay")
+	move := mdp.NewAction("move")
+	s0 := mdp.NewState("S0", 0, false)
+	s1 := mdp.NewState("S1", 1, false)
+	goal := mdp.NewState("goal", 2, true)
+
+	assert.NoError(t, m.AddStateObject(s0, -1, map[mdp.Action]mdp.Transition{
+		stay: mdp.NewTransition(1, s0),
+		move: mdp.NewTransition(1, s1),
+	}))
+	assert.NoError(t, m.AddStateObject(s1, -1, map[mdp.Action]mdp.Transition{
+		stay: mdp.NewTransition(1, s1),
+		move: mdp.NewTransition(1, goal),
+	}))
+	assert.NoError(t, m.AddStateObject(goal, 10, nil))
+	return m
+}
+
+func TestSimplex(t *testing.T) {
+	// maximize 3x + 5y subject to x ≤ 4, 2y ≤ 12, 3x + 2y ≤ 18
+	lp := newLinearProgram(2)
+	lp.objective = []float64{3, 5}
+	lp.addConstraint([]float64{1, 0}, lessEqual, 4)
+	lp.addConstraint([]float64{0, 2}, lessEqual, 12)
+	lp.addConstraint([]float64{3, 2}, lessEqual, 18)
+	x, value, err := lp.maximize()
+	assert.NoError(t, err)
+	assert.InDelta(t, 36, value, 1e-9)
+	assert.InDelta(t, 2, x[0], 1e-9)
+	assert.InDelta(t, 6, x[1], 1e-9)
+
+	// Equality and lower-bound constraints need phase 1
+	lp = newLinearProgram(2)
+	lp.objective = []float64{-1, -1}
+	lp.addConstraint([]float64{1, 1}, greaterEqual, 2)
+	lp.addConstraint([]float64{1, -1}, equal, 1)
+	x, value, err = lp.maximize()
+	assert.NoError(t, err)
+	assert.InDelta(t, -2, value, 1e-9)
+	assert.InDelta(t, 1.5, x[0], 1e-9)
+	assert.InDelta(t, 0.5, x[1], 1e-9)
+
+	lp = newLinearProgram(1)
+	lp.addConstraint([]float64{1}, greaterEqual, 2)
+	lp.addConstraint([]float64{1}, lessEqual, 1)
+	_, _, err = lp.maximize()
+	assert.Error(t, err)
+
+	lp = newLinearProgram(1)
+	lp.objective = []float64{1}
+	_, _, err = lp.maximize()
+	assert.Error(t, err)
+}
+
+func TestSolvePrimalLP(t *testing.T) {
+	m := newCorridorMDP(t)
+
+	values, policy, err := SolvePrimalLP(m)
+	assert.NoError(t, err)
+	assert.InDelta(t, 6.2, values[0], 1e-4)
+	assert.InDelta(t, 8, values[1], 1e-4)
+	assert.InDelta(t, 10, values[2], 1e-4)
+	assert.Equal(t, "move", policy[0].Name())
+	assert.Equal(t, "move", policy[1].Name())
+}
+
+func TestSolveDualLP(t *testing.T) {
+	m := newCorridorMDP(t)
+
+	result, err := SolveDualLP(m, nil, nil)
+	assert.NoError(t, err)
+	assert.InDelta(t, 6.2, result.Objective, 1e-4)
+	assert.InDelta(t, 1, result.Visits[0], 1e-4)
+	assert.InDelta(t, 0.9, result.Visits[1], 1e-4)
+	assert.InDelta(t, 0.81, result.Visits[2], 1e-4)
+	assert.InDelta(t, 1, result.Policy.Probability(m.StateByIndex(0), mdp.NewAction("move")), 1e-4)
+
+	// Strong duality: the dual objective matches the primal values under the same start distribution
+	values, _, err := SolvePrimalLP(m)
+	assert.NoError(t, err)
+	result, err = SolveDualLP(m, map[int]float32{0: 0.5, 1: 0.5}, nil)
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.5*values[0]+0.5*values[1], result.Objective, 1e-4)
+}
+
+func TestSolveDualLPWithVisitConstraint(t *testing.T) {
+	m := newCorridorMDP(t)
+	s1 := m.StateByIndex(1)
+
+	result, err := SolveDualLP(m, nil, []OccupancyConstraint{VisitConstraint(m.StateByIndex(2), 0.5)})
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.5, result.Visits[2], 1e-4)
+	assert.InDelta(t, 4, result.Visits[1], 1e-4)
+	assert.InDelta(t, 0, result.Objective, 1e-4)
+
+	// Reaching the goal less often than the unconstrained policy does needs a stochastic policy
+	stay := result.Policy.Probability(s1, mdp.NewAction("stay"))
+	move := result.Policy.Probability(s1, mdp.NewAction("move"))
+	assert.InDelta(t, 1, stay+move, 1e-4)
+	assert.True(t, stay > 0 && move > 0)
+	assert.InDelta(t, 1, result.Policy.Probability(m.StateByIndex(0), mdp.NewAction("move")), 1e-4)
+
+	_, err = SolveDualLP(m, nil, []OccupancyConstraint{VisitConstraint(m.StateByIndex(0), 0.5)})
+	assert.Error(t, err)
+}
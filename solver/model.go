@@ -0,0 +1,134 @@
+package solver
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/anthonykrivonos/go-rl/mdp"
+)
+
+// outcome is one possible result of taking an action: the position of the next state and its probability.
+type outcome struct {
+	next        int
+	probability float64
+}
+
+// model is a dense snapshot of an MDP that the solvers iterate over. States are addressed by their position in
+// `states`, which is ordered by state index.
+type model struct {
+	states   []mdp.State
+	position map[int]int
+	rewards  []float64
+	terminal []bool
+	// actionRewards holds the expected immediate reward of each action: the state's reward plus any expected
+	// transition reward.
+	actionRewards [][]float64
+	actions       [][]mdp.Action
+	outcomes      [][][]outcome
+	gamma         float64
+	// predecessors holds the positions of the states with an action that can lead to each state.
+	predecessors [][]int
+}
+
+// newModel builds a dense model from an MDP. Terminal states and states without actions have no actions in the model.
+func newModel(m mdp.MDP) (*model, error) {
+	if m == nil {
+		return nil, errors.New("mdp must be provided")
+	}
+	states := m.States()
+	if len(states) == 0 {
+		return nil, errors.New("mdp has no states")
+	}
+
+	md := &model{}
+	md.states = states
+	md.position = make(map[int]int)
+	md.rewards = make([]float64, len(states))
+	md.terminal = make([]bool, len(states))
+	md.actions = make([][]mdp.Action, len(states))
+	md.outcomes = make([][][]outcome, len(states))
+	md.actionRewards = make([][]float64, len(states))
+	md.gamma = float64(m.DiscountRate())
+
+	for i, state := range states {
+		md.position[state.Index()] = i
+	}
+
+	rewarder, _ := m.(mdp.TransitionRewarder)
+	for i, state := range states {
+		md.rewards[i] = float64(m.RByIndex(state.Index()))
+		md.terminal[i] = state.Terminal()
+		if state.Terminal() {
+			continue
+		}
+		for _, action := range m.AvailableActions(state) {
+			var outcomes []outcome
+			reward := md.rewards[i]
+			for _, t := range mdp.Outcomes(state, m.TByIndex(state.Index(), action.Name())) {
+				next, ok := md.position[t.NextState().Index()]
+				if !ok {
+					return nil, errors.New("transition from " + state.String() + " via " + action.String() + " leads to unknown state " + fmt.Sprint(t.NextState().Index()))
+				}
+				outcomes = append(outcomes, outcome{next, float64(t.Probability())})
+				if rewarder != nil {
+					reward += float64(t.Probability()) * float64(rewarder.TransitionReward(state, states[next]))
+				}
+			}
+			md.actions[i] = append(md.actions[i], action)
+			md.outcomes[i] = append(md.outcomes[i], outcomes)
+			md.actionRewards[i] = append(md.actionRewards[i], reward)
+		}
+	}
+
+	md.predecessors = make([][]int, len(states))
+	for s := range states {
+		seen := make(map[int]bool)
+		for _, outcomes := range md.outcomes[s] {
+			for _, o := range outcomes {
+				if o.probability > 0 && !seen[o.next] {
+					seen[o.next] = true
+					md.predecessors[o.next] = append(md.predecessors[o.next], s)
+				}
+			}
+		}
+	}
+
+	return md, nil
+}
+
+// size returns the number of states in the model.
+func (md *model) size() int {
+	return len(md.states)
+}
+
+// absorbing returns whether the state at position `s` has no actions, and so stays where it is forever.
+func (md *model) absorbing(s int) bool {
+	return len(md.actions[s]) == 0
+}
+
+// action returns the action at position `a` in state `s`, or nil if `a` is negative.
+func (md *model) action(s, a int) mdp.Action {
+	if a < 0 {
+		return nil
+	}
+	return md.actions[s][a]
+}
+
+// expectation returns the expected value of `values` after taking the action at position `a` in state `s`.
+func (md *model) expectation(s, a int, values []float64) float64 {
+	total := 0.0
+	for _, o := range md.outcomes[s][a] {
+		total += o.probability * values[o.next]
+	}
+	return total
+}
+
+// reference returns the position of the MDP's initial state, or the first state if there is none.
+func (md *model) reference(m mdp.MDP) int {
+	if initial := m.InitialState(); initial != nil {
+		if s, ok := md.position[initial.Index()]; ok {
+			return s
+		}
+	}
+	return 0
+}
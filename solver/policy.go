@@ -0,0 +1,50 @@
+package solver
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/anthonykrivonos/go-rl/mdp"
+)
+
+// Policy maps state indices to the action to take in each state. Terminal states and states without actions are not
+// included.
+type Policy map[int]mdp.Action
+
+// Action returns the action the policy takes in `state`, or nil if the policy has none.
+func (p Policy) Action(state mdp.State) mdp.Action {
+	if a, ok := p[state.Index()]; ok {
+		return a
+	}
+	return nil
+}
+
+// String returns the policy as a list of state index to action name pairs, ordered by index.
+func (p Policy) String() string {
+	indices := make([]int, 0, len(p))
+	for index := range p {
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+	res := "{"
+	for i, index := range indices {
+		if i > 0 {
+			res += ", "
+		}
+		res += "S" + fmt.Sprint(index) + ": " + p[index].String()
+	}
+	return res + "}"
+}
+
+// StochasticPolicy maps state indices to the probability of taking each action in that state.
+type StochasticPolicy map[int]map[mdp.Action]float32
+
+// Probability returns the probability that the policy takes `action` in `state`.
+func (p StochasticPolicy) Probability(state mdp.State, action mdp.Action) float32 {
+	for a, probability := range p[state.Index()] {
+		if a.Equals(action) {
+			return probability
+		}
+	}
+	return 0
+}
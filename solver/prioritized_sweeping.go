@@ -0,0 +1,105 @@
+package solver
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/anthonykrivonos/go-rl/mdp"
+)
+
+// PrioritizedSweeping solves a discounted MDP like ValueIteration, but instead of sweeping every state it backs up one
+// state at a time, always choosing the state with the largest Bellman error. After each backup, the predecessors of the
+// backed-up state, and the state itself, have their Bellman errors recomputed, since theirs are the only ones that can
+// have changed. This saves work on large sparse MDPs, where most states barely change in most sweeps. Stops once no
+// state's Bellman error exceeds `tolerance`.
+// Returns the optimal state values, a greedy optimal policy and a nil error on success, or returns nils and a non-nil
+// error if that takes more than `maxBackups` backups.
+func PrioritizedSweeping(m mdp.MDP, tolerance float32, maxBackups int) (map[int]float32, Policy, error) {
+	if tolerance <= 0 {
+		return nil, nil, errors.New("tolerance must be positive")
+	} else if maxBackups <= 0 {
+		return nil, nil, errors.New("max backups must be positive")
+	}
+	md, err := newModel(m)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tol := float64(tolerance)
+	values := make([]float64, md.size())
+	queue := newStateQueue()
+	for s := range md.states {
+		if e := math.Abs(bellmanBackup(md, s, values) - values[s]); e > tol {
+			queue.push(s, e)
+		}
+	}
+
+	for backups := 0; queue.Len() > 0; backups++ {
+		if backups == maxBackups {
+			return nil, nil, errors.New("prioritized sweeping did not converge within " + fmt.Sprint(maxBackups) + " backups")
+		}
+		s := queue.pop()
+		values[s] = bellmanBackup(md, s, values)
+		for _, p := range append([]int{s}, md.predecessors[s]...) {
+			if e := math.Abs(bellmanBackup(md, p, values) - values[p]); e > tol {
+				queue.push(p, e)
+			}
+		}
+	}
+
+	return stateValues(md, values), qGreedyPolicy(md, values), nil
+}
+
+// stateQueue is a max-priority queue of state positions that holds each state at most once, at its latest priority.
+type stateQueue struct {
+	items      []int
+	priorities map[int]float64
+	positions  map[int]int
+}
+
+func newStateQueue() *stateQueue {
+	q := &stateQueue{}
+	q.priorities = make(map[int]float64)
+	q.positions = make(map[int]int)
+	return q
+}
+
+func (q *stateQueue) Len() int           { return len(q.items) }
+func (q *stateQueue) Less(i, j int) bool { return q.priorities[q.items[i]] > q.priorities[q.items[j]] }
+
+func (q *stateQueue) Swap(i, j int) {
+	q.items[i], q.items[j] = q.items[j], q.items[i]
+	q.positions[q.items[i]] = i
+	q.positions[q.items[j]] = j
+}
+
+func (q *stateQueue) Push(x interface{}) {
+	q.positions[x.(int)] = len(q.items)
+	q.items = append(q.items, x.(int))
+}
+
+func (q *stateQueue) Pop() interface{} {
+	last := q.items[len(q.items)-1]
+	q.items = q.items[:len(q.items)-1]
+	delete(q.positions, last)
+	return last
+}
+
+// push queues state `s` with `priority`, or updates its priority if it's already queued.
+func (q *stateQueue) push(s int, priority float64) {
+	q.priorities[s] = priority
+	if i, ok := q.positions[s]; ok {
+		heap.Fix(q, i)
+		return
+	}
+	heap.Push(q, s)
+}
+
+// pop removes and returns the highest-priority state.
+func (q *stateQueue) pop() int {
+	s := heap.Pop(q).(int)
+	delete(q.priorities, s)
+	return s
+}
@@ -0,0 +1,63 @@
+package solver
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/anthonykrivonos/go-rl/mdp"
+	"github.com/stretchr/testify/assert"
+)
+
+// newChainMDP creates a chain of `length` states where "forward" moves to the next state with probability 0.8, "back"
+// returns to the start, and the last state is a terminal worth 1. The start also holds a trap that loses 1 per step.
+func newChainMDP(t *testing.T, length int) mdp.MDP {
+	m, err := mdp.NewDefaultMDP()
+	assert.NoError(t, err)
+	assert.NoError(t, m.SetDiscountRate(0.95))
+
+	forward := mdp.NewAction("forward")
+	back := mdp.NewAction("back")
+	trap := mdp.NewAction("trap")
+	states := make([]mdp.State, length+1)
+	for i := 0; i < length; i++ {
+		states[i] = mdp.NewState(fmt.Sprint("S", i), i, i == length-1)
+	}
+	states[length] = mdp.NewState("trap", length, false)
+
+	for i := 0; i < length-1; i++ {
+		transitions := map[mdp.Action]mdp.Transition{
+			forward: mdp.NewTransition(0.8, states[i+1]),
+			back:    mdp.NewTransition(1, states[0]),
+		}
+		if i == 0 {
+			transitions[trap] = mdp.NewTransition(1, states[length])
+		}
+		assert.NoError(t, m.AddStateObject(states[i], 0, transitions))
+	}
+	assert.NoError(t, m.AddStateObject(states[length-1], 1, nil))
+	assert.NoError(t, m.AddStateObject(states[length], -1, nil))
+	return m
+}
+
+func TestPrioritizedSweeping(t *testing.T) {
+	m := newChainMDP(t, 50)
+
+	expected, expectedPolicy, err := ValueIteration(m, 1e-7, 10000)
+	assert.NoError(t, err)
+	values, policy, err := PrioritizedSweeping(m, 1e-7, 100000)
+	assert.NoError(t, err)
+
+	for index, value := range expected {
+		assert.InDelta(t, value, values[index], 1e-5, "state %d", index)
+	}
+	assert.Equal(t, expectedPolicy.String(), policy.String())
+	assert.Equal(t, "forward", policy[0].Name())
+
+	// States without actions stay put forever, so the trap is worth -1 / (1 - ɣ)
+	assert.InDelta(t, -20, values[50], 1e-4)
+
+	_, _, err = PrioritizedSweeping(m, 1e-7, 10)
+	assert.Error(t, err)
+	_, _, err = PrioritizedSweeping(m, 0, 10)
+	assert.Error(t, err)
+}
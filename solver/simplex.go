@@ -0,0 +1,234 @@
+package solver
+
+import (
+	"errors"
+	"math"
+)
+
+// simplexEpsilon is the tolerance below which tableau entries are treated as zero.
+const simplexEpsilon = 1e-9
+
+// constraintKind is the relation between a linear constraint's left-hand side and its bound.
+type constraintKind int
+
+const (
+	lessEqual constraintKind = iota
+	equal
+	greaterEqual
+)
+
+// linearProgram is a linear program over non-negative variables: maximize `objective`·x subject to each row's
+// coefficients·x being related to its bound by its kind.
+type linearProgram struct {
+	objective []float64
+	rows      [][]float64
+	kinds     []constraintKind
+	bounds    []float64
+}
+
+// newLinearProgram creates a linear program over `variables` non-negative variables with a zero objective.
+func newLinearProgram(variables int) *linearProgram {
+	lp := &linearProgram{}
+	lp.objective = make([]float64, variables)
+	return lp
+}
+
+// addConstraint adds the constraint coefficients·x (kind) bound.
+func (lp *linearProgram) addConstraint(coefficients []float64, kind constraintKind, bound float64) {
+	lp.rows = append(lp.rows, coefficients)
+	lp.kinds = append(lp.kinds, kind)
+	lp.bounds = append(lp.bounds, bound)
+}
+
+// maximize solves the linear program with the two-phase simplex method.
+// Returns the optimal variables, the optimal objective value and a nil error on success, or returns nil, 0 and a
+// non-nil error if the program is infeasible, unbounded or fails to converge.
+func (lp *linearProgram) maximize() ([]float64, float64, error) {
+	n := len(lp.objective)
+	m := len(lp.rows)
+
+	slacks, artificials := 0, 0
+	for _, kind := range lp.kinds {
+		if kind != equal {
+			slacks++
+		}
+		if kind != lessEqual {
+			artificials++
+		}
+	}
+
+	t := &tableau{}
+	t.cols = n + slacks + artificials
+	t.rows = make([][]float64, m)
+	t.basis = make([]int, m)
+	slack, artificial := n, n+slacks
+	for i := range lp.rows {
+		row := make([]float64, t.cols+1)
+		sign := 1.0
+		kind := lp.kinds[i]
+		if lp.bounds[i] < 0 {
+			// Keep the right-hand side non-negative so the starting basis is feasible
+			sign = -1
+			if kind == lessEqual {
+				kind = greaterEqual
+			} else if kind == greaterEqual {
+				kind = lessEqual
+			}
+		}
+		for j, c := range lp.rows[i] {
+			row[j] = sign * c
+		}
+		row[t.cols] = sign * lp.bounds[i]
+		switch kind {
+		case lessEqual:
+			row[slack] = 1
+			t.basis[i] = slack
+			slack++
+		case greaterEqual:
+			row[slack] = -1
+			slack++
+			row[artificial] = 1
+			t.basis[i] = artificial
+			artificial++
+		case equal:
+			row[artificial] = 1
+			t.basis[i] = artificial
+			artificial++
+		}
+		t.rows[i] = row
+	}
+
+	// Phase 1: drive the artificial variables to zero to find a feasible basis
+	if artificials > 0 {
+		cost := make([]float64, t.cols)
+		for j := n + slacks; j < t.cols; j++ {
+			cost[j] = -1
+		}
+		value, err := t.optimize(cost, t.cols)
+		if err != nil {
+			return nil, 0, err
+		}
+		scale := 1.0
+		for _, b := range lp.bounds {
+			scale = math.Max(scale, math.Abs(b))
+		}
+		if value < -1e-7*scale {
+			return nil, 0, errors.New("linear program is infeasible")
+		}
+		for i, b := range t.basis {
+			if b < n+slacks {
+				continue
+			}
+			for j := 0; j < n+slacks; j++ {
+				if math.Abs(t.rows[i][j]) > simplexEpsilon {
+					t.pivot(i, j, nil)
+					break
+				}
+			}
+		}
+	}
+
+	// Phase 2: optimize the real objective without letting artificial variables back into the basis
+	cost := make([]float64, t.cols)
+	copy(cost, lp.objective)
+	value, err := t.optimize(cost, n+slacks)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	x := make([]float64, n)
+	for i, b := range t.basis {
+		if b < n {
+			x[b] = t.rows[i][t.cols]
+		}
+	}
+	return x, value, nil
+}
+
+// tableau is a simplex tableau. Each row holds its coefficients followed by its right-hand side, and `basis` holds the
+// basic variable of each row.
+type tableau struct {
+	rows  [][]float64
+	basis []int
+	cols  int
+}
+
+// optimize maximizes `cost`·x from the tableau's current basic feasible solution, only letting the first `allowed`
+// variables enter the basis. Returns the optimal objective value.
+func (t *tableau) optimize(cost []float64, allowed int) (float64, error) {
+	// Price out the basic variables to get the reduced costs
+	reduced := make([]float64, t.cols+1)
+	copy(reduced, cost)
+	for i, b := range t.basis {
+		if cost[b] != 0 {
+			for j := range reduced {
+				reduced[j] -= cost[b] * t.rows[i][j]
+			}
+		}
+	}
+
+	maxIterations := 50 * (len(t.rows) + t.cols + 1)
+	degenerate := false
+	for iteration := 0; iteration < maxIterations; iteration++ {
+		// Choose the entering variable by largest reduced cost, or by Bland's rule after a degenerate pivot
+		enter := -1
+		for j := 0; j < allowed; j++ {
+			if reduced[j] > simplexEpsilon && (enter < 0 || (!degenerate && reduced[j] > reduced[enter])) {
+				enter = j
+				if degenerate {
+					break
+				}
+			}
+		}
+		if enter < 0 {
+			return -reduced[t.cols], nil
+		}
+
+		// Choose the leaving row by the minimum ratio test, breaking ties by smallest basic variable
+		leave := -1
+		ratio := math.Inf(1)
+		for i, row := range t.rows {
+			if row[enter] > simplexEpsilon {
+				r := row[t.cols] / row[enter]
+				if r < ratio-simplexEpsilon || (r < ratio+simplexEpsilon && leave >= 0 && t.basis[i] < t.basis[leave]) {
+					leave = i
+					ratio = r
+				}
+			}
+		}
+		if leave < 0 {
+			return 0, errors.New("linear program is unbounded")
+		}
+
+		degenerate = ratio < simplexEpsilon
+		t.pivot(leave, enter, reduced)
+	}
+	return 0, errors.New("simplex did not converge")
+}
+
+// pivot makes variable `col` basic in row `row`, updating the other rows and the reduced costs if provided.
+func (t *tableau) pivot(row, col int, reduced []float64) {
+	pivotRow := t.rows[row]
+	p := pivotRow[col]
+	for j := range pivotRow {
+		pivotRow[j] /= p
+	}
+	eliminate := func(target []float64) {
+		factor := target[col]
+		if factor == 0 {
+			return
+		}
+		for j := range target {
+			target[j] -= factor * pivotRow[j]
+		}
+	}
+	for i, r := range t.rows {
+		if i != row {
+			eliminate(r)
+		}
+	}
+	if reduced != nil {
+		eliminate(reduced)
+	}
+	t.basis[row] = col
+}
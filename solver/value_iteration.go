@@ -0,0 +1,91 @@
+package solver
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/anthonykrivonos/go-rl/mdp"
+)
+
+// ValueIteration solves a discounted MDP by repeatedly applying the Bellman optimality backup
+// V(s) = R(s) + max_a Σ P(s' | s, a) ɣ V(s') until no value changes by more than `tolerance`. Terminal states are worth
+// their reward and states without actions stay where they are forever. If the MDP implements mdp.TransitionRewarder,
+// the expected transition reward is added to each action's value.
+// Returns the optimal state values, a greedy optimal policy and a nil error on success, or returns nils and a non-nil
+// error on failure.
+func ValueIteration(m mdp.MDP, tolerance float32, maxIterations int) (map[int]float32, Policy, error) {
+	if tolerance <= 0 {
+		return nil, nil, errors.New("tolerance must be positive")
+	} else if maxIterations <= 0 {
+		return nil, nil, errors.New("max iterations must be positive")
+	}
+	md, err := newModel(m)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	values := make([]float64, md.size())
+	next := make([]float64, md.size())
+	for iteration := 1; iteration <= maxIterations; iteration++ {
+		change := 0.0
+		for s := range md.states {
+			next[s] = bellmanBackup(md, s, values)
+			change = math.Max(change, math.Abs(next[s]-values[s]))
+		}
+		values, next = next, values
+		if change < float64(tolerance) {
+			return stateValues(md, values), qGreedyPolicy(md, values), nil
+		}
+	}
+
+	return nil, nil, errors.New("value iteration did not converge within " + fmt.Sprint(maxIterations) + " iterations")
+}
+
+// bellmanBackup returns the Bellman optimality backup of state `s` under `values`.
+func bellmanBackup(md *model, s int, values []float64) float64 {
+	if md.terminal[s] {
+		return md.rewards[s]
+	} else if md.absorbing(s) {
+		return md.rewards[s] + md.gamma*values[s]
+	}
+	best := math.Inf(-1)
+	for a := range md.actions[s] {
+		best = math.Max(best, md.q(s, a, values))
+	}
+	return best
+}
+
+// q returns the value of taking the action at position `a` in state `s` under `values`.
+func (md *model) q(s, a int, values []float64) float64 {
+	return md.actionRewards[s][a] + md.gamma*md.expectation(s, a, values)
+}
+
+// qGreedyPolicy returns the policy that maximizes each action's value under `values`. Ties go to the action whose name
+// sorts first.
+func qGreedyPolicy(md *model, values []float64) Policy {
+	policy := make(Policy)
+	for s, state := range md.states {
+		best := -1
+		bestValue := math.Inf(-1)
+		for a := range md.actions[s] {
+			if v := md.q(s, a, values); v > bestValue+tieTolerance {
+				best = a
+				bestValue = v
+			}
+		}
+		if best >= 0 {
+			policy[state.Index()] = md.actions[s][best]
+		}
+	}
+	return policy
+}
+
+// stateValues converts dense values into a map from state index to value.
+func stateValues(md *model, values []float64) map[int]float32 {
+	result := make(map[int]float32)
+	for s, state := range md.states {
+		result[state.Index()] = float32(values[s])
+	}
+	return result
+}
@@ -0,0 +1,23 @@
+package solver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValueIteration(t *testing.T) {
+	m := newCorridorMDP(t)
+
+	values, policy, err := ValueIteration(m, 1e-6, 1000)
+	assert.NoError(t, err)
+	assert.InDelta(t, 6.2, values[0], 1e-4)
+	assert.InDelta(t, 8, values[1], 1e-4)
+	assert.InDelta(t, 10, values[2], 1e-4)
+	assert.Equal(t, "move", policy[0].Name())
+	assert.Equal(t, "move", policy[1].Name())
+	assert.Nil(t, policy[2])
+
+	_, _, err = ValueIteration(m, 1e-6, 1)
+	assert.Error(t, err)
+}
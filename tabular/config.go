@@ -0,0 +1,59 @@
+package tabular
+
+import (
+	"errors"
+	"math/rand"
+)
+
+var errPolicy = errors.New("policy must be provided")
+
+// Config holds the settings shared by the tabular learners and the linear learners built on them.
+type Config struct {
+	// LearningRate is the step size of each update, α (alpha).
+	LearningRate float32
+	// DiscountRate is the discount rate for learning, ɣ (gamma).
+	DiscountRate float32
+	// Explorer chooses actions from the learner's action values.
+	Explorer Explorer
+	// Random is the source of randomness for exploration.
+	Random *rand.Rand
+}
+
+// Validate returns an error describing the first invalid setting, or nil if the configuration is valid.
+func (c Config) Validate() error {
+	if err := c.ValidateRates(); err != nil {
+		return err
+	} else if c.Explorer == nil {
+		return errors.New("explorer must be provided")
+	} else if c.Random == nil {
+		return errors.New("random source must be provided")
+	}
+	return nil
+}
+
+// ValidateRates returns an error if the learning or discount rate is invalid. Prediction learners, which follow a fixed
+// policy instead of exploring, only need the rates.
+func (c Config) ValidateRates() error {
+	if c.LearningRate <= 0 || c.LearningRate > 1.0 {
+		return errors.New("learning rate must be in (0, 1.0]")
+	} else if c.DiscountRate <= 0 || c.DiscountRate > 1.0 {
+		return errors.New("discount rate must be in (0, 1.0]")
+	}
+	return nil
+}
+
+// validateN returns an error if `n` isn't a valid number of steps for an n-step learner.
+func validateN(n int) error {
+	if n <= 0 {
+		return errors.New("n must be positive")
+	}
+	return nil
+}
+
+// validateLambda returns an error if `lambda` isn't a valid trace decay rate.
+func validateLambda(lambda float32) error {
+	if lambda < 0 || lambda > 1.0 {
+		return errors.New("lambda must be in [0, 1.0]")
+	}
+	return nil
+}
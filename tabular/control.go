@@ -0,0 +1,154 @@
+package tabular
+
+import (
+	"github.com/anthonykrivonos/go-rl/env"
+	"github.com/anthonykrivonos/go-rl/mdp"
+)
+
+// onPolicy remembers the action an on-policy learner has already chosen for the next state, so that it takes the
+// action it learned from.
+type onPolicy struct {
+	state  mdp.State
+	action mdp.Action
+}
+
+// take returns the remembered action if it was chosen for `state`, or nil otherwise, and forgets it.
+func (o *onPolicy) take(state mdp.State) mdp.Action {
+	action := o.action
+	if action == nil || o.state == nil || !o.state.Equals(state) {
+		action = nil
+	}
+	o.state, o.action = nil, nil
+	return action
+}
+
+type nStepSARSA struct {
+	config Config
+	n      int
+	q      QTable
+	next   onPolicy
+	steps  []env.Step
+}
+
+// NewNStepSARSA creates an on-policy n-step SARSA agent, which moves Q(s, a) towards the discounted sum of the next `n`
+// rewards plus the discounted value of the state and action reached after them.
+func NewNStepSARSA(config Config, n int) (QLearner, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	} else if err := validateN(n); err != nil {
+		return nil, err
+	}
+	l := &nStepSARSA{}
+	l.config = config
+	l.n = n
+	l.q = NewQTable()
+	return l, nil
+}
+
+func (l *nStepSARSA) Act(state mdp.State, actions []mdp.Action) mdp.Action {
+	if action := l.next.take(state); action != nil {
+		return action
+	}
+	return l.config.Explorer.Choose(actions, l.q.Values(state, actions), l.config.Random)
+}
+
+func (l *nStepSARSA) Learn(step env.Step, nextActions []mdp.Action) {
+	l.steps = append(l.steps, step)
+	if step.Done || len(nextActions) == 0 {
+		l.flush(0)
+		return
+	}
+	l.next.state = step.NextState
+	l.next.action = l.config.Explorer.Choose(nextActions, l.q.Values(step.NextState, nextActions), l.config.Random)
+	if len(l.steps) == l.n {
+		l.update(l.q.Get(step.NextState, l.next.action))
+	}
+}
+
+func (l *nStepSARSA) EndEpisode() {
+	if len(l.steps) > 0 && l.next.action != nil {
+		l.flush(l.q.Get(l.next.state, l.next.action))
+	}
+	l.steps = nil
+	l.next = onPolicy{}
+}
+
+func (l *nStepSARSA) Q(state mdp.State, action mdp.Action) float32 {
+	return l.q.Get(state, action)
+}
+
+// update moves the value of the oldest pending step's state and action towards its n-step return, then drops that step.
+func (l *nStepSARSA) update(bootstrap float32) {
+	first := l.steps[0]
+	value := l.q.Get(first.State, first.Action)
+	target := nStepReturn(l.steps, l.config.DiscountRate, bootstrap)
+	l.q.Set(first.State, first.Action, value+l.config.LearningRate*(target-value))
+	l.steps = l.steps[1:]
+}
+
+// flush updates every pending step, bootstrapping from `bootstrap` after the last one.
+func (l *nStepSARSA) flush(bootstrap float32) {
+	for len(l.steps) > 0 {
+		l.update(bootstrap)
+	}
+	l.steps = nil
+}
+
+type sarsaLambda struct {
+	config      Config
+	lambda      float32
+	q           QTable
+	next        onPolicy
+	eligibility *eligibility
+}
+
+// NewSARSALambda creates an on-policy SARSA(λ) agent, which moves every recently taken state-action pair's value by each
+// step's TD error r + ɣQ(s', a') - Q(s, a), weighted by the pair's eligibility trace. Traces decay by ɣλ every step.
+func NewSARSALambda(config Config, lambda float32, trace Trace) (QLearner, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	} else if err := validateLambda(lambda); err != nil {
+		return nil, err
+	}
+	l := &sarsaLambda{}
+	l.config = config
+	l.lambda = lambda
+	l.q = NewQTable()
+	l.eligibility = newEligibility(trace)
+	return l, nil
+}
+
+func (l *sarsaLambda) Act(state mdp.State, actions []mdp.Action) mdp.Action {
+	if action := l.next.take(state); action != nil {
+		return action
+	}
+	return l.config.Explorer.Choose(actions, l.q.Values(state, actions), l.config.Random)
+}
+
+func (l *sarsaLambda) Learn(step env.Step, nextActions []mdp.Action) {
+	target := step.Reward
+	if !step.Done && len(nextActions) > 0 {
+		l.next.state = step.NextState
+		l.next.action = l.config.Explorer.Choose(nextActions, l.q.Values(step.NextState, nextActions), l.config.Random)
+		target += l.config.DiscountRate * l.q.Get(step.NextState, l.next.action)
+	}
+	delta := target - l.q.Get(step.State, step.Action)
+
+	l.eligibility.visit(step.State, step.Action, l.config.LearningRate)
+	for _, e := range l.eligibility.entries {
+		l.q.Set(e.state, e.action, l.q.Get(e.state, e.action)+l.config.LearningRate*delta*e.value)
+	}
+	l.eligibility.decay(l.config.DiscountRate * l.lambda)
+	if step.Done {
+		l.eligibility.clear()
+	}
+}
+
+func (l *sarsaLambda) EndEpisode() {
+	l.eligibility.clear()
+	l.next = onPolicy{}
+}
+
+func (l *sarsaLambda) Q(state mdp.State, action mdp.Action) float32 {
+	return l.q.Get(state, action)
+}
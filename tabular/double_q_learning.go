@@ -0,0 +1,57 @@
+package tabular
+
+import (
+	"github.com/anthonykrivonos/go-rl/env"
+	"github.com/anthonykrivonos/go-rl/mdp"
+)
+
+type doubleQLearning struct {
+	config Config
+	tables [2]QTable
+	turn   int
+}
+
+// NewDoubleQLearning creates a Double Q-learning agent, which counters the maximization bias of Q-learning by keeping
+// two action-value tables. The tables take turns being updated: the table being updated picks the best next action, and
+// the other table values it. The agent acts on the sum of the two tables, and Q returns their average.
+func NewDoubleQLearning(config Config) (QLearner, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	l := &doubleQLearning{}
+	l.config = config
+	l.tables = [2]QTable{NewQTable(), NewQTable()}
+	return l, nil
+}
+
+func (l *doubleQLearning) Act(state mdp.State, actions []mdp.Action) mdp.Action {
+	values := l.tables[0].Values(state, actions)
+	for i, v := range l.tables[1].Values(state, actions) {
+		values[i] += v
+	}
+	return l.config.Explorer.Choose(actions, values, l.config.Random)
+}
+
+func (l *doubleQLearning) Learn(step env.Step, nextActions []mdp.Action) {
+	update, other := l.tables[l.turn], l.tables[1-l.turn]
+	l.turn = 1 - l.turn
+
+	target := step.Reward
+	if !step.Done && len(nextActions) > 0 {
+		best := nextActions[0]
+		for _, action := range nextActions[1:] {
+			if update.Get(step.NextState, action) > update.Get(step.NextState, best) {
+				best = action
+			}
+		}
+		target += l.config.DiscountRate * other.Get(step.NextState, best)
+	}
+	value := update.Get(step.State, step.Action)
+	update.Set(step.State, step.Action, value+l.config.LearningRate*(target-value))
+}
+
+func (l *doubleQLearning) EndEpisode() {}
+
+func (l *doubleQLearning) Q(state mdp.State, action mdp.Action) float32 {
+	return (l.tables[0].Get(state, action) + l.tables[1].Get(state, action)) / 2
+}
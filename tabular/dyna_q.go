@@ -0,0 +1,344 @@
+package tabular
+
+import (
+	"container/heap"
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/anthonykrivonos/go-rl/env"
+	"github.com/anthonykrivonos/go-rl/mdp"
+)
+
+// DynaOptions holds the planning settings of a Dyna-Q agent.
+type DynaOptions struct {
+	// PlanningSteps is the number of simulated updates made from the learned model after every real step.
+	PlanningSteps int
+	// PrioritizedSweeping plans by updating the state-action pairs with the largest expected change first and then
+	// their predecessors, instead of sampling pairs uniformly at random.
+	PrioritizedSweeping bool
+	// Threshold is the smallest expected change for which a pair is queued when sweeping.
+	Threshold float32
+}
+
+// A Dyna-Q agent, which learns action values and a model of its environment at once.
+type DynaQLearner interface {
+	QLearner
+	// Model returns the learned model as an MDP. Its initial state is the first state the agent acted in, each state's
+	// reward is the average reward received on arriving there, and each action's transition is a distribution over
+	// every next state observed, with its observed frequency.
+	Model() (mdp.MDP, error)
+}
+
+// modelKey identifies a state-action pair in the learned model by state index and action name.
+type modelKey struct {
+	index  int
+	action string
+}
+
+// modelOutcome is a next state observed after a state-action pair, with how often it was observed and the total
+// reward received on those occasions.
+type modelOutcome struct {
+	state  mdp.State
+	done   bool
+	count  int
+	reward float32
+}
+
+// modelEntry is everything observed after taking an action in a state.
+type modelEntry struct {
+	state    mdp.State
+	action   mdp.Action
+	visits   int
+	outcomes []*modelOutcome
+}
+
+// outcome returns the recorded outcome that arrived in `state`, creating it if necessary.
+func (e *modelEntry) outcome(state mdp.State, done bool) *modelOutcome {
+	for _, o := range e.outcomes {
+		if o.state.Equals(state) {
+			return o
+		}
+	}
+	o := &modelOutcome{state: state, done: done}
+	e.outcomes = append(e.outcomes, o)
+	return o
+}
+
+type dynaQ struct {
+	config  Config
+	options DynaOptions
+	q       QTable
+
+	entries      map[modelKey]*modelEntry
+	keys         []modelKey
+	actions      map[int][]mdp.Action
+	predecessors map[int][]modelKey
+	initial      mdp.State
+
+	queue *priorityQueue
+}
+
+// NewDynaQ creates a Dyna-Q agent. After every real step it makes a Q-learning update, records the step in a tabular
+// model of the environment, and then makes `options.PlanningSteps` further updates from steps simulated by the model.
+func NewDynaQ(config Config, options DynaOptions) (DynaQLearner, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	} else if options.PlanningSteps < 0 {
+		return nil, errors.New("planning steps must be non-negative")
+	} else if options.Threshold < 0 {
+		return nil, errors.New("threshold must be non-negative")
+	}
+	l := &dynaQ{}
+	l.config = config
+	l.options = options
+	l.q = NewQTable()
+	l.entries = make(map[modelKey]*modelEntry)
+	l.actions = make(map[int][]mdp.Action)
+	l.predecessors = make(map[int][]modelKey)
+	l.queue = newPriorityQueue()
+	return l, nil
+}
+
+func (l *dynaQ) Act(state mdp.State, actions []mdp.Action) mdp.Action {
+	if l.initial == nil {
+		l.initial = state
+	}
+	l.actions[state.Index()] = actions
+	return l.config.Explorer.Choose(actions, l.q.Values(state, actions), l.config.Random)
+}
+
+func (l *dynaQ) Learn(step env.Step, nextActions []mdp.Action) {
+	if !step.Done {
+		l.actions[step.NextState.Index()] = nextActions
+	}
+	l.record(step)
+
+	key := modelKey{step.State.Index(), step.Action.Name()}
+	if l.options.PrioritizedSweeping {
+		l.prioritize(key)
+		l.sweep()
+		return
+	}
+
+	l.update(step.State, step.Action, step.Reward, step.NextState, step.Done)
+	for i := 0; i < l.options.PlanningSteps; i++ {
+		entry := l.entries[l.keys[l.config.Random.Intn(len(l.keys))]]
+		o := entry.sample(l.config.Random.Intn(entry.visits))
+		l.update(entry.state, entry.action, o.reward/float32(o.count), o.state, o.done)
+	}
+}
+
+func (l *dynaQ) EndEpisode() {}
+
+func (l *dynaQ) Q(state mdp.State, action mdp.Action) float32 {
+	return l.q.Get(state, action)
+}
+
+// record adds a real step to the learned model.
+func (l *dynaQ) record(step env.Step) {
+	key := modelKey{step.State.Index(), step.Action.Name()}
+	entry, ok := l.entries[key]
+	if !ok {
+		entry = &modelEntry{state: step.State, action: step.Action}
+		l.entries[key] = entry
+		l.keys = append(l.keys, key)
+	}
+	o := entry.outcome(step.NextState, step.Done)
+	if o.count == 0 {
+		next := step.NextState.Index()
+		l.predecessors[next] = append(l.predecessors[next], key)
+	}
+	o.count++
+	o.reward += step.Reward
+	entry.visits++
+}
+
+// sample returns the outcome that the `n`th of the entry's visits falls in, for `n` in [0, visits).
+func (e *modelEntry) sample(n int) *modelOutcome {
+	for _, o := range e.outcomes {
+		if n < o.count {
+			return o
+		}
+		n -= o.count
+	}
+	return e.outcomes[len(e.outcomes)-1]
+}
+
+// update makes a Q-learning update from a real or simulated step.
+func (l *dynaQ) update(state mdp.State, action mdp.Action, reward float32, nextState mdp.State, done bool) {
+	target := reward
+	if !done {
+		target += l.config.DiscountRate * l.q.Max(nextState, l.actions[nextState.Index()])
+	}
+	value := l.q.Get(state, action)
+	l.q.Set(state, action, value+l.config.LearningRate*(target-value))
+}
+
+// expectedTarget returns the expected Q-learning target of a state-action pair under the learned model.
+func (l *dynaQ) expectedTarget(entry *modelEntry) float32 {
+	target := float32(0)
+	for _, o := range entry.outcomes {
+		value := o.reward / float32(o.count)
+		if !o.done {
+			value += l.config.DiscountRate * l.q.Max(o.state, l.actions[o.state.Index()])
+		}
+		target += float32(o.count) / float32(entry.visits) * value
+	}
+	return target
+}
+
+// prioritize queues a state-action pair if its expected update is larger than the threshold.
+func (l *dynaQ) prioritize(key modelKey) {
+	entry := l.entries[key]
+	priority := float32(math.Abs(float64(l.expectedTarget(entry) - l.q.Get(entry.state, entry.action))))
+	if priority > l.options.Threshold {
+		l.queue.push(key, priority)
+	}
+}
+
+// sweep makes expected updates to the highest-priority pairs, queueing the predecessors of each updated state.
+func (l *dynaQ) sweep() {
+	for i := 0; i <= l.options.PlanningSteps && l.queue.Len() > 0; i++ {
+		entry := l.entries[l.queue.pop()]
+		value := l.q.Get(entry.state, entry.action)
+		l.q.Set(entry.state, entry.action, value+l.config.LearningRate*(l.expectedTarget(entry)-value))
+		for _, predecessor := range l.predecessors[entry.state.Index()] {
+			l.prioritize(predecessor)
+		}
+	}
+}
+
+func (l *dynaQ) Model() (mdp.MDP, error) {
+	m, err := mdp.NewDefaultMDP()
+	if err != nil {
+		return nil, err
+	}
+	if err = m.SetDiscountRate(l.config.DiscountRate); err != nil {
+		return nil, err
+	}
+
+	// Gather every observed state, whether it was left or arrived in
+	states := make(map[int]mdp.State)
+	terminal := make(map[int]bool)
+	arrivals := make(map[int]int)
+	rewards := make(map[int]float32)
+	for _, key := range l.keys {
+		entry := l.entries[key]
+		states[entry.state.Index()] = entry.state
+		for _, o := range entry.outcomes {
+			states[o.state.Index()] = o.state
+			terminal[o.state.Index()] = terminal[o.state.Index()] || o.done
+			arrivals[o.state.Index()] += o.count
+			rewards[o.state.Index()] += o.reward
+		}
+	}
+	if l.initial != nil {
+		states[l.initial.Index()] = l.initial
+	}
+	indices := make([]int, 0, len(states))
+	for index := range states {
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+
+	objects := make(map[int]mdp.State)
+	for _, index := range indices {
+		objects[index] = mdp.NewState(states[index].Name(), index, terminal[index])
+	}
+	// The MDP looks transitions up by action object, so every state must share one object per action name
+	actions := make(map[string]mdp.Action)
+	for _, key := range l.keys {
+		if _, ok := actions[key.action]; !ok {
+			actions[key.action] = mdp.NewAction(key.action)
+		}
+	}
+	for _, index := range indices {
+		transitions := make(map[mdp.Action]mdp.Transition)
+		for _, key := range l.keys {
+			if key.index != index {
+				continue
+			}
+			entry := l.entries[key]
+			outcomes := make([]mdp.Transition, len(entry.outcomes))
+			for i, o := range entry.outcomes {
+				outcomes[i] = mdp.NewTransition(float32(o.count)/float32(entry.visits), objects[o.state.Index()])
+			}
+			transitions[actions[key.action]] = mdp.NewDistribution(outcomes...)
+		}
+		reward := float32(0)
+		if arrivals[index] > 0 {
+			reward = rewards[index] / float32(arrivals[index])
+		}
+		// States are set in order of index, so no later state at index 0 can take over as the initial state
+		set := m.SetStateObject
+		if l.initial != nil && index == l.initial.Index() {
+			set = m.SetInitialStateObject
+		}
+		if err = set(objects[index], reward, transitions); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// priorityQueue is a max-priority queue of state-action pairs that holds each pair at most once, at its highest
+// priority so far.
+type priorityQueue struct {
+	items      []modelKey
+	priorities map[modelKey]float32
+	positions  map[modelKey]int
+}
+
+func newPriorityQueue() *priorityQueue {
+	q := &priorityQueue{}
+	q.priorities = make(map[modelKey]float32)
+	q.positions = make(map[modelKey]int)
+	return q
+}
+
+func (q *priorityQueue) Len() int {
+	return len(q.items)
+}
+
+func (q *priorityQueue) Less(i, j int) bool {
+	return q.priorities[q.items[i]] > q.priorities[q.items[j]]
+}
+
+func (q *priorityQueue) Swap(i, j int) {
+	q.items[i], q.items[j] = q.items[j], q.items[i]
+	q.positions[q.items[i]] = i
+	q.positions[q.items[j]] = j
+}
+
+func (q *priorityQueue) Push(x interface{}) {
+	q.positions[x.(modelKey)] = len(q.items)
+	q.items = append(q.items, x.(modelKey))
+}
+
+func (q *priorityQueue) Pop() interface{} {
+	last := q.items[len(q.items)-1]
+	q.items = q.items[:len(q.items)-1]
+	delete(q.positions, last)
+	return last
+}
+
+// push queues `key` with `priority`, or raises its priority if it's already queued with a lower one.
+func (q *priorityQueue) push(key modelKey, priority float32) {
+	if current, ok := q.priorities[key]; ok {
+		if priority > current {
+			q.priorities[key] = priority
+			heap.Fix(q, q.positions[key])
+		}
+		return
+	}
+	q.priorities[key] = priority
+	heap.Push(q, key)
+}
+
+// pop removes and returns the highest-priority pair.
+func (q *priorityQueue) pop() modelKey {
+	key := heap.Pop(q).(modelKey)
+	delete(q.priorities, key)
+	return key
+}
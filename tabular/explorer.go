@@ -0,0 +1,79 @@
+package tabular
+
+import (
+	"errors"
+	"math/rand"
+
+	"github.com/anthonykrivonos/go-rl/mdp"
+	"github.com/anthonykrivonos/go-rl/utils"
+)
+
+// An exploration policy, which chooses an action given the learner's current value of each available action.
+type Explorer interface {
+	Choose(actions []mdp.Action, values []float32, rng *rand.Rand) mdp.Action
+}
+
+type greedy struct{}
+
+// NewGreedy creates an Explorer that always takes the highest-valued action, breaking ties at random.
+func NewGreedy() Explorer {
+	return &greedy{}
+}
+
+func (g *greedy) Choose(actions []mdp.Action, values []float32, rng *rand.Rand) mdp.Action {
+	return actions[argmax(values, rng)]
+}
+
+type epsilonGreedy struct {
+	epsilon float32
+}
+
+// NewEpsilonGreedy creates an Explorer that takes a uniformly random action with probability `epsilon` and otherwise
+// takes the highest-valued action, breaking ties at random.
+func NewEpsilonGreedy(epsilon float32) (Explorer, error) {
+	if epsilon < 0 || epsilon > 1.0 {
+		return nil, errors.New("epsilon must be in [0, 1.0]")
+	}
+	e := &epsilonGreedy{}
+	e.epsilon = epsilon
+	return e, nil
+}
+
+func (e *epsilonGreedy) Choose(actions []mdp.Action, values []float32, rng *rand.Rand) mdp.Action {
+	if rng.Float32() < e.epsilon {
+		return actions[rng.Intn(len(actions))]
+	}
+	return actions[argmax(values, rng)]
+}
+
+type softmax struct {
+	temperature float32
+}
+
+// NewSoftmax creates an Explorer that takes each action with probability proportional to exp(value / temperature).
+func NewSoftmax(temperature float32) (Explorer, error) {
+	if temperature <= 0 {
+		return nil, errors.New("temperature must be positive")
+	}
+	s := &softmax{}
+	s.temperature = temperature
+	return s, nil
+}
+
+func (s *softmax) Choose(actions []mdp.Action, values []float32, rng *rand.Rand) mdp.Action {
+	return actions[utils.SampleCategorical(utils.Softmax(widen(values), float64(s.temperature)), rng)]
+}
+
+// argmax returns the position of the largest value, breaking ties uniformly at random.
+func argmax(values []float32, rng *rand.Rand) int {
+	return utils.Argmax(widen(values), rng)
+}
+
+// widen converts `values` to float64.
+func widen(values []float32) []float64 {
+	res := make([]float64, len(values))
+	for i, v := range values {
+		res[i] = float64(v)
+	}
+	return res
+}
@@ -0,0 +1,151 @@
+package tabular
+
+import (
+	"github.com/anthonykrivonos/go-rl/env"
+	"github.com/anthonykrivonos/go-rl/mdp"
+)
+
+// Policy chooses an action in a state. Prediction learners evaluate a fixed Policy.
+type Policy func(state mdp.State, actions []mdp.Action) mdp.Action
+
+// A tabular learner of the state values of a fixed policy.
+type VLearner interface {
+	env.Agent
+	// V returns the learner's estimate of the value of `state` under its policy.
+	V(state mdp.State) float32
+}
+
+// nStepReturn returns the discounted sum of the steps' rewards plus the discounted `bootstrap` value of the state after
+// the last step.
+func nStepReturn(steps []env.Step, discountRate float32, bootstrap float32) float32 {
+	total := float32(0)
+	discount := float32(1)
+	for _, step := range steps {
+		total += discount * step.Reward
+		discount *= discountRate
+	}
+	return total + discount*bootstrap
+}
+
+type nStepTD struct {
+	config Config
+	n      int
+	policy Policy
+	v      map[int]float32
+	steps  []env.Step
+}
+
+// NewNStepTD creates an n-step TD prediction agent, which follows `policy` and moves V(s) towards the discounted sum of
+// the next `n` rewards plus the discounted value of the state reached after them. The explorer and random source of
+// `config` are unused.
+func NewNStepTD(config Config, n int, policy Policy) (VLearner, error) {
+	if err := config.ValidateRates(); err != nil {
+		return nil, err
+	} else if err := validateN(n); err != nil {
+		return nil, err
+	} else if policy == nil {
+		return nil, errPolicy
+	}
+	l := &nStepTD{}
+	l.config = config
+	l.n = n
+	l.policy = policy
+	l.v = make(map[int]float32)
+	return l, nil
+}
+
+func (l *nStepTD) Act(state mdp.State, actions []mdp.Action) mdp.Action {
+	return l.policy(state, actions)
+}
+
+func (l *nStepTD) Learn(step env.Step, nextActions []mdp.Action) {
+	l.steps = append(l.steps, step)
+	if step.Done || len(nextActions) == 0 {
+		l.flush(0)
+	} else if len(l.steps) == l.n {
+		l.update(l.v[step.NextState.Index()])
+	}
+}
+
+func (l *nStepTD) EndEpisode() {
+	if len(l.steps) > 0 {
+		l.flush(l.v[l.steps[len(l.steps)-1].NextState.Index()])
+	}
+}
+
+func (l *nStepTD) V(state mdp.State) float32 {
+	return l.v[state.Index()]
+}
+
+// update moves the value of the oldest pending step's state towards its n-step return, then drops that step.
+func (l *nStepTD) update(bootstrap float32) {
+	index := l.steps[0].State.Index()
+	target := nStepReturn(l.steps, l.config.DiscountRate, bootstrap)
+	l.v[index] += l.config.LearningRate * (target - l.v[index])
+	l.steps = l.steps[1:]
+}
+
+// flush updates every pending step, bootstrapping from `bootstrap` after the last one.
+func (l *nStepTD) flush(bootstrap float32) {
+	for len(l.steps) > 0 {
+		l.update(bootstrap)
+	}
+	l.steps = nil
+}
+
+type tdLambda struct {
+	config      Config
+	lambda      float32
+	policy      Policy
+	v           map[int]float32
+	eligibility *eligibility
+}
+
+// NewTDLambda creates a TD(λ) prediction agent, which follows `policy` and moves every recently visited state's value by
+// each step's TD error r + ɣV(s') - V(s), weighted by the state's eligibility trace. Traces decay by ɣλ every step.
+// The explorer and random source of `config` are unused.
+func NewTDLambda(config Config, lambda float32, trace Trace, policy Policy) (VLearner, error) {
+	if err := config.ValidateRates(); err != nil {
+		return nil, err
+	} else if err := validateLambda(lambda); err != nil {
+		return nil, err
+	} else if policy == nil {
+		return nil, errPolicy
+	}
+	l := &tdLambda{}
+	l.config = config
+	l.lambda = lambda
+	l.policy = policy
+	l.v = make(map[int]float32)
+	l.eligibility = newEligibility(trace)
+	return l, nil
+}
+
+func (l *tdLambda) Act(state mdp.State, actions []mdp.Action) mdp.Action {
+	return l.policy(state, actions)
+}
+
+func (l *tdLambda) Learn(step env.Step, nextActions []mdp.Action) {
+	target := step.Reward
+	if !step.Done {
+		target += l.config.DiscountRate * l.v[step.NextState.Index()]
+	}
+	delta := target - l.v[step.State.Index()]
+
+	l.eligibility.visit(step.State, nil, l.config.LearningRate)
+	for _, e := range l.eligibility.entries {
+		l.v[e.state.Index()] += l.config.LearningRate * delta * e.value
+	}
+	l.eligibility.decay(l.config.DiscountRate * l.lambda)
+	if step.Done {
+		l.eligibility.clear()
+	}
+}
+
+func (l *tdLambda) EndEpisode() {
+	l.eligibility.clear()
+}
+
+func (l *tdLambda) V(state mdp.State) float32 {
+	return l.v[state.Index()]
+}
@@ -0,0 +1,48 @@
+package tabular
+
+import (
+	"github.com/anthonykrivonos/go-rl/env"
+	"github.com/anthonykrivonos/go-rl/mdp"
+)
+
+// A tabular learner of action values.
+type QLearner interface {
+	env.Agent
+	// Q returns the learner's estimate of the value of taking `action` in `state`.
+	Q(state mdp.State, action mdp.Action) float32
+}
+
+type qLearning struct {
+	config Config
+	q      QTable
+}
+
+// NewQLearning creates an off-policy Q-learning agent, which moves Q(s, a) towards r + ɣ max_a' Q(s', a').
+func NewQLearning(config Config) (QLearner, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	l := &qLearning{}
+	l.config = config
+	l.q = NewQTable()
+	return l, nil
+}
+
+func (l *qLearning) Act(state mdp.State, actions []mdp.Action) mdp.Action {
+	return l.config.Explorer.Choose(actions, l.q.Values(state, actions), l.config.Random)
+}
+
+func (l *qLearning) Learn(step env.Step, nextActions []mdp.Action) {
+	target := step.Reward
+	if !step.Done {
+		target += l.config.DiscountRate * l.q.Max(step.NextState, nextActions)
+	}
+	value := l.q.Get(step.State, step.Action)
+	l.q.Set(step.State, step.Action, value+l.config.LearningRate*(target-value))
+}
+
+func (l *qLearning) EndEpisode() {}
+
+func (l *qLearning) Q(state mdp.State, action mdp.Action) float32 {
+	return l.q.Get(state, action)
+}
@@ -0,0 +1,82 @@
+package tabular
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/anthonykrivonos/go-rl/mdp"
+)
+
+// A table of action values, indexed by state index and action name. Unset values are 0.
+type QTable interface {
+	Get(state mdp.State, action mdp.Action) float32
+	Set(state mdp.State, action mdp.Action, value float32)
+	Values(state mdp.State, actions []mdp.Action) []float32
+	Max(state mdp.State, actions []mdp.Action) float32
+	String(prefix string) string
+}
+
+type qTable struct {
+	table map[int]map[string]float32
+}
+
+func (q *qTable) Get(state mdp.State, action mdp.Action) float32 {
+	return q.table[state.Index()][action.Name()]
+}
+
+func (q *qTable) Set(state mdp.State, action mdp.Action, value float32) {
+	if _, ok := q.table[state.Index()]; !ok {
+		q.table[state.Index()] = make(map[string]float32)
+	}
+	q.table[state.Index()][action.Name()] = value
+}
+
+func (q *qTable) Values(state mdp.State, actions []mdp.Action) []float32 {
+	values := make([]float32, len(actions))
+	for i, action := range actions {
+		values[i] = q.Get(state, action)
+	}
+	return values
+}
+
+// Max returns the largest value of the provided actions in `state`, or 0 if there are no actions.
+func (q *qTable) Max(state mdp.State, actions []mdp.Action) float32 {
+	if len(actions) == 0 {
+		return 0
+	}
+	best := q.Get(state, actions[0])
+	for _, action := range actions[1:] {
+		if v := q.Get(state, action); v > best {
+			best = v
+		}
+	}
+	return best
+}
+
+func (q *qTable) String(prefix string) string {
+	indices := make([]int, 0, len(q.table))
+	for index := range q.table {
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+	res := "{\n"
+	for _, index := range indices {
+		names := make([]string, 0, len(q.table[index]))
+		for name := range q.table[index] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			res += prefix + "	S" + fmt.Sprint(index) + ", " + name + ": " + fmt.Sprintf("%.4f", q.table[index][name]) + ",\n"
+		}
+	}
+	res = res[:len(res)-2]
+	res += "\n" + prefix + "}"
+	return res
+}
+
+func NewQTable() QTable {
+	q := &qTable{}
+	q.table = make(map[int]map[string]float32)
+	return q
+}
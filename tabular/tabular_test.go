@@ -0,0 +1,525 @@
+package tabular
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/anthonykrivonos/go-rl/env"
+	"github.com/anthonykrivonos/go-rl/mdp"
+	"github.com/anthonykrivonos/go-rl/solver"
+	"github.com/stretchr/testify/assert"
+)
+
+// maximizationBias is the environment from Sutton & Barto's example 6.7. From A, going right ends the episode and going
+// left moves to B, from which every action ends the episode with a reward drawn from N(-0.1, 1). Going left is worse on
+// average, but the best of B's noisy rewards looks better than 0 to a learner that maximizes over its estimates.
+type maximizationBias struct {
+	rng     *rand.Rand
+	a, b, t mdp.State
+	current mdp.State
+}
+
+func newMaximizationBias(rng *rand.Rand) *maximizationBias {
+	e := &maximizationBias{}
+	e.rng = rng
+	e.a = mdp.NewState("A", 0, false)
+	e.b = mdp.NewState("B", 1, false)
+	e.t = mdp.NewState("T", 2, true)
+	return e
+}
+
+func (e *maximizationBias) Actions() []mdp.Action {
+	switch e.current {
+	case e.a:
+		return mdp.NewActions([]string{"left", "right"})
+	case e.b:
+		var names []string
+		for i := 0; i < 10; i++ {
+			names = append(names, fmt.Sprint("b", i))
+		}
+		return mdp.NewActions(names)
+	}
+	return nil
+}
+
+func (e *maximizationBias) Reset() (mdp.State, error) {
+	e.current = e.a
+	return e.current, nil
+}
+
+func (e *maximizationBias) Step(action mdp.Action) (mdp.State, float32, bool, error) {
+	if e.current == e.a && action.Name() == "left" {
+		e.current = e.b
+		return e.current, 0, false, nil
+	}
+	reward := float32(0)
+	if e.current == e.b {
+		reward = float32(e.rng.NormFloat64() - 0.1)
+	}
+	e.current = e.t
+	return e.current, reward, true, nil
+}
+
+// newCorridorEnvironment creates an environment over a corridor of `length` states where moving right reaches a goal
+// worth 1 at the far end and moving left stays put at the near end.
+func newCorridorEnvironment(t *testing.T, length int, rng *rand.Rand) env.Environment {
+	m, err := mdp.NewDefaultMDP()
+	assert.NoError(t, err)
+	left := mdp.NewAction("left")
+	right := mdp.NewAction("right")
+	states := make([]mdp.State, length)
+	for i := range states {
+		states[i] = mdp.NewState(fmt.Sprint("C", i), i, i == length-1)
+	}
+	for i, state := range states {
+		if state.Terminal() {
+			assert.NoError(t, m.AddStateObject(state, 1, nil))
+			continue
+		}
+		previous := states[i]
+		if i > 0 {
+			previous = states[i-1]
+		}
+		assert.NoError(t, m.AddStateObject(state, 0, map[mdp.Action]mdp.Transition{
+			left:  mdp.NewTransition(1, previous),
+			right: mdp.NewTransition(1, states[i+1]),
+		}))
+	}
+	e, err := env.NewMDPEnvironment(m, rng)
+	assert.NoError(t, err)
+	return e
+}
+
+// newConfig creates a configuration with epsilon-greedy exploration.
+func newConfig(t *testing.T, learningRate, discountRate, epsilon float32, seed int64) Config {
+	explorer, err := NewEpsilonGreedy(epsilon)
+	assert.NoError(t, err)
+	return Config{
+		LearningRate: learningRate,
+		DiscountRate: discountRate,
+		Explorer:     explorer,
+		Random:       rand.New(rand.NewSource(seed)),
+	}
+}
+
+// greedyPath follows the learner's greedy actions from the start of the environment and returns the actions taken.
+func greedyPath(t *testing.T, e env.Environment, learner QLearner, maxSteps int) []string {
+	state, err := e.Reset()
+	assert.NoError(t, err)
+	var path []string
+	for step := 0; step < maxSteps; step++ {
+		actions := e.Actions()
+		if len(actions) == 0 {
+			break
+		}
+		values := make([]float32, len(actions))
+		for i, action := range actions {
+			values[i] = learner.Q(state, action)
+		}
+		action := NewGreedy().Choose(actions, values, rand.New(rand.NewSource(0)))
+		path = append(path, action.Name())
+		var done bool
+		state, _, done, err = e.Step(action)
+		assert.NoError(t, err)
+		if done {
+			break
+		}
+	}
+	return path
+}
+
+func TestConfigValidation(t *testing.T) {
+	config := newConfig(t, 0.1, 0.9, 0.1, 0)
+	assert.NoError(t, config.Validate())
+
+	bad := config
+	bad.LearningRate = 0
+	assert.Error(t, bad.Validate())
+	bad = config
+	bad.DiscountRate = 1.5
+	assert.Error(t, bad.Validate())
+	bad = config
+	bad.Explorer = nil
+	assert.Error(t, bad.Validate())
+	bad = config
+	bad.Random = nil
+	assert.Error(t, bad.Validate())
+
+	_, err := NewEpsilonGreedy(2)
+	assert.Error(t, err)
+	_, err = NewSoftmax(0)
+	assert.Error(t, err)
+}
+
+func TestExplorers(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	actions := mdp.NewActions([]string{"a", "b", "c"})
+	values := []float32{0, 1, 1}
+
+	counts := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		counts[NewGreedy().Choose(actions, values, rng).Name()]++
+	}
+	assert.Equal(t, 0, counts["a"])
+	assert.InDelta(t, 500, counts["b"], 100)
+
+	softmax, err := NewSoftmax(1)
+	assert.NoError(t, err)
+	counts = make(map[string]int)
+	for i := 0; i < 10000; i++ {
+		counts[softmax.Choose(actions, values, rng).Name()]++
+	}
+	// exp(0) / (exp(0) + 2 exp(1)) ≈ 0.155
+	assert.InDelta(t, 1550, counts["a"], 200)
+}
+
+func TestQLearningCorridor(t *testing.T) {
+	config := newConfig(t, 0.5, 0.9, 0.2, 1)
+	e := newCorridorEnvironment(t, 6, rand.New(rand.NewSource(2)))
+	learner, err := NewQLearning(config)
+	assert.NoError(t, err)
+
+	_, err = env.Train(e, learner, 200, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"right", "right", "right", "right", "right"}, greedyPath(t, e, learner, 10))
+	assert.InDelta(t, 0.9*0.9*0.9*0.9, learner.Q(mdp.NewState("C0", 0, false), mdp.NewAction("right")), 1e-2)
+}
+
+func TestDoubleQLearningReducesMaximizationBias(t *testing.T) {
+	// Measure how often going left is chosen over many independent runs
+	lefts := func(newLearner func(Config) (QLearner, error)) float32 {
+		runs, episodes, count := 100, 300, 0
+		for run := 0; run < runs; run++ {
+			learner, err := newLearner(newConfig(t, 0.1, 1, 0.1, int64(run)))
+			assert.NoError(t, err)
+			e := newMaximizationBias(rand.New(rand.NewSource(int64(run))))
+			for episode := 0; episode < episodes; episode++ {
+				trajectory, err := env.RunEpisode(e, learner, 10)
+				assert.NoError(t, err)
+				if trajectory[0].Action.Name() == "left" {
+					count++
+				}
+			}
+		}
+		return float32(count) / float32(runs*episodes)
+	}
+
+	// Going left is worth -0.1, but Q-learning overestimates it and goes left far more often
+	qLefts := lefts(NewQLearning)
+	doubleLefts := lefts(NewDoubleQLearning)
+	assert.True(t, qLefts > 2*doubleLefts, "Q-learning %f, Double Q-learning %f", qLefts, doubleLefts)
+	assert.True(t, doubleLefts < 0.2, "Double Q-learning %f", doubleLefts)
+}
+
+func TestDoubleQLearningCorridor(t *testing.T) {
+	config := newConfig(t, 0.5, 0.9, 0.2, 1)
+	e := newCorridorEnvironment(t, 6, rand.New(rand.NewSource(2)))
+	learner, err := NewDoubleQLearning(config)
+	assert.NoError(t, err)
+
+	_, err = env.Train(e, learner, 300, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"right", "right", "right", "right", "right"}, greedyPath(t, e, learner, 10))
+}
+
+// alwaysRight is a policy that moves right down a corridor.
+func alwaysRight(_ mdp.State, actions []mdp.Action) mdp.Action {
+	for _, action := range actions {
+		if action.Name() == "right" {
+			return action
+		}
+	}
+	return actions[0]
+}
+
+// corridorStates returns the states of a corridor created by newCorridorEnvironment.
+func corridorStates(length int) []mdp.State {
+	states := make([]mdp.State, length)
+	for i := range states {
+		states[i] = mdp.NewState(fmt.Sprint("C", i), i, i == length-1)
+	}
+	return states
+}
+
+func TestNStepTDPropagatesFurther(t *testing.T) {
+	length := 10
+	states := corridorStates(length)
+	config := newConfig(t, 1, 0.9, 0, 0)
+
+	// After one episode with α = 1, an n-step learner has seen the goal's reward from the last n states
+	for _, n := range []int{1, 4} {
+		e := newCorridorEnvironment(t, length, rand.New(rand.NewSource(0)))
+		learner, err := NewNStepTD(config, n, alwaysRight)
+		assert.NoError(t, err)
+		_, err = env.RunEpisode(e, learner, 100)
+		assert.NoError(t, err)
+		for i := 0; i < length-1; i++ {
+			if i >= length-1-n {
+				assert.True(t, learner.V(states[i]) > 0, "n = %d, state %d", n, i)
+			} else {
+				assert.Equal(t, float32(0), learner.V(states[i]), "n = %d, state %d", n, i)
+			}
+		}
+	}
+
+	// With more episodes, values converge to the discounted reward of the goal
+	config.LearningRate = 0.5
+	e := newCorridorEnvironment(t, length, rand.New(rand.NewSource(0)))
+	learner, err := NewNStepTD(config, 3, alwaysRight)
+	assert.NoError(t, err)
+	_, err = env.Train(e, learner, 50, 100)
+	assert.NoError(t, err)
+	assert.InDelta(t, math.Pow(0.9, float64(length-2)), learner.V(states[0]), 1e-3)
+
+	// Truncated episodes bootstrap from the last state reached
+	e = newCorridorEnvironment(t, length, rand.New(rand.NewSource(0)))
+	learner, err = NewNStepTD(newConfig(t, 1, 0.9, 0, 0), 4, alwaysRight)
+	assert.NoError(t, err)
+	_, err = env.RunEpisode(e, learner, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, float32(0), learner.V(states[0]))
+
+	_, err = NewNStepTD(config, 0, alwaysRight)
+	assert.Error(t, err)
+	_, err = NewNStepTD(config, 1, nil)
+	assert.Error(t, err)
+}
+
+func TestTDLambdaTraces(t *testing.T) {
+	length := 10
+	states := corridorStates(length)
+
+	for _, trace := range []Trace{AccumulatingTrace, ReplacingTrace, DutchTrace} {
+		// With λ = 1, a single episode reaches the start of the corridor
+		e := newCorridorEnvironment(t, length, rand.New(rand.NewSource(0)))
+		learner, err := NewTDLambda(newConfig(t, 0.5, 0.9, 0, 0), 1, trace, alwaysRight)
+		assert.NoError(t, err)
+		_, err = env.RunEpisode(e, learner, 100)
+		assert.NoError(t, err)
+		assert.True(t, learner.V(states[0]) > 0, "trace %d", trace)
+
+		// With λ = 0, it's one-step TD
+		learner, err = NewTDLambda(newConfig(t, 0.5, 0.9, 0, 0), 0, trace, alwaysRight)
+		assert.NoError(t, err)
+		_, err = env.RunEpisode(e, learner, 100)
+		assert.NoError(t, err)
+		assert.Equal(t, float32(0), learner.V(states[length-3]), "trace %d", trace)
+		assert.Equal(t, float32(0.5), learner.V(states[length-2]), "trace %d", trace)
+
+		_, err = env.Train(e, learner, 100, 100)
+		assert.NoError(t, err)
+		assert.InDelta(t, math.Pow(0.9, float64(length-2)), learner.V(states[0]), 1e-2, "trace %d", trace)
+	}
+
+	_, err := NewTDLambda(newConfig(t, 0.5, 0.9, 0, 0), 1.5, AccumulatingTrace, alwaysRight)
+	assert.Error(t, err)
+}
+
+func TestEligibilityTraces(t *testing.T) {
+	state := mdp.NewState("S", 0, false)
+	for trace, expected := range map[Trace]float32{AccumulatingTrace: 2, ReplacingTrace: 1, DutchTrace: 1.375} {
+		e := newEligibility(trace)
+		e.visit(state, nil, 0.5)
+		e.decay(1)
+		e.visit(state, nil, 0.5)
+		e.decay(0.5)
+		e.visit(state, nil, 0.5)
+		assert.Len(t, e.entries, 1)
+		for _, entry := range e.entries {
+			assert.Equal(t, expected, entry.value, "trace %d", trace)
+		}
+		e.decay(0)
+		assert.Empty(t, e.entries)
+	}
+}
+
+func TestOnPolicyControlCorridor(t *testing.T) {
+	length := 15
+	path := make([]string, length-1)
+	for i := range path {
+		path[i] = "right"
+	}
+
+	for name, newLearner := range map[string]func(Config) (QLearner, error){
+		"n-step SARSA": func(config Config) (QLearner, error) {
+			return NewNStepSARSA(config, 4)
+		},
+		"SARSA(λ) accumulating": func(config Config) (QLearner, error) {
+			return NewSARSALambda(config, 0.8, AccumulatingTrace)
+		},
+		"SARSA(λ) replacing": func(config Config) (QLearner, error) {
+			return NewSARSALambda(config, 0.8, ReplacingTrace)
+		},
+		"SARSA(λ) dutch": func(config Config) (QLearner, error) {
+			return NewSARSALambda(config, 0.8, DutchTrace)
+		},
+	} {
+		e := newCorridorEnvironment(t, length, rand.New(rand.NewSource(2)))
+		learner, err := newLearner(newConfig(t, 0.3, 0.95, 0.1, 1))
+		assert.NoError(t, err)
+		_, err = env.Train(e, learner, 100, 500)
+		assert.NoError(t, err)
+		assert.Equal(t, path, greedyPath(t, e, learner, 2*length), name)
+	}
+
+	_, err := NewNStepSARSA(newConfig(t, 0.3, 0.95, 0.1, 1), 0)
+	assert.Error(t, err)
+	_, err = NewSARSALambda(newConfig(t, 0.3, 0.95, 0.1, 1), -1, DutchTrace)
+	assert.Error(t, err)
+}
+
+func TestDynaQ(t *testing.T) {
+	length := 12
+	path := make([]string, length-1)
+	for i := range path {
+		path[i] = "right"
+	}
+
+	for _, options := range []DynaOptions{
+		{PlanningSteps: 30},
+		{PlanningSteps: 30, PrioritizedSweeping: true, Threshold: 1e-4},
+	} {
+		// Planning learns the corridor in a handful of episodes
+		e := newCorridorEnvironment(t, length, rand.New(rand.NewSource(2)))
+		learner, err := NewDynaQ(newConfig(t, 0.5, 0.9, 0.1, 1), options)
+		assert.NoError(t, err)
+		_, err = env.Train(e, learner, 8, 1000)
+		assert.NoError(t, err)
+		assert.Equal(t, path, greedyPath(t, e, learner, 2*length), "%+v", options)
+
+		// The learned model can be inspected and solved directly
+		model, err := learner.Model()
+		assert.NoError(t, err)
+		assert.Equal(t, "C0", model.InitialState().Name())
+		assert.Len(t, model.States(), length)
+		assert.True(t, model.StateByName(fmt.Sprint("C", length-1)).Terminal())
+		assert.Equal(t, float32(1), model.R(fmt.Sprint("C", length-1)))
+		transition := model.T("C3", "right")
+		assert.Equal(t, float32(1), transition.Probability())
+		assert.Equal(t, "C4", transition.NextState().Name())
+		assert.Equal(t, "C0", model.T("C0", "left").NextState().Name())
+
+		_, policy, err := solver.ValueIteration(model, 1e-6, 1000)
+		assert.NoError(t, err)
+		for _, state := range model.States() {
+			if !state.Terminal() {
+				assert.Equal(t, "right", policy.Action(state).Name())
+			}
+		}
+	}
+
+	// After two episodes, planning has carried the goal's reward back to the start, which one-step updates alone can't
+	start := mdp.NewState("C0", 0, false)
+	right := mdp.NewAction("right")
+	for planningSteps, reached := range map[int]bool{0: false, 50: true} {
+		e := newCorridorEnvironment(t, length, rand.New(rand.NewSource(2)))
+		learner, err := NewDynaQ(newConfig(t, 0.5, 0.9, 0.1, 1), DynaOptions{PlanningSteps: planningSteps})
+		assert.NoError(t, err)
+		_, err = env.Train(e, learner, 2, 1000)
+		assert.NoError(t, err)
+		assert.Equal(t, reached, learner.Q(start, right) > 0, "%d planning steps", planningSteps)
+	}
+
+	_, err := NewDynaQ(newConfig(t, 0.5, 0.9, 0.1, 1), DynaOptions{PlanningSteps: -1})
+	assert.Error(t, err)
+}
+
+// countingAgent counts the next states a learner sees after each state-action pair while passing its steps on.
+type countingAgent struct {
+	QLearner
+	counts map[string]map[string]int
+}
+
+func (a *countingAgent) Learn(step env.Step, nextActions []mdp.Action) {
+	key := step.State.Name() + " " + step.Action.Name()
+	if a.counts[key] == nil {
+		a.counts[key] = make(map[string]int)
+	}
+	a.counts[key][step.NextState.Name()]++
+	a.QLearner.Learn(step, nextActions)
+}
+
+func TestDynaQModelKeepsEveryOutcome(t *testing.T) {
+	// From the start, "go" slips to one of two goals or stays put, and "wait" stays put
+	m, err := mdp.NewDefaultMDP()
+	assert.NoError(t, err)
+	move, wait := mdp.NewAction("go"), mdp.NewAction("wait")
+	start := mdp.NewState("start", 0, false)
+	near := mdp.NewState("near", 1, true)
+	far := mdp.NewState("far", 2, true)
+	assert.NoError(t, m.AddStateObject(start, 0, map[mdp.Action]mdp.Transition{
+		move: mdp.NewDistribution(mdp.NewTransition(0.5, near), mdp.NewTransition(0.3, far)),
+		wait: mdp.NewTransition(1, start),
+	}))
+	assert.NoError(t, m.AddStateObject(near, 1, nil))
+	assert.NoError(t, m.AddStateObject(far, 2, nil))
+	e, err := env.NewMDPEnvironment(m, rand.New(rand.NewSource(3)))
+	assert.NoError(t, err)
+
+	learner, err := NewDynaQ(newConfig(t, 0.5, 0.9, 0.5, 1), DynaOptions{PlanningSteps: 5})
+	assert.NoError(t, err)
+	agent := &countingAgent{learner, make(map[string]map[string]int)}
+	_, err = env.Train(e, agent, 500, 100)
+	assert.NoError(t, err)
+
+	// Every observed outcome appears in the model with its observed frequency
+	model, err := learner.Model()
+	assert.NoError(t, err)
+	modelStart := model.StateByName("start")
+	for _, action := range []string{"go", "wait"} {
+		counts := agent.counts["start "+action]
+		visits := 0
+		for _, count := range counts {
+			visits += count
+		}
+		outcomes := mdp.Outcomes(modelStart, model.T("start", action))
+		probabilities := make(map[string]float32)
+		for _, outcome := range outcomes {
+			probabilities[outcome.NextState().Name()] += outcome.Probability()
+		}
+		assert.Len(t, probabilities, len(counts), action)
+		for next, count := range counts {
+			assert.InDelta(t, float32(count)/float32(visits), probabilities[next], 1e-5, action+" to "+next)
+		}
+	}
+	assert.Len(t, agent.counts["start go"], 3)
+
+	// Solving the model values the start at about 0.9 (0.5 * 1 + 0.3 * 2 + 0.2 V(start)) = 1.21, where keeping only the
+	// most likely outcome would give 0.82
+	values, _, err := solver.ValueIteration(model, 1e-6, 1000)
+	assert.NoError(t, err)
+	assert.InDelta(t, 1.21, values[0], 0.05)
+}
+
+func TestDynaQModelInitialState(t *testing.T) {
+	// The corridor runs from the highest index down to the goal at index 0
+	m, err := mdp.NewDefaultMDP()
+	assert.NoError(t, err)
+	right := mdp.NewAction("right")
+	goal := mdp.NewState("goal", 0, true)
+	middle := mdp.NewState("middle", 1, false)
+	start := mdp.NewState("start", 2, false)
+	assert.NoError(t, m.AddStateObject(goal, 1, nil))
+	assert.NoError(t, m.AddStateObject(middle, 0, map[mdp.Action]mdp.Transition{right: mdp.NewTransition(1, goal)}))
+	assert.NoError(t, m.SetInitialStateObject(start, 0, map[mdp.Action]mdp.Transition{right: mdp.NewTransition(1, middle)}))
+	assert.Equal(t, start, m.InitialState())
+	e, err := env.NewMDPEnvironment(m, rand.New(rand.NewSource(1)))
+	assert.NoError(t, err)
+
+	learner, err := NewDynaQ(newConfig(t, 0.5, 0.9, 0.1, 1), DynaOptions{PlanningSteps: 5})
+	assert.NoError(t, err)
+	_, err = env.Train(e, learner, 3, 10)
+	assert.NoError(t, err)
+
+	// Episodes of the learned model start where the real ones did
+	model, err := learner.Model()
+	assert.NoError(t, err)
+	assert.Equal(t, "start", model.InitialState().Name())
+	simulated, err := env.NewMDPEnvironment(model, rand.New(rand.NewSource(1)))
+	assert.NoError(t, err)
+	state, err := simulated.Reset()
+	assert.NoError(t, err)
+	assert.Equal(t, "start", state.Name())
+}
@@ -0,0 +1,81 @@
+package tabular
+
+import "github.com/anthonykrivonos/go-rl/mdp"
+
+// Trace is the kind of eligibility trace used by TD(λ) learners, which determines how a trace changes when its state
+// or state-action pair is visited.
+type Trace int
+
+const (
+	// AccumulatingTrace adds 1 to the trace on every visit.
+	AccumulatingTrace Trace = iota
+	// ReplacingTrace resets the trace to 1 on every visit.
+	ReplacingTrace
+	// DutchTrace scales the trace by 1 - α and then adds 1 on every visit, which sits between the other two.
+	DutchTrace
+)
+
+// minTrace is the size below which a decayed trace is dropped.
+const minTrace = 1e-4
+
+// traceKey identifies an eligibility trace by state index and action name. Traces of states have an empty action name.
+type traceKey struct {
+	index  int
+	action string
+}
+
+// trace is the eligibility of a state, or of a state-action pair if its action is non-nil.
+type trace struct {
+	state  mdp.State
+	action mdp.Action
+	value  float32
+}
+
+// eligibility is a set of eligibility traces of one kind.
+type eligibility struct {
+	kind    Trace
+	entries map[traceKey]*trace
+}
+
+func newEligibility(kind Trace) *eligibility {
+	e := &eligibility{}
+	e.kind = kind
+	e.entries = make(map[traceKey]*trace)
+	return e
+}
+
+// visit updates the trace of `state`, or of `state` and `action` if `action` is non-nil, for a visit.
+func (e *eligibility) visit(state mdp.State, action mdp.Action, learningRate float32) {
+	key := traceKey{state.Index(), ""}
+	if action != nil {
+		key.action = action.Name()
+	}
+	t, ok := e.entries[key]
+	if !ok {
+		t = &trace{state: state, action: action}
+		e.entries[key] = t
+	}
+	switch e.kind {
+	case AccumulatingTrace:
+		t.value++
+	case ReplacingTrace:
+		t.value = 1
+	case DutchTrace:
+		t.value = (1-learningRate)*t.value + 1
+	}
+}
+
+// decay scales every trace by `factor`, dropping traces that become negligible.
+func (e *eligibility) decay(factor float32) {
+	for key, t := range e.entries {
+		t.value *= factor
+		if t.value < minTrace {
+			delete(e.entries, key)
+		}
+	}
+}
+
+// clear drops every trace.
+func (e *eligibility) clear() {
+	e.entries = make(map[traceKey]*trace)
+}
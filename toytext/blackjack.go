@@ -0,0 +1,190 @@
+package toytext
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/anthonykrivonos/go-rl/env"
+	"github.com/anthonykrivonos/go-rl/mdp"
+)
+
+const (
+	blackjackMinHard = 4
+	blackjackMinSoft = 12
+	blackjackMax     = 21
+	blackjackStand   = 17
+	blackjackCards   = 10
+	// The hard and soft player states for each dealer card, then win, lose and draw
+	blackjackHardStates = (blackjackMax - blackjackMinHard + 1) * blackjackCards
+	blackjackSoftStates = (blackjackMax - blackjackMinSoft + 1) * blackjackCards
+	blackjackWin        = blackjackHardStates + blackjackSoftStates
+	blackjackLose       = blackjackWin + 1
+	blackjackDraw       = blackjackWin + 2
+	// The dealer's bust, as a final sum
+	blackjackBust = blackjackMax + 1
+)
+
+// cardProbability returns the probability of drawing a card worth `card` from an infinite deck, where aces are worth 1
+// and face cards 10.
+func cardProbability(card int) float64 {
+	if card == blackjackCards {
+		return 4.0 / 13
+	}
+	return 1.0 / 13
+}
+
+// blackjackIndex returns the index of the state where the player's best sum is `sum`, the dealer shows `dealer`, and
+// `usable` says whether the player holds an ace counted as 11.
+func blackjackIndex(sum int, dealer int, usable bool) int {
+	if usable {
+		return blackjackHardStates + (sum-blackjackMinSoft)*blackjackCards + dealer - 1
+	}
+	return (sum-blackjackMinHard)*blackjackCards + dealer - 1
+}
+
+// addCard returns the best sum of a hand with best sum `sum` after drawing `card`, and whether it then holds an ace
+// counted as 11.
+func addCard(sum int, usable bool, card int) (int, bool) {
+	sum += card
+	if usable && sum > blackjackMax {
+		return sum - 10, false
+	} else if !usable && card == 1 && sum+10 <= blackjackMax {
+		return sum + 10, true
+	}
+	return sum, usable
+}
+
+// dealerFinals returns the probability of each final sum of a dealer hand with best sum `sum`, who draws until reaching
+// at least 17, indexed by the final sum. Bust hands end at blackjackBust.
+// The probabilities are summed in a fixed order, so they're identical from run to run.
+func dealerFinals(sum int, usable bool) []float64 {
+	finals := make([]float64, blackjackBust+1)
+	if sum > blackjackMax {
+		finals[blackjackBust] = 1
+		return finals
+	} else if sum >= blackjackStand {
+		finals[sum] = 1
+		return finals
+	}
+	for card := 1; card <= blackjackCards; card++ {
+		next, nextUsable := addCard(sum, usable, card)
+		for final, p := range dealerFinals(next, nextUsable) {
+			finals[final] += cardProbability(card) * p
+		}
+	}
+	return finals
+}
+
+// NewBlackjack creates the blackjack task against a dealer who draws until reaching 17 or more, dealt from an infinite
+// deck. The player's state is their best sum, whether they hold an ace counted as 11, and the dealer's face up card. Hard
+// sums 4 to 21 and soft sums 12 to 21 for each dealer card from ace (1) to 10 are indexed in that order, followed by the
+// terminal win, lose and draw states. Action "hit" draws a card, going bust above 21, and "stick" lets the dealer play
+// out their hand. Winning pays 1, losing pays -1, and the discount rate is 1.
+// Episodes start from a freshly dealt hand. `rng` is the source of randomness for the environment.
+// Returns the MDP, an environment that simulates it, and a nil error on success, or returns nils and a non-nil error on
+// failure.
+func NewBlackjack(rng *rand.Rand) (mdp.MDP, env.Environment, error) {
+	states := make([]mdp.State, blackjackDraw+1)
+	for dealer := 1; dealer <= blackjackCards; dealer++ {
+		shown := fmt.Sprint(dealer)
+		if dealer == 1 {
+			shown = "A"
+		}
+		for sum := blackjackMinHard; sum <= blackjackMax; sum++ {
+			i := blackjackIndex(sum, dealer, false)
+			states[i] = mdp.NewState(fmt.Sprintf("hard %d, dealer %s", sum, shown), i, false)
+		}
+		for sum := blackjackMinSoft; sum <= blackjackMax; sum++ {
+			i := blackjackIndex(sum, dealer, true)
+			states[i] = mdp.NewState(fmt.Sprintf("soft %d, dealer %s", sum, shown), i, false)
+		}
+	}
+	states[blackjackWin] = mdp.NewState("win", blackjackWin, true)
+	states[blackjackLose] = mdp.NewState("lose", blackjackLose, true)
+	states[blackjackDraw] = mdp.NewState("draw", blackjackDraw, true)
+	hit, stick := mdp.NewAction("hit"), mdp.NewAction("stick")
+
+	// The dealer's final sums for each face up card, after turning over the face down one
+	finals := make([][]float64, blackjackCards+1)
+	for dealer := 1; dealer <= blackjackCards; dealer++ {
+		sum, usable := addCard(0, false, dealer)
+		finals[dealer] = make([]float64, blackjackBust+1)
+		for card := 1; card <= blackjackCards; card++ {
+			next, nextUsable := addCard(sum, usable, card)
+			for final, p := range dealerFinals(next, nextUsable) {
+				finals[dealer][final] += cardProbability(card) * p
+			}
+		}
+	}
+
+	reward := func(state mdp.State) float32 {
+		switch state.Index() {
+		case blackjackWin:
+			return 1
+		case blackjackLose:
+			return -1
+		}
+		return 0
+	}
+	transitions := func(state mdp.State) map[mdp.Action]mdp.Transition {
+		i := state.Index()
+		usable := i >= blackjackHardStates
+		sum := blackjackMinHard + i/blackjackCards
+		if usable {
+			sum = blackjackMinSoft + (i-blackjackHardStates)/blackjackCards
+		}
+		dealer := i%blackjackCards + 1
+
+		var hits []mdp.Transition
+		for card := 1; card <= blackjackCards; card++ {
+			next, nextUsable := addCard(sum, usable, card)
+			target := states[blackjackLose]
+			if next <= blackjackMax {
+				target = states[blackjackIndex(next, dealer, nextUsable)]
+			}
+			hits = append(hits, mdp.NewTransition(float32(cardProbability(card)), target))
+		}
+
+		var win, lose, draw float64
+		for final, p := range finals[dealer] {
+			if final == blackjackBust || final < sum {
+				win += p
+			} else if final > sum {
+				lose += p
+			} else {
+				draw += p
+			}
+		}
+		return map[mdp.Action]mdp.Transition{
+			hit: mdp.NewDistribution(hits...),
+			stick: mdp.NewDistribution(
+				mdp.NewTransition(float32(win), states[blackjackWin]),
+				mdp.NewTransition(float32(lose), states[blackjackLose]),
+				mdp.NewTransition(float32(draw), states[blackjackDraw]),
+			),
+		}
+	}
+
+	m, err := newMDP(states, reward, transitions, 1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Deal two cards to the player and one face up to the dealer
+	var deals []mdp.Transition
+	for first := 1; first <= blackjackCards; first++ {
+		for second := 1; second <= blackjackCards; second++ {
+			sum, usable := addCard(0, false, first)
+			sum, usable = addCard(sum, usable, second)
+			for dealer := 1; dealer <= blackjackCards; dealer++ {
+				p := cardProbability(first) * cardProbability(second) * cardProbability(dealer)
+				deals = append(deals, mdp.NewTransition(float32(p), states[blackjackIndex(sum, dealer, usable)]))
+			}
+		}
+	}
+	e, err := env.NewMDPEnvironmentWithStart(m, mdp.NewDistribution(deals...), rng)
+	if err != nil {
+		return nil, nil, err
+	}
+	return m, e, nil
+}
@@ -0,0 +1,70 @@
+package toytext
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/anthonykrivonos/go-rl/env"
+	"github.com/anthonykrivonos/go-rl/mdp"
+)
+
+const (
+	cliffWalkingRows = 4
+	cliffWalkingCols = 12
+)
+
+// NewCliffWalking creates the cliff walking task on a 4 by 12 grid. The agent starts in the bottom left corner and must
+// reach the bottom right corner, and the cells between them along the bottom edge are a cliff. Cells are indexed from
+// the bottom, so that cell (row, col) counted from the bottom left has index row * 12 + col and the start has index 0.
+// Actions "up", "right", "down" and "left" move one cell, staying put at the grid's edge. Every step pays -1, reaching
+// the goal ends the episode, and stepping off the cliff pays -100; since rewards here belong to the state arrived in,
+// the cliff cells are states of their own from which any action returns to the start. The discount rate is 1.
+// `rng` is the source of randomness for the environment.
+// Returns the MDP, an environment that simulates it, and a nil error on success, or returns nils and a non-nil error on
+// failure.
+func NewCliffWalking(rng *rand.Rand) (mdp.MDP, env.Environment, error) {
+	rows, cols := cliffWalkingRows, cliffWalkingCols
+	cliff := func(index int) bool {
+		return index > 0 && index < cols-1
+	}
+	states := make([]mdp.State, rows*cols)
+	for i := range states {
+		name := cellName(i/cols, i%cols)
+		if cliff(i) {
+			name = fmt.Sprint("cliff ", name)
+		}
+		states[i] = mdp.NewState(name, i, i == cols-1)
+	}
+	directions := [][2]int{{1, 0}, {0, 1}, {-1, 0}, {0, -1}}
+	actions := []mdp.Action{mdp.NewAction("up"), mdp.NewAction("right"), mdp.NewAction("down"), mdp.NewAction("left")}
+
+	reward := func(state mdp.State) float32 {
+		if cliff(state.Index()) {
+			return -100
+		}
+		return -1
+	}
+	transitions := func(state mdp.State) map[mdp.Action]mdp.Transition {
+		t := make(map[mdp.Action]mdp.Transition)
+		row, col := state.Index()/cols, state.Index()%cols
+		for i, action := range actions {
+			if cliff(state.Index()) {
+				t[action] = mdp.NewTransition(1, states[0])
+				continue
+			}
+			r, c := move(row, col, directions[i][0], directions[i][1], rows, cols)
+			t[action] = mdp.NewTransition(1, states[r*cols+c])
+		}
+		return t
+	}
+
+	m, err := newMDP(states, reward, transitions, 1)
+	if err != nil {
+		return nil, nil, err
+	}
+	e, err := env.NewMDPEnvironment(m, rng)
+	if err != nil {
+		return nil, nil, err
+	}
+	return m, e, nil
+}
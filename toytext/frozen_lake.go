@@ -0,0 +1,97 @@
+package toytext
+
+import (
+	"errors"
+	"math/rand"
+
+	"github.com/anthonykrivonos/go-rl/env"
+	"github.com/anthonykrivonos/go-rl/mdp"
+)
+
+var (
+	// FrozenLake4x4 is the standard 4 by 4 frozen lake.
+	FrozenLake4x4 = []string{
+		"SFFF",
+		"FHFH",
+		"FFFH",
+		"HFFG",
+	}
+	// FrozenLake8x8 is the standard 8 by 8 frozen lake.
+	FrozenLake8x8 = []string{
+		"SFFFFFFF",
+		"FFFFFFFF",
+		"FFFHFFFF",
+		"FFFFFHFF",
+		"FFFHFFFF",
+		"FHHFFFHF",
+		"FHFFHFHF",
+		"FFFHFFFG",
+	}
+)
+
+// NewFrozenLake creates the frozen lake task, where an agent must cross a lake from the start to the goal without
+// falling through a hole. `lake` holds one string per row, with "S" for the start, which must be in the top left corner,
+// "F" for frozen ice, "H" for a hole and "G" for the goal. Cell (row, col) has index row * width + col. Actions "left",
+// "down", "right" and "up" move one cell, staying put at the lake's edge. On slippery ice, the agent moves in the
+// intended direction or either perpendicular one, each with probability 1/3. Reaching the goal pays 1 and ends the
+// episode, falling into a hole pays 0 and ends it, and the discount rate is 0.99.
+// `rng` is the source of randomness for the environment.
+// Returns the MDP, an environment that simulates it, and a nil error on success, or returns nils and a non-nil error on
+// failure.
+func NewFrozenLake(lake []string, slippery bool, rng *rand.Rand) (mdp.MDP, env.Environment, error) {
+	g := grid(lake)
+	if err := g.validate("SFHG"); err != nil {
+		return nil, nil, err
+	} else if g[0][0] != 'S' {
+		return nil, nil, errors.New("start must be in the top left corner")
+	}
+	rows, cols := len(g), len(g[0])
+
+	states := make([]mdp.State, rows*cols)
+	for r := range g {
+		for c := range g[r] {
+			cell := g[r][c]
+			states[r*cols+c] = mdp.NewState(cellName(r, c), r*cols+c, cell == 'H' || cell == 'G')
+		}
+	}
+	// Directions in the order of the actions, so that i ± 1 are perpendicular to i
+	directions := [][2]int{{0, -1}, {1, 0}, {0, 1}, {-1, 0}}
+	actions := []mdp.Action{mdp.NewAction("left"), mdp.NewAction("down"), mdp.NewAction("right"), mdp.NewAction("up")}
+
+	reward := func(state mdp.State) float32 {
+		if g[state.Index()/cols][state.Index()%cols] == 'G' {
+			return 1
+		}
+		return 0
+	}
+	transitions := func(state mdp.State) map[mdp.Action]mdp.Transition {
+		row, col := state.Index()/cols, state.Index()%cols
+		next := func(d int) mdp.State {
+			r, c := move(row, col, directions[d][0], directions[d][1], rows, cols)
+			return states[r*cols+c]
+		}
+		t := make(map[mdp.Action]mdp.Transition)
+		for i, action := range actions {
+			if !slippery {
+				t[action] = mdp.NewTransition(1, next(i))
+				continue
+			}
+			t[action] = mdp.NewDistribution(
+				mdp.NewTransition(1.0/3, next((i+3)%4)),
+				mdp.NewTransition(1.0/3, next(i)),
+				mdp.NewTransition(1.0/3, next((i+1)%4)),
+			)
+		}
+		return t
+	}
+
+	m, err := newMDP(states, reward, transitions, 0.99)
+	if err != nil {
+		return nil, nil, err
+	}
+	e, err := env.NewMDPEnvironment(m, rng)
+	if err != nil {
+		return nil, nil, err
+	}
+	return m, e, nil
+}
@@ -0,0 +1,137 @@
+package toytext
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/anthonykrivonos/go-rl/env"
+	"github.com/anthonykrivonos/go-rl/mdp"
+)
+
+// taxiMap is the taxi grid. Cells are the letters and spaces between "|" and ":", and "|" is a wall.
+var taxiMap = []string{
+	"+---------+",
+	"|R: | : :G|",
+	"| : | : : |",
+	"| : : : : |",
+	"| | : | : |",
+	"|Y| : |B: |",
+	"+---------+",
+}
+
+const (
+	taxiSize = 5
+	// Passenger position 0 is in the taxi and 1 to 4 are the pickup locations
+	taxiPassengerPositions = 5
+	taxiLocationCount      = 4
+)
+
+// taxiLocations are the rows and columns of the pickup locations R, G, Y and B.
+var taxiLocations = [taxiLocationCount][2]int{{0, 0}, {0, 4}, {4, 0}, {4, 3}}
+
+const taxiLocationNames = "RGYB"
+
+// taxiIndex returns the index of the state with the taxi at (row, col), the passenger at `passenger` and the destination
+// `destination`.
+func taxiIndex(row, col, passenger, destination int) int {
+	return ((row*taxiSize+col)*taxiPassengerPositions+passenger)*taxiLocationCount + destination
+}
+
+// taxiLocation returns the location at (row, col), or -1 if there isn't one.
+func taxiLocation(row, col int) int {
+	for i, location := range taxiLocations {
+		if location[0] == row && location[1] == col {
+			return i
+		}
+	}
+	return -1
+}
+
+// NewTaxi creates the taxi task on a 5 by 5 grid with walls and four pickup locations, R, G, Y and B. The taxi must
+// drive to the passenger, pick them up, drive to their destination and drop them off. The state with the taxi at
+// (row, col), the passenger at position p and destination d has index ((row * 5 + col) * 5 + p) * 4 + d, where p is 0
+// for in the taxi and 1 to 4 for the locations, and d is 0 to 3 for the locations. Actions "south", "north", "east" and
+// "west" move the taxi, which stays put against walls and the grid's edge, and "pickup" and "dropoff" are only offered
+// where they're legal, since rewards here belong to the state arrived in and can't penalize illegal ones. Dropping the
+// passenger off at another location leaves them there. Every step pays -1, and delivering the passenger pays 20 and
+// ends the episode. The discount rate is 0.99.
+// Episodes start with the taxi anywhere and the passenger at one location waiting to go to another, all uniformly at
+// random. `rng` is the source of randomness for the environment.
+// Returns the MDP, an environment that simulates it, and a nil error on success, or returns nils and a non-nil error on
+// failure.
+func NewTaxi(rng *rand.Rand) (mdp.MDP, env.Environment, error) {
+	states := make([]mdp.State, taxiSize*taxiSize*taxiPassengerPositions*taxiLocationCount)
+	for row := 0; row < taxiSize; row++ {
+		for col := 0; col < taxiSize; col++ {
+			for p := 0; p < taxiPassengerPositions; p++ {
+				for d := 0; d < taxiLocationCount; d++ {
+					passenger := "in taxi"
+					if p > 0 {
+						passenger = "at " + string(taxiLocationNames[p-1])
+					}
+					name := fmt.Sprintf("taxi %s, passenger %s, destination %c", cellName(row, col), passenger, taxiLocationNames[d])
+					i := taxiIndex(row, col, p, d)
+					states[i] = mdp.NewState(name, i, p == d+1)
+				}
+			}
+		}
+	}
+	moves := []mdp.Action{mdp.NewAction("south"), mdp.NewAction("north"), mdp.NewAction("east"), mdp.NewAction("west")}
+	directions := [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+	pickup, dropoff := mdp.NewAction("pickup"), mdp.NewAction("dropoff")
+
+	reward := func(state mdp.State) float32 {
+		if state.Terminal() {
+			return 20
+		}
+		return -1
+	}
+	transitions := func(state mdp.State) map[mdp.Action]mdp.Transition {
+		i := state.Index()
+		d := i % taxiLocationCount
+		p := i / taxiLocationCount % taxiPassengerPositions
+		col := i / taxiLocationCount / taxiPassengerPositions % taxiSize
+		row := i / taxiLocationCount / taxiPassengerPositions / taxiSize
+
+		t := make(map[mdp.Action]mdp.Transition)
+		for m, action := range moves {
+			r, c := move(row, col, directions[m][0], directions[m][1], taxiSize, taxiSize)
+			// Walls sit between cells in the map's columns 2 * col + 1 and 2 * c + 1
+			if c != col && taxiMap[row+1][col+c+1] == '|' {
+				r, c = row, col
+			}
+			t[action] = mdp.NewTransition(1, states[taxiIndex(r, c, p, d)])
+		}
+		location := taxiLocation(row, col)
+		if location >= 0 && p == location+1 {
+			t[pickup] = mdp.NewTransition(1, states[taxiIndex(row, col, 0, d)])
+		} else if location >= 0 && p == 0 {
+			t[dropoff] = mdp.NewTransition(1, states[taxiIndex(row, col, location+1, d)])
+		}
+		return t
+	}
+
+	m, err := newMDP(states, reward, transitions, 0.99)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The passenger waits at one of the locations to go to one of the other three
+	probability := float32(1) / (taxiSize * taxiSize * taxiLocationCount * (taxiLocationCount - 1))
+	var starts []mdp.Transition
+	for row := 0; row < taxiSize; row++ {
+		for col := 0; col < taxiSize; col++ {
+			for p := 1; p < taxiPassengerPositions; p++ {
+				for d := 0; d < taxiLocationCount; d++ {
+					if p != d+1 {
+						starts = append(starts, mdp.NewTransition(probability, states[taxiIndex(row, col, p, d)]))
+					}
+				}
+			}
+		}
+	}
+	e, err := env.NewMDPEnvironmentWithStart(m, mdp.NewDistribution(starts...), rng)
+	if err != nil {
+		return nil, nil, err
+	}
+	return m, e, nil
+}
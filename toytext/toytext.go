@@ -0,0 +1,72 @@
+package toytext
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/anthonykrivonos/go-rl/mdp"
+)
+
+// newMDP creates an MDP with discount rate `discountRate` from `states`, which must be indexed from 0 without gaps. Each
+// state's reward and transitions are given by `reward` and `transitions`; terminal states have no transitions.
+func newMDP(states []mdp.State, reward func(mdp.State) float32, transitions func(mdp.State) map[mdp.Action]mdp.Transition, discountRate float32) (mdp.MDP, error) {
+	m, err := mdp.NewDefaultMDP()
+	if err != nil {
+		return nil, err
+	} else if err := m.SetDiscountRate(discountRate); err != nil {
+		return nil, err
+	}
+	for i, state := range states {
+		if state.Index() != i {
+			return nil, errors.New("state " + state.String() + " should have index " + fmt.Sprint(i))
+		}
+		var t map[mdp.Action]mdp.Transition
+		if !state.Terminal() {
+			t = transitions(state)
+		}
+		if err := m.SetStateObject(state, reward(state), t); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// grid is a rectangular map of cells, one character to a cell.
+type grid []string
+
+// validate returns an error if the grid is empty, ragged, or has a cell that isn't one of `cells`.
+func (g grid) validate(cells string) error {
+	if len(g) == 0 || len(g[0]) == 0 {
+		return errors.New("grid must be non-empty")
+	}
+	for _, row := range g {
+		if len(row) != len(g[0]) {
+			return errors.New("grid rows must be of equal length")
+		}
+		for _, cell := range row {
+			found := false
+			for _, c := range cells {
+				found = found || c == cell
+			}
+			if !found {
+				return errors.New("grid cell " + string(cell) + " must be one of " + cells)
+			}
+		}
+	}
+	return nil
+}
+
+// move returns the cell one step from (row, col) in direction (dRow, dCol), or (row, col) itself if the step would leave
+// a grid of `rows` by `cols` cells.
+func move(row, col, dRow, dCol, rows, cols int) (int, int) {
+	r, c := row+dRow, col+dCol
+	if r < 0 || r >= rows || c < 0 || c >= cols {
+		return row, col
+	}
+	return r, c
+}
+
+// cellName names the cell at (row, col).
+func cellName(row, col int) string {
+	return fmt.Sprintf("(%d, %d)", row, col)
+}
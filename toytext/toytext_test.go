@@ -0,0 +1,248 @@
+package toytext
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/anthonykrivonos/go-rl/env"
+	"github.com/anthonykrivonos/go-rl/mdp"
+	"github.com/anthonykrivonos/go-rl/solver"
+	"github.com/anthonykrivonos/go-rl/tabular"
+	"github.com/anthonykrivonos/go-rl/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// policyAgent follows a solver's policy and learns nothing.
+type policyAgent struct {
+	policy solver.Policy
+}
+
+func (a *policyAgent) Act(state mdp.State, _ []mdp.Action) mdp.Action {
+	return a.policy.Action(state)
+}
+
+func (a *policyAgent) Learn(env.Step, []mdp.Action) {}
+
+func (a *policyAgent) EndEpisode() {}
+
+// solve returns the optimal policy of `m` by value iteration.
+func solve(t *testing.T, m mdp.MDP) solver.Policy {
+	_, policy, err := solver.ValueIteration(m, 1e-6, 10000)
+	assert.NoError(t, err)
+	return policy
+}
+
+func TestFrozenLake(t *testing.T) {
+	m, e, err := NewFrozenLake(FrozenLake4x4, false, rand.New(rand.NewSource(1)))
+	assert.NoError(t, err)
+	assert.Len(t, m.States(), 16)
+	assert.Equal(t, 0, m.InitialState().Index())
+	assert.True(t, m.StateByIndex(5).Terminal())
+	assert.Empty(t, mdp.Analyze(m).DeadEnds)
+
+	// Without slipping, the shortest safe path takes 6 steps
+	trajectory, err := env.RunEpisode(e, &policyAgent{solve(t, m)}, 100)
+	assert.NoError(t, err)
+	assert.Len(t, trajectory, 6)
+	assert.Equal(t, float32(1), trajectory.Return(1))
+
+	// Slipping, the agent can only hope to reach the goal most of the time
+	m, e, err = NewFrozenLake(FrozenLake4x4, true, rand.New(rand.NewSource(1)))
+	assert.NoError(t, err)
+	outcomes := mdp.Outcomes(m.StateByIndex(0), m.TByIndex(0, "down"))
+	assert.Len(t, outcomes, 3)
+	agent := &policyAgent{solve(t, m)}
+	// Moving left at the start can only slip down or stay put, which keeps away from the holes
+	assert.Equal(t, "left", agent.policy.Action(m.StateByIndex(0)).Name())
+	returns, err := env.Train(e, agent, 1000, 500)
+	assert.NoError(t, err)
+	successes := float32(0)
+	for _, r := range returns {
+		successes += r
+	}
+	assert.InDelta(t, 0.82, successes/1000, 0.04)
+
+	_, _, err = NewFrozenLake(FrozenLake8x8, true, rand.New(rand.NewSource(1)))
+	assert.NoError(t, err)
+	_, _, err = NewFrozenLake([]string{"FS"}, true, rand.New(rand.NewSource(1)))
+	assert.Error(t, err)
+	_, _, err = NewFrozenLake([]string{"SX"}, true, rand.New(rand.NewSource(1)))
+	assert.Error(t, err)
+	_, _, err = NewFrozenLake([]string{"SF", "F"}, true, rand.New(rand.NewSource(1)))
+	assert.Error(t, err)
+}
+
+func TestCliffWalking(t *testing.T) {
+	m, e, err := NewCliffWalking(rand.New(rand.NewSource(1)))
+	assert.NoError(t, err)
+	assert.Len(t, m.States(), 48)
+	assert.Equal(t, float32(-100), m.RByIndex(5))
+
+	// The optimal path runs along the cliff edge
+	trajectory, err := env.RunEpisode(e, &policyAgent{solve(t, m)}, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, float32(-13), trajectory.Return(1))
+
+	// Falling off returns to the start
+	_, err = e.Reset()
+	assert.NoError(t, err)
+	state, reward, done, err := e.Step(mdp.NewAction("right"))
+	assert.NoError(t, err)
+	assert.Equal(t, float32(-100), reward)
+	assert.False(t, done)
+	state, _, _, err = e.Step(mdp.NewAction("up"))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, state.Index())
+
+	// Q-learning learns the optimal path too
+	explorer, err := tabular.NewEpsilonGreedy(0.1)
+	assert.NoError(t, err)
+	learner, err := tabular.NewQLearning(tabular.Config{
+		LearningRate: 0.5,
+		DiscountRate: 1,
+		Explorer:     explorer,
+		Random:       rand.New(rand.NewSource(2)),
+	})
+	assert.NoError(t, err)
+	_, err = env.Train(e, learner, 500, 1000)
+	assert.NoError(t, err)
+	greedy := solver.Policy{}
+	for _, s := range m.States() {
+		actions := m.AvailableActions(s)
+		if len(actions) == 0 {
+			continue
+		}
+		best := actions[0]
+		for _, action := range actions {
+			if learner.Q(s, action) > learner.Q(s, best) {
+				best = action
+			}
+		}
+		greedy[s.Index()] = best
+	}
+	trajectory, err = env.RunEpisode(e, &policyAgent{greedy}, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, float32(-13), trajectory.Return(1))
+}
+
+func TestTaxi(t *testing.T) {
+	m, e, err := NewTaxi(rand.New(rand.NewSource(1)))
+	assert.NoError(t, err)
+	assert.Len(t, m.States(), 500)
+	assert.False(t, m.InitialState().Terminal())
+	assert.Empty(t, mdp.Analyze(m).DeadEnds)
+
+	// Walls block the way east from R
+	assert.Equal(t, taxiIndex(0, 1, 1, 1), m.TByIndex(taxiIndex(0, 1, 1, 1), "east").NextState().Index())
+	assert.Equal(t, taxiIndex(0, 1, 1, 1), m.TByIndex(taxiIndex(0, 0, 1, 1), "east").NextState().Index())
+	// Pickup is only offered where the passenger waits
+	names := func(actions []mdp.Action) []string {
+		res := []string{}
+		for _, action := range actions {
+			res = append(res, action.Name())
+		}
+		return res
+	}
+	assert.Contains(t, names(m.AvailableActions(m.StateByIndex(taxiIndex(0, 0, 1, 1)))), "pickup")
+	assert.NotContains(t, names(m.AvailableActions(m.StateByIndex(taxiIndex(0, 1, 1, 1)))), "pickup")
+
+	agent := &policyAgent{solve(t, m)}
+	starts := make(map[int]bool)
+	for episode := 0; episode < 100; episode++ {
+		trajectory, err := env.RunEpisode(e, agent, 100)
+		assert.NoError(t, err)
+		starts[trajectory[0].State.Index()] = true
+		assert.True(t, trajectory[len(trajectory)-1].Done)
+		assert.LessOrEqual(t, len(trajectory), 20)
+		assert.Equal(t, float32(21-len(trajectory)), trajectory.Return(1))
+	}
+	assert.Greater(t, len(starts), 50)
+}
+
+func TestBlackjack(t *testing.T) {
+	m, e, err := NewBlackjack(rand.New(rand.NewSource(1)))
+	assert.NoError(t, err)
+	assert.Len(t, m.States(), 283)
+	for _, s := range m.States() {
+		if s.Terminal() {
+			continue
+		}
+		for _, action := range m.AvailableActions(s) {
+			total := float32(0)
+			for _, outcome := range mdp.Outcomes(s, m.TByIndex(s.Index(), action.Name())) {
+				total += outcome.Probability()
+			}
+			assert.InDelta(t, 1, total, 1e-5)
+		}
+	}
+
+	// The optimal strategy from Sutton and Barto's figure 5.2
+	policy := solve(t, m)
+	expect := func(action string, sum int, dealer int, usable bool) {
+		state := m.StateByIndex(blackjackIndex(sum, dealer, usable))
+		assert.Equal(t, action, policy.Action(state).Name(), state.Name())
+	}
+	expect("hit", 12, 2, false)
+	expect("stick", 12, 4, false)
+	expect("hit", 12, 7, false)
+	expect("stick", 13, 2, false)
+	expect("stick", 16, 6, false)
+	expect("hit", 16, 7, false)
+	expect("hit", 16, 1, false)
+	expect("stick", 17, 10, false)
+	expect("hit", 11, 10, false)
+	expect("stick", 18, 8, true)
+	expect("hit", 18, 9, true)
+	expect("hit", 17, 5, true)
+	expect("stick", 19, 10, true)
+
+	// Dealt hands cover most states, and the optimal strategy loses a little on average
+	returns, err := env.Train(e, &policyAgent{policy}, 20000, 20)
+	assert.NoError(t, err)
+	total := float32(0)
+	for _, r := range returns {
+		total += r
+	}
+	assert.InDelta(t, -0.045, total/20000, 0.03)
+}
+
+func TestLearningIsReproducible(t *testing.T) {
+	constructors := map[string]func(rng *rand.Rand) (mdp.MDP, env.Environment, error){
+		"frozen lake": func(rng *rand.Rand) (mdp.MDP, env.Environment, error) {
+			return NewFrozenLake(FrozenLake4x4, true, rng)
+		},
+		"cliff walking": NewCliffWalking,
+		"taxi":          NewTaxi,
+		"blackjack":     NewBlackjack,
+	}
+	for name, newTask := range constructors {
+		// Runs the task's environment and a Q-learner, both seeded from a run-level seed, and records every step
+		run := func(seed int64) []string {
+			seeds := utils.NewSeeds(seed)
+			_, e, err := newTask(seeds.Random("env"))
+			assert.NoError(t, err)
+			explorer, err := tabular.NewEpsilonGreedy(0.2)
+			assert.NoError(t, err)
+			learner, err := tabular.NewQLearning(tabular.Config{
+				LearningRate: 0.5,
+				DiscountRate: 0.99,
+				Explorer:     explorer,
+				Random:       seeds.Random("explorer"),
+			})
+			assert.NoError(t, err)
+			var steps []string
+			for episode := 0; episode < 50; episode++ {
+				trajectory, err := env.RunEpisode(e, learner, 100)
+				assert.NoError(t, err)
+				for _, step := range trajectory {
+					steps = append(steps, fmt.Sprint(step.State.Index(), step.Action.Name(), step.Reward, step.NextState.Index()))
+				}
+			}
+			return steps
+		}
+		a := run(1)
+		assert.Equal(t, a, run(1), name)
+		assert.NotEqual(t, a, run(2), name)
+	}
+}
@@ -0,0 +1,79 @@
+package utils
+
+// StronglyConnectedComponents returns the strongly connected components of a directed graph on vertices 0 to n - 1,
+// where `successors` returns the vertices each vertex has an edge to. Uses Tarjan's algorithm, which returns the
+// components in reverse topological order.
+func StronglyConnectedComponents(n int, successors func(int) []int) [][]int {
+	index := make([]int, n)
+	low := make([]int, n)
+	onStack := make([]bool, n)
+	for i := range index {
+		index[i] = -1
+	}
+	var stack []int
+	var components [][]int
+	counter := 0
+
+	var visit func(v int)
+	visit = func(v int) {
+		index[v] = counter
+		low[v] = counter
+		counter++
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, w := range successors(v) {
+			if index[w] < 0 {
+				visit(w)
+				if low[w] < low[v] {
+					low[v] = low[w]
+				}
+			} else if onStack[w] && index[w] < low[v] {
+				low[v] = index[w]
+			}
+		}
+		if low[v] == index[v] {
+			var component []int
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				component = append(component, w)
+				if w == v {
+					break
+				}
+			}
+			components = append(components, component)
+		}
+	}
+
+	for v := 0; v < n; v++ {
+		if index[v] < 0 {
+			visit(v)
+		}
+	}
+	return components
+}
+
+// Reachable returns which vertices of a directed graph on vertices 0 to n - 1 can be reached from any of the `sources`,
+// where `successors` returns the vertices each vertex has an edge to.
+func Reachable(n int, sources []int, successors func(int) []int) []bool {
+	reached := make([]bool, n)
+	stack := make([]int, 0, len(sources))
+	for _, s := range sources {
+		if !reached[s] {
+			reached[s] = true
+			stack = append(stack, s)
+		}
+	}
+	for len(stack) > 0 {
+		v := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, w := range successors(v) {
+			if !reached[w] {
+				reached[w] = true
+				stack = append(stack, w)
+			}
+		}
+	}
+	return reached
+}
@@ -1,6 +1,212 @@
 package utils
 
-// Uniform returns a uniform random probability given support size.
+import (
+	"errors"
+	"math"
+	"math/rand"
+)
+
+// DefaultTolerance is the tolerance on the sum of a probability distribution that allows for rounding errors.
+const DefaultTolerance = 1e-6
+
+// Uniform returns the probability of each outcome of a uniform distribution with `support` outcomes, or 0 if there are
+// none.
 func Uniform(support int) float32 {
-	return float32(1 / support)
+	if support <= 0 {
+		return 0
+	}
+	return 1 / float32(support)
+}
+
+// Normalize returns `weights` scaled to sum to 1.
+// Returns the probabilities and a nil error on success, or returns nil and a non-nil error if any weight is negative or
+// not finite, or if the weights sum to 0.
+func Normalize(weights []float64) ([]float64, error) {
+	total := 0.0
+	for _, w := range weights {
+		if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+			return nil, errors.New("weights must be non-negative and finite")
+		}
+		total += w
+	}
+	if total <= 0 {
+		return nil, errors.New("weights must have a positive sum")
+	}
+	probabilities := make([]float64, len(weights))
+	for i, w := range weights {
+		probabilities[i] = w / total
+	}
+	return probabilities, nil
+}
+
+// ValidateDistribution returns an error if `probabilities` isn't a probability distribution: if any probability is
+// negative or NaN, or if they don't sum to 1 within `tolerance`. Returns nil otherwise.
+func ValidateDistribution(probabilities []float64, tolerance float64) error {
+	total := 0.0
+	for _, p := range probabilities {
+		if p < 0 || math.IsNaN(p) {
+			return errors.New("probabilities must be non-negative")
+		}
+		total += p
+	}
+	if math.Abs(total-1) > tolerance {
+		return errors.New("probabilities must sum to 1")
+	}
+	return nil
+}
+
+// SampleCategorical returns the position of an outcome drawn with probability proportional to its weight in
+// `weights`, which must be non-negative. Outcomes with no weight are never drawn. Returns -1 if no weight is positive.
+// Takes time linear in the number of outcomes; use an AliasTable to draw many times from the same weights.
+func SampleCategorical(weights []float64, rng *rand.Rand) int {
+	total := 0.0
+	last := -1
+	for i, w := range weights {
+		if w > 0 {
+			total += w
+			last = i
+		}
+	}
+	if last < 0 {
+		return -1
+	}
+	u := rng.Float64() * total
+	for i, w := range weights {
+		if w <= 0 {
+			continue
+		}
+		if u < w {
+			return i
+		}
+		u -= w
+	}
+	// Rounding left a sliver of mass past the last outcome
+	return last
+}
+
+// Softmax returns the probabilities proportional to exp(value / `temperature`) of `values`. Lower temperatures favour
+// the largest values more, and as the temperature falls to 0 the probability is shared evenly among the largest
+// values, which is what a temperature of 0 or less gives.
+func Softmax(values []float64, temperature float64) []float64 {
+	probabilities := make([]float64, len(values))
+	if len(values) == 0 {
+		return probabilities
+	}
+	highest := math.Inf(-1)
+	for _, v := range values {
+		highest = math.Max(highest, v)
+	}
+	total := 0.0
+	for i, v := range values {
+		if temperature > 0 {
+			// Subtracting the largest value keeps the exponentials from overflowing
+			probabilities[i] = math.Exp((v - highest) / temperature)
+		} else if v == highest {
+			probabilities[i] = 1
+		}
+		total += probabilities[i]
+	}
+	for i := range probabilities {
+		probabilities[i] /= total
+	}
+	return probabilities
+}
+
+// Argmax returns the position of the largest of `values`, breaking ties uniformly at random with `rng`, or in favour
+// of the first if `rng` is nil. Returns -1 if there are no values.
+func Argmax(values []float64, rng *rand.Rand) int {
+	if len(values) == 0 {
+		return -1
+	}
+	best := 0
+	ties := 1
+	for i := 1; i < len(values); i++ {
+		if values[i] > values[best] {
+			best = i
+			ties = 1
+		} else if values[i] == values[best] && rng != nil {
+			ties++
+			if rng.Intn(ties) == 0 {
+				best = i
+			}
+		}
+	}
+	return best
+}
+
+// An AliasTable draws outcomes from a fixed categorical distribution in constant time.
+type AliasTable interface {
+	// Len returns the number of outcomes.
+	Len() int
+	// Probability returns the probability of drawing the outcome at position `i`.
+	Probability(i int) float64
+	// Sample returns the position of a drawn outcome.
+	Sample(rng *rand.Rand) int
+}
+
+type aliasTable struct {
+	probabilities []float64
+	// Each column holds an outcome's own share of its 1/n slot, in cutoff, and the outcome that fills the rest
+	cutoff []float64
+	alias  []int
+}
+
+// NewAliasTable creates an AliasTable that draws each outcome with probability proportional to its weight in
+// `weights`, using Vose's alias method. Building the table takes time linear in the number of outcomes.
+// Returns the AliasTable and a nil error on success, or returns nil and a non-nil error on failure.
+func NewAliasTable(weights []float64) (AliasTable, error) {
+	probabilities, err := Normalize(weights)
+	if err != nil {
+		return nil, err
+	}
+	n := len(probabilities)
+	a := &aliasTable{}
+	a.probabilities = probabilities
+	a.cutoff = make([]float64, n)
+	a.alias = make([]int, n)
+
+	scaled := make([]float64, n)
+	var small, large []int
+	for i, p := range probabilities {
+		scaled[i] = p * float64(n)
+		a.alias[i] = i
+		if scaled[i] < 1 {
+			small = append(small, i)
+		} else {
+			large = append(large, i)
+		}
+	}
+	for len(small) > 0 && len(large) > 0 {
+		s, l := small[len(small)-1], large[len(large)-1]
+		small = small[:len(small)-1]
+		a.cutoff[s] = scaled[s]
+		a.alias[s] = l
+		// The large outcome gives up the rest of the small one's slot
+		scaled[l] -= 1 - scaled[s]
+		if scaled[l] < 1 {
+			large = large[:len(large)-1]
+			small = append(small, l)
+		}
+	}
+	// Whatever remains fills its own slot, up to rounding errors
+	for _, i := range append(small, large...) {
+		a.cutoff[i] = 1
+	}
+	return a, nil
+}
+
+func (a *aliasTable) Len() int {
+	return len(a.probabilities)
+}
+
+func (a *aliasTable) Probability(i int) float64 {
+	return a.probabilities[i]
+}
+
+func (a *aliasTable) Sample(rng *rand.Rand) int {
+	i := rng.Intn(len(a.cutoff))
+	if rng.Float64() < a.cutoff[i] {
+		return i
+	}
+	return a.alias[i]
 }
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+)
+
+// Seeds derives the seeds of a run's stochastic components, such as simulators, exploration policies, replay buffers and
+// network initialization, from a single run-level seed. Each component is identified by name, so adding a component
+// doesn't change the seeds of the others, and two runs with the same seed give every component the same randomness.
+type Seeds struct {
+	seed int64
+}
+
+// NewSeeds creates the seeds of a run with the run-level seed `seed`.
+func NewSeeds(seed int64) *Seeds {
+	s := &Seeds{}
+	s.seed = seed
+	return s
+}
+
+// Seed returns the run-level seed.
+func (s *Seeds) Seed() int64 {
+	return s.seed
+}
+
+// Derive returns the seed of the component named `name`. The same run-level seed and name always give the same seed,
+// and different names give unrelated seeds.
+func (s *Seeds) Derive(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return int64(mix(uint64(s.seed) ^ mix(h.Sum64())))
+}
+
+// Random returns a new source of randomness for the component named `name`, seeded with Derive(name).
+func (s *Seeds) Random(name string) *rand.Rand {
+	return rand.New(rand.NewSource(s.Derive(name)))
+}
+
+// Randoms returns `n` sources of randomness for the components named `name` followed by "/0" to "/n-1", such as the
+// copies of an environment that run side by side.
+func (s *Seeds) Randoms(name string, n int) []*rand.Rand {
+	res := make([]*rand.Rand, n)
+	for i := range res {
+		res[i] = s.Random(fmt.Sprintf("%s/%d", name, i))
+	}
+	return res
+}
+
+// Sub returns the seeds of the part of the run named `name`, whose run-level seed is Derive(name).
+func (s *Seeds) Sub(name string) *Seeds {
+	return NewSeeds(s.Derive(name))
+}
+
+// mix scrambles the bits of `x` with the SplitMix64 finalizer, so that nearby inputs give unrelated outputs.
+func mix(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
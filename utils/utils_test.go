@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeeds(t *testing.T) {
+	s := NewSeeds(42)
+	assert.Equal(t, int64(42), s.Seed())
+
+	// Seeds depend only on the run-level seed and the name
+	assert.Equal(t, s.Derive("env"), NewSeeds(42).Derive("env"))
+	assert.NotEqual(t, s.Derive("env"), s.Derive("agent"))
+	assert.NotEqual(t, s.Derive("env"), NewSeeds(43).Derive("env"))
+	assert.Equal(t, s.Random("env").Int63(), NewSeeds(42).Random("env").Int63())
+
+	randoms := s.Randoms("env", 3)
+	assert.Len(t, randoms, 3)
+	assert.Equal(t, s.Random("env/1").Int63(), randoms[1].Int63())
+	assert.NotEqual(t, randoms[0].Int63(), randoms[2].Int63())
+
+	assert.Equal(t, s.Derive("agent"), s.Sub("agent").Seed())
+	assert.Equal(t, s.Sub("agent").Derive("replay"), NewSeeds(42).Sub("agent").Derive("replay"))
+	assert.NotEqual(t, s.Sub("agent").Derive("replay"), s.Derive("replay"))
+}
+
+func TestUniform(t *testing.T) {
+	assert.Equal(t, float32(1), Uniform(1))
+	assert.Equal(t, float32(0.25), Uniform(4))
+	assert.Equal(t, float32(0), Uniform(0))
+}
+
+func TestNormalize(t *testing.T) {
+	p, err := Normalize([]float64{1, 3, 0})
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{0.25, 0.75, 0}, p)
+	assert.NoError(t, ValidateDistribution(p, DefaultTolerance))
+
+	for _, weights := range [][]float64{nil, {0, 0}, {1, -1}, {math.NaN()}, {math.Inf(1)}} {
+		_, err = Normalize(weights)
+		assert.Error(t, err, "%v", weights)
+	}
+
+	assert.NoError(t, ValidateDistribution([]float64{0.5, 0.5 + 1e-9}, DefaultTolerance))
+	assert.Error(t, ValidateDistribution([]float64{0.5, 0.6}, DefaultTolerance))
+	assert.Error(t, ValidateDistribution([]float64{1.5, -0.5}, DefaultTolerance))
+	assert.Error(t, ValidateDistribution([]float64{math.NaN()}, DefaultTolerance))
+}
+
+// frequencies returns how often each of `n` outcomes is drawn in 100000 draws of `draw`.
+func frequencies(n int, draw func() int) []float64 {
+	counts := make([]float64, n)
+	for i := 0; i < 100000; i++ {
+		counts[draw()]++
+	}
+	for i := range counts {
+		counts[i] /= 100000
+	}
+	return counts
+}
+
+func TestSampleCategorical(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	assert.Equal(t, -1, SampleCategorical([]float64{0, 0}, rng))
+	assert.Equal(t, 1, SampleCategorical([]float64{0, 2, 0}, rng))
+
+	// Weights needn't be normalized, and outcomes without weight are never drawn
+	weights := []float64{2, 0, 6, 2}
+	assert.InDeltaSlice(t, []float64{0.2, 0, 0.6, 0.2}, frequencies(len(weights), func() int {
+		return SampleCategorical(weights, rng)
+	}), 0.01)
+}
+
+func TestAliasTable(t *testing.T) {
+	_, err := NewAliasTable(nil)
+	assert.Error(t, err)
+	_, err = NewAliasTable([]float64{1, -1})
+	assert.Error(t, err)
+
+	rng := rand.New(rand.NewSource(1))
+	for _, weights := range [][]float64{{1}, {1, 1, 1, 1}, {0.1, 0, 0.6, 0.3}, {5, 1, 1, 1, 1, 1}} {
+		a, err := NewAliasTable(weights)
+		assert.NoError(t, err)
+		assert.Equal(t, len(weights), a.Len())
+		expected, err := Normalize(weights)
+		assert.NoError(t, err)
+		for i := range weights {
+			assert.InDelta(t, expected[i], a.Probability(i), 1e-12)
+		}
+		assert.InDeltaSlice(t, expected, frequencies(len(weights), func() int {
+			return a.Sample(rng)
+		}), 0.01, "%v", weights)
+	}
+}
+
+func TestSoftmax(t *testing.T) {
+	p := Softmax([]float64{1, 2, 3}, 1)
+	assert.NoError(t, ValidateDistribution(p, DefaultTolerance))
+	assert.InDelta(t, math.E, p[2]/p[1], 1e-9)
+
+	// Higher temperatures flatten the distribution and lower ones sharpen it
+	assert.InDelta(t, math.Exp(0.1), Softmax([]float64{1, 2, 3}, 10)[2]/Softmax([]float64{1, 2, 3}, 10)[1], 1e-9)
+	assert.Greater(t, Softmax([]float64{1, 2, 3}, 0.1)[2], 0.99)
+	assert.Equal(t, []float64{0, 0.5, 0.5}, Softmax([]float64{1, 3, 3}, 0))
+
+	// Large values don't overflow
+	assert.InDeltaSlice(t, []float64{0.5, 0.5}, Softmax([]float64{1000, 1000}, 1), 1e-12)
+	assert.Empty(t, Softmax(nil, 1))
+}
+
+func TestArgmax(t *testing.T) {
+	assert.Equal(t, -1, Argmax(nil, nil))
+	assert.Equal(t, 2, Argmax([]float64{1, 3, 4, 2}, nil))
+	assert.Equal(t, 1, Argmax([]float64{1, 4, 4, 2}, nil))
+
+	// Ties are broken uniformly at random
+	rng := rand.New(rand.NewSource(1))
+	assert.InDeltaSlice(t, []float64{0, 0.5, 0, 0.5}, frequencies(4, func() int {
+		return Argmax([]float64{1, 4, 2, 4}, rng)
+	}), 0.01)
+}